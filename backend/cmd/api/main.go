@@ -30,6 +30,12 @@ func main() {
 		logrus.Fatalf("Config error: %v", err)
 	}
 
+	// 私鑰加密金鑰 (未設定時產生並保存在 encryption_key_file，不使用內建金鑰)
+	encryptionKey, err := service.LoadEncryptionKey(cfg.Security)
+	if err != nil {
+		logrus.Fatalf("Encryption key error: %v", err)
+	}
+
 	// 2. Database
 	mongoClient, err := database.Connect(cfg.MongoDB)
 	if err != nil {
@@ -42,22 +48,63 @@ func main() {
 	// 3. Dependency Injection (依賴注入)
 	// Repo -> Service -> Handler
 	domainRepo := repository.NewMongoDomainRepo(db)
+	certRepo := repository.NewMongoCertificateRepo(db)
+	acmeAccountRepo := repository.NewMongoAcmeAccountRepo(db)
+	acmeChallengeRepo := repository.NewMongoAcmeChallengeRepo(db)
+	deployTargetRepo := repository.NewMongoDeployTargetRepo(db)
+	internalCARepo := repository.NewMongoInternalCARepo(db)
+	cfAccountRepo := repository.NewMongoCloudflareAccountRepo(db)
+	fileCertRepo := repository.NewMongoFileCertificateRepo(db)
+	ctRepo := repository.NewMongoCTRepo(db)
+	discoveryRepo := repository.NewMongoDiscoveryRepo(db)
+	zoneSecurityRepo := repository.NewMongoZoneSecurityRepo(db)
+	zoneSyncRepo := repository.NewMongoZoneSyncRepo(db)
 
 	// 初始化基礎 Service (順序很重要)
 	notifierService := service.NewNotifierService(domainRepo)
-	cfService := service.NewCloudflareService(cfg.Cloudflare.APIToken, domainRepo) // Cloudflare 服務
+	certStoreService := service.NewCertStoreService(certRepo, domainRepo, encryptionKey)
+	cfService := service.NewCloudflareService(cfg.Cloudflare.APIToken, domainRepo, cfAccountRepo, certStoreService) // Cloudflare 服務 (多帳號)
+	route53Service := service.NewRoute53Service(cfg.Route53)                                                        // Route 53 服務 (設定 Access Key 才啟用)
+	powerDNSService := service.NewPowerDNSService(cfg.PowerDNS)                                                     // PowerDNS 服務 (設定 API URL 才啟用)
+	axfrService := service.NewAXFRService(cfg.AXFR)                                                                 // 自建 DNS (AXFR + TSIG)
+	kubernetesService := service.NewKubernetesService(cfg.Kubernetes, domainRepo)                                   // Kubernetes Ingress / HTTPRoute 探索
 	scannerService := service.NewScannerService(domainRepo, notifierService, cfService)
+	deployService := service.NewDeployService(deployTargetRepo, domainRepo, certStoreService, cfService, notifierService, cfg.Deploy)
+	internalCAService := service.NewInternalCAService(internalCARepo, domainRepo, certStoreService, deployService, notifierService)
+	originCAService := service.NewOriginCAService(cfService, domainRepo, certStoreService, notifierService)
+	zoneFileService := service.NewZoneFileService(domainRepo)
+	fileCertService := service.NewFileCertService(fileCertRepo, notifierService, cfg.FileWatch) // 磁碟憑證 (PEM / PKCS#12 / JKS)
+	ctMonitorService := service.NewCTMonitorService(cfg.CT, ctRepo, domainRepo, notifierService) // Certificate Transparency 監控
+	discoveryService := service.NewDiscoveryService(discoveryRepo, domainRepo, ctRepo)           // 從 SAN / CT 探索未監控的主機
+	zoneSecurityService := service.NewZoneSecurityService(zoneSecurityRepo, domainRepo, notifierService)
+	dnsActionService := service.NewDNSActionService(cfService, domainRepo, notifierService)
+	http01Provider := service.NewHTTP01Provider(acmeChallengeRepo)
+	acmeService := service.NewAcmeService(domainRepo, acmeAccountRepo, cfService, http01Provider, notifierService, certStoreService, deployService)
 
 	// [關鍵修正 1] 這裡必須傳入 cfService，不能傳 nil！
-	cronService := service.NewCronService(domainRepo, cfService, scannerService, notifierService)
+	dnsSyncService := service.NewDNSSyncService(domainRepo, zoneSyncRepo, zoneSecurityService, cfService, route53Service, powerDNSService, axfrService, kubernetesService) // 同步來源 (DNS Provider)
+	cronService := service.NewCronService(domainRepo, dnsSyncService, scannerService, notifierService, acmeService, internalCAService, fileCertService, ctMonitorService, discoveryService)
 
 	// [關鍵修正 2] 啟動 Cron 排程服務！
 	cronService.Start()
+	fileCertService.Start(context.Background()) // 監看憑證目錄
 
 
 	// 初始化 Handler
-	domainHandler := api.NewDomainHandler(domainRepo, cfService, scannerService, notifierService, cronService)
+	domainHandler := api.NewDomainHandler(domainRepo, cfService, scannerService, notifierService, cronService, certRepo)
 	toolHandler := api.NewToolHandler()
+	acmeHandler := api.NewAcmeHandler(domainRepo, acmeAccountRepo, acmeService, http01Provider)
+	certHandler := api.NewCertificateHandler(certRepo, domainRepo, certStoreService, acmeService, deployService, originCAService, notifierService)
+	deployHandler := api.NewDeployHandler(deployTargetRepo, certRepo, deployService)
+	caHandler := api.NewCAHandler(domainRepo, internalCAService)
+	cfAccountHandler := api.NewCloudflareAccountHandler(cfAccountRepo, cfService)
+	zoneFileHandler := api.NewZoneFileHandler(zoneFileService, scannerService, notifierService)
+	fileCertHandler := api.NewFileCertHandler(fileCertRepo, fileCertService)
+	ctHandler := api.NewCTHandler(ctRepo, ctMonitorService)
+	discoveryHandler := api.NewDiscoveryHandler(discoveryRepo, discoveryService, scannerService, notifierService)
+	zoneSecurityHandler := api.NewZoneSecurityHandler(zoneSecurityService)
+	zoneSyncHandler := api.NewZoneSyncHandler(dnsSyncService)
+	dnsActionHandler := api.NewDNSActionHandler(domainRepo, dnsActionService, scannerService)
 	// [建議] 舊的 Scheduler 應該可以移除了，因為現在由 CronService 接管
 	// scheduler := service.NewSchedulerService(scannerService, cfService)
 	// scheduler.Start()
@@ -100,6 +147,22 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"token": token})
 	})
 
+	// ACME HTTP-01 驗證 (CA 直接存取，不需登入)
+	r.GET("/.well-known/acme-challenge/:token", acmeHandler.ServeHTTPChallenge)
+
+	// 內建 CA 憑證鏈 (公開，讓用戶端下載加入信任)
+	r.GET("/pki/ca-chain.pem", caHandler.GetChain)
+
+	// 選用：獨立 Listener (例如 :80)，讓主服務不必對外開放也能完成 HTTP-01
+	if cfg.Acme.HTTP01Listen != "" {
+		go func() {
+			logrus.Infof("HTTP-01 challenge listener on %s", cfg.Acme.HTTP01Listen)
+			if err := http.ListenAndServe(cfg.Acme.HTTP01Listen, http01Provider); err != nil {
+				logrus.Errorf("HTTP-01 listener stopped: %v", err)
+			}
+		}()
+	}
+
 	// API V1 Group (受保護)
 	v1 := r.Group("/api/v1")
 	v1.Use(api.AuthMiddleware("my-secret-key"))
@@ -110,8 +173,11 @@ func main() {
 		v1.POST("/domains/:id/scan", domainHandler.ScanOneDomain)
 		v1.POST("/domains/batch-scan", domainHandler.BatchScanDomains)
 		v1.GET("/domains", domainHandler.GetDomains)                    // 列表查詢
+		v1.GET("/domains/:id", domainHandler.GetDomain)                 // 單一域名詳情 (含 ARI)
 		v1.PATCH("/domains/:id/settings", domainHandler.UpdateSettings) // 更新設定
 		v1.GET("/zones", domainHandler.GetZones)                        // 獲取下拉選單資料
+		v1.POST("/zones/import", zoneFileHandler.ImportZone)            // 匯入 BIND Zone File
+		v1.GET("/zones/:zone/export", zoneFileHandler.ExportZone)       // 匯出 BIND Zone File
 		v1.GET("/settings", domainHandler.GetSettings)                  // 獲取設定
 		v1.POST("/settings", domainHandler.SaveSettings)                // 儲存設定
 		v1.POST("/settings/test", domainHandler.TestNotification)       // 測試通知
@@ -120,6 +186,68 @@ func main() {
 		v1.GET("/domains/export", domainHandler.ExportDomains)
 		v1.POST("/domains", domainHandler.AddDomain)
 		v1.DELETE("/domains/:id", domainHandler.DeleteDomain)
+		v1.POST("/domains/:id/issue", acmeHandler.IssueCertificate) // ACME 簽發 (DNS-01)
+		v1.GET("/domains/:id/issue", acmeHandler.GetIssueStatus)    // 查詢簽發進度
+		v1.GET("/domains/:id/certificates", certHandler.ListDomainCertificates)
+		v1.POST("/domains/:id/certificates", certHandler.UploadCertificate)            // 上傳憑證 (PEM)
+		v1.POST("/domains/:id/origin-certificate", certHandler.IssueOriginCertificate) // Cloudflare Origin CA
+		v1.GET("/certificates/:id", certHandler.GetCertificate)
+		v1.POST("/certificates/:id/download", certHandler.DownloadCertificate) // PEM / PKCS#12 / JKS
+		v1.POST("/certificates/:id/revoke", certHandler.RevokeCertificate)     // 撤銷 (RFC 5280 reason)
+
+		// 部署目標 (簽發/續簽/上傳後自動執行)
+		v1.GET("/domains/:id/deploy-targets", deployHandler.ListTargets)
+		v1.POST("/domains/:id/deploy-targets", deployHandler.CreateTarget)
+		v1.PUT("/deploy-targets/:id", deployHandler.UpdateTarget)
+		v1.DELETE("/deploy-targets/:id", deployHandler.DeleteTarget)
+		v1.POST("/deploy-targets/:id/run", deployHandler.RunTarget) // 立即部署最新憑證
+
+		// 內建私有 CA (內部主機名稱)
+		v1.GET("/ca", caHandler.GetCA)
+		v1.POST("/ca/generate", caHandler.GenerateCA)
+		v1.POST("/ca/import", caHandler.ImportCA)
+		v1.POST("/domains/:id/issue-internal", caHandler.IssueInternal)
+
+		// ACME 帳號 (多 CA)
+		v1.GET("/acme/directories", acmeHandler.GetDirectories)
+		v1.GET("/acme/accounts", acmeHandler.ListAccounts)
+		v1.POST("/acme/accounts", acmeHandler.CreateAccount)
+		v1.PUT("/acme/accounts/:id", acmeHandler.UpdateAccount)
+		v1.POST("/acme/accounts/:id/default", acmeHandler.SetDefaultAccount)
+		v1.DELETE("/acme/accounts/:id", acmeHandler.DeleteAccount)
+
+		// Cloudflare 帳號 (多 Token)
+		v1.GET("/cloudflare/accounts", cfAccountHandler.ListAccounts)
+		v1.POST("/cloudflare/accounts", cfAccountHandler.CreateAccount)
+		v1.PUT("/cloudflare/accounts/:id", cfAccountHandler.UpdateAccount)
+		v1.DELETE("/cloudflare/accounts/:id", cfAccountHandler.DeleteAccount)
+
+		// 磁碟憑證 (檔案監看)
+		v1.GET("/file-certificates", fileCertHandler.ListFileCertificates)
+		v1.POST("/file-certificates/scan", fileCertHandler.ScanFileCertificates)
+		v1.PATCH("/file-certificates/:id/settings", fileCertHandler.UpdateFileCertSettings)
+
+		// Certificate Transparency 監控
+		v1.GET("/ct/logs", ctHandler.ListLogs)
+		v1.GET("/ct/issuances", ctHandler.ListIssuances)
+		v1.POST("/ct/poll", ctHandler.Poll)
+
+		// 主機探索建議 (SAN / CT)
+		v1.GET("/discovery", discoveryHandler.ListDiscovered)
+		v1.POST("/discovery/run", discoveryHandler.RunDiscovery)
+		v1.POST("/discovery/:id/accept", discoveryHandler.AcceptDiscovered)
+		v1.POST("/discovery/:id/ignore", discoveryHandler.IgnoreDiscovered)
+
+		// Zone SSL/TLS 模式與 Edge 憑證包 (Cloudflare)
+		v1.GET("/zones/security", zoneSecurityHandler.ListZoneSecurity)
+		v1.GET("/zones/sync", zoneSyncHandler.ListZoneSync) // 各 Zone 的增量同步進度
+
+		// 寫回 Cloudflare 紀錄 (Token 需具備 DNS:Edit)
+		v1.POST("/domains/:id/dns/proxy", dnsActionHandler.SetProxy)
+		v1.PATCH("/domains/:id/dns", dnsActionHandler.UpdateRecord)
+		v1.DELETE("/domains/:id/dns", dnsActionHandler.DeleteRecord)
+		v1.POST("/zones/:zone/records", dnsActionHandler.CreateRecord)
+
 		v1.POST("/tools/decode-cert", toolHandler.DecodeCertificate)
 	}
 
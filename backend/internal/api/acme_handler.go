@@ -0,0 +1,217 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AcmeHandler struct {
+	Repo     repository.DomainRepository
+	Accounts repository.AcmeAccountRepository
+	Acme     *service.AcmeService
+	HTTP01   *service.HTTP01Provider
+}
+
+func NewAcmeHandler(r repository.DomainRepository, accounts repository.AcmeAccountRepository, a *service.AcmeService, h *service.HTTP01Provider) *AcmeHandler {
+	return &AcmeHandler{Repo: r, Accounts: accounts, Acme: a, HTTP01: h}
+}
+
+// ServeHTTPChallenge 回應 CA 的 HTTP-01 驗證 (不需登入)
+func (h *AcmeHandler) ServeHTTPChallenge(c *gin.Context) {
+	keyAuth, ok := h.HTTP01.Lookup(c.Request.Context(), c.Request.Host, c.Param("token"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.String(http.StatusOK, keyAuth)
+}
+
+// IssueCertificate 觸發 ACME 簽發 (背景執行，進度用 GetIssueStatus 查詢)
+// Body 可選填 {"account_id": "..."}，未指定則使用預設帳號
+func (h *AcmeHandler) IssueCertificate(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req struct {
+		AccountID string `json:"account_id"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+			return
+		}
+	}
+	var accountID primitive.ObjectID
+	if req.AccountID != "" {
+		if accountID, err = primitive.ObjectIDFromHex(req.AccountID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的帳號 ID"})
+			return
+		}
+	}
+
+	d, err := h.Repo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return
+	}
+
+	account, err := h.Acme.ResolveAccount(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.Acme.StartIssue(*d, account)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "簽發任務已在背景啟動", "data": job})
+}
+
+// GetIssueStatus 查詢簽發進度
+func (h *AcmeHandler) GetIssueStatus(c *gin.Context) {
+	job := h.Acme.GetJob(c.Param("id"))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "沒有簽發紀錄"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// GetDirectories 常用 CA 的 Directory URL (給前端下拉選單)
+func (h *AcmeHandler) GetDirectories(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": []gin.H{
+		{"name": "Let's Encrypt", "directory_url": domain.AcmeDirectoryLEProduction, "eab_required": false},
+		{"name": "Let's Encrypt (Staging)", "directory_url": domain.AcmeDirectoryLEStaging, "eab_required": false},
+		{"name": "ZeroSSL", "directory_url": domain.AcmeDirectoryZeroSSL, "eab_required": true},
+		{"name": "Google Trust Services", "directory_url": domain.AcmeDirectoryGoogle, "eab_required": true},
+	}})
+}
+
+// acmeAccountRequest EAB HMAC 不會出現在回應中，因此新增/更新使用獨立的請求結構
+type acmeAccountRequest struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	DirectoryURL string `json:"directory_url"`
+	EABKeyID     string `json:"eab_key_id"`
+	EABHMACKey   string `json:"eab_hmac_key"` // 只在新增時接受
+	RootCAPEM    string `json:"root_ca_pem"`
+}
+
+func (r acmeAccountRequest) account() domain.AcmeAccount {
+	return domain.AcmeAccount{
+		Name:         r.Name,
+		Email:        r.Email,
+		DirectoryURL: r.DirectoryURL,
+		EABKeyID:     r.EABKeyID,
+		EABHMACKey:   r.EABHMACKey,
+		RootCAPEM:    r.RootCAPEM,
+	}
+}
+
+// ListAccounts 列出所有 ACME 帳號
+func (h *AcmeHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.Accounts.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// CreateAccount 新增 ACME 帳號 (實際註冊在第一次簽發時進行)
+func (h *AcmeHandler) CreateAccount(c *gin.Context) {
+	var req acmeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	account := req.account()
+
+	if err := h.Acme.CreateAccount(c.Request.Context(), &account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "新增成功", "data": account})
+}
+
+// UpdateAccount 更新 ACME 帳號
+func (h *AcmeHandler) UpdateAccount(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req acmeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	if req.EABHMACKey != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "EAB HMAC 只能在新增帳號時設定"})
+		return
+	}
+	account := req.account()
+	account.ID = oid
+
+	if err := h.Acme.UpdateAccount(c.Request.Context(), account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+}
+
+// SetDefaultAccount 設為預設帳號
+func (h *AcmeHandler) SetDefaultAccount(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	if _, err := h.Accounts.GetByID(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該帳號"})
+		return
+	}
+	if err := h.Accounts.SetDefault(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已設為預設帳號"})
+}
+
+// DeleteAccount 刪除 ACME 帳號 (預設帳號不可刪除)
+func (h *AcmeHandler) DeleteAccount(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	account, err := h.Accounts.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該帳號"})
+		return
+	}
+	if account.IsDefault {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "預設帳號不可刪除，請先指定其他預設帳號"})
+		return
+	}
+
+	if err := h.Accounts.Delete(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CAHandler struct {
+	Repo       repository.DomainRepository
+	InternalCA *service.InternalCAService
+}
+
+func NewCAHandler(r repository.DomainRepository, ca *service.InternalCAService) *CAHandler {
+	return &CAHandler{Repo: r, InternalCA: ca}
+}
+
+// GetCA 取得內建 CA 資訊 (不含私鑰)
+func (h *CAHandler) GetCA(c *gin.Context) {
+	ca, err := h.InternalCA.Get(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ca == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "尚未建立內建 CA"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": ca})
+}
+
+// GenerateCA 產生新的 Root + Intermediate (會取代現有 CA)
+func (h *CAHandler) GenerateCA(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+			return
+		}
+	}
+
+	ca, err := h.InternalCA.Generate(c.Request.Context(), req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "CA 已建立", "data": ca})
+}
+
+// ImportCA 匯入既有 CA (Root 憑證 + Root 私鑰，或 Root 憑證 + Intermediate 憑證與私鑰)
+func (h *CAHandler) ImportCA(c *gin.Context) {
+	var req struct {
+		Name                string `json:"name"`
+		RootCertPEM         string `json:"root_cert_pem"`
+		RootKeyPEM          string `json:"root_key_pem"`
+		IntermediateCertPEM string `json:"intermediate_cert_pem"`
+		IntermediateKeyPEM  string `json:"intermediate_key_pem"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+
+	ca, err := h.InternalCA.Import(c.Request.Context(), req.Name, req.RootCertPEM, req.RootKeyPEM, req.IntermediateCertPEM, req.IntermediateKeyPEM)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "CA 已匯入", "data": ca})
+}
+
+// GetChain 公開 CA 憑證鏈 (Intermediate + Root)，讓用戶端下載後加入信任
+func (h *CAHandler) GetChain(c *gin.Context) {
+	chain, err := h.InternalCA.Chain(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", "attachment;filename=ca-chain.pem")
+	c.Data(http.StatusOK, "application/x-pem-file", []byte(chain))
+}
+
+// IssueInternal 由內建 CA 為域名簽發憑證
+func (h *CAHandler) IssueInternal(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req struct {
+		ValidityDays int `json:"validity_days"` // 預設 90 天
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+			return
+		}
+	}
+
+	d, err := h.Repo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return
+	}
+
+	mc, err := h.InternalCA.Issue(c.Request.Context(), *d, req.ValidityDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "簽發成功", "data": mc})
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CertificateHandler struct {
+	Repo       repository.CertificateRepository
+	DomainRepo repository.DomainRepository
+	Store      *service.CertStoreService
+	Acme       *service.AcmeService
+	Deployer   *service.DeployService
+	OriginCA   *service.OriginCAService
+	Notifier   *service.NotifierService
+}
+
+func NewCertificateHandler(r repository.CertificateRepository, d repository.DomainRepository, s *service.CertStoreService, a *service.AcmeService, dp *service.DeployService, o *service.OriginCAService, n *service.NotifierService) *CertificateHandler {
+	return &CertificateHandler{Repo: r, DomainRepo: d, Store: s, Acme: a, Deployer: dp, OriginCA: o, Notifier: n}
+}
+
+// ListDomainCertificates 列出某個域名保管的所有憑證 (新到舊)
+func (h *CertificateHandler) ListDomainCertificates(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	certs, err := h.Repo.ListByDomainID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": certs})
+}
+
+// UploadCertificate 手動上傳憑證與私鑰 (PEM)
+func (h *CertificateHandler) UploadCertificate(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req struct {
+		CertPEM string `json:"cert_pem"` // Leaf (可附帶中繼憑證)
+		KeyPEM  string `json:"key_pem"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+
+	d, err := h.DomainRepo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return
+	}
+
+	mc, err := h.Store.Save(c.Request.Context(), *d, domain.CertSourceUpload, []byte(req.CertPEM), []byte(req.KeyPEM))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.Notifier.NotifyOperation(c.Request.Context(), service.EventUpdate, d.DomainName,
+		fmt.Sprintf("📤 手動上傳憑證 (到期: %s, IP: %s)", mc.NotAfter.Format("2006-01-02"), c.ClientIP()))
+
+	// 背景部署到設定的目標
+	go h.Deployer.DeployCertificate(context.Background(), mc)
+	c.JSON(http.StatusOK, gin.H{"message": "上傳成功", "data": mc})
+}
+
+// IssueOriginCertificate 為 Proxy 域名簽發 Cloudflare Origin CA 憑證 (源站使用)
+func (h *CertificateHandler) IssueOriginCertificate(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req struct {
+		Hostnames    []string `json:"hostnames"`     // 預設為域名本身，可加入萬用字元
+		ValidityDays int      `json:"validity_days"` // 7/30/90/365/730/1095/5475，預設 5475 (15 年)
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+			return
+		}
+	}
+
+	d, err := h.DomainRepo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return
+	}
+
+	mc, err := h.OriginCA.Issue(c.Request.Context(), *d, req.Hostnames, req.ValidityDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "簽發成功", "data": mc})
+}
+
+// GetCertificate 取得單一憑證 (不含私鑰)
+func (h *CertificateHandler) GetCertificate(c *gin.Context) {
+	mc, ok := h.findCertificate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mc})
+}
+
+// DownloadCertificate 下載憑證
+// format: leaf / chain / fullchain / key / p12 / jks (p12 與 jks 需帶 password)
+func (h *CertificateHandler) DownloadCertificate(c *gin.Context) {
+	mc, ok := h.findCertificate(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Format   string `json:"format"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	if req.Format == "" {
+		req.Format = service.ExportFullChain
+	}
+
+	file, err := h.Store.Export(mc, req.Format, req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s", file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// RevokeCertificate 向 CA 撤銷憑證
+// reason 為 RFC 5280 CRLReason，只接受 0 unspecified / 1 keyCompromise / 3 affiliationChanged / 4 superseded / 5 cessationOfOperation
+func (h *CertificateHandler) RevokeCertificate(c *gin.Context) {
+	var req struct {
+		Reason uint `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+			return
+		}
+	}
+	// 不支援的原因代碼直接擋下，不送到 CA
+	if _, ok := domain.RevocationReasons[req.Reason]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支援的撤銷原因代碼: %d (可用 0, 1, 3, 4, 5)", req.Reason)})
+		return
+	}
+
+	mc, ok := h.findCertificate(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Acme.Revoke(c.Request.Context(), mc, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "撤銷成功"})
+}
+
+func (h *CertificateHandler) findCertificate(c *gin.Context) (*domain.ManagedCertificate, bool) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return nil, false
+	}
+
+	mc, err := h.Repo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該憑證"})
+		return nil, false
+	}
+	return mc, true
+}
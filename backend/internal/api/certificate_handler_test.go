@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRevokeCertificateRejectsUnsupportedReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Handler 沒有任何依賴：不合法的原因必須在查詢憑證、呼叫 CA 之前就回 400
+	h := &CertificateHandler{}
+	r := gin.New()
+	r.POST("/certificates/:id/revoke", h.RevokeCertificate)
+
+	revoke := func(id, body string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/certificates/"+id+"/revoke", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Error
+	}
+
+	for _, reason := range []string{"2", "6", "7", "8", "9", "10", "42"} {
+		code, msg := revoke("not-an-id", `{"reason":`+reason+`}`)
+		if code != http.StatusBadRequest || !strings.Contains(msg, "撤銷原因") {
+			t.Errorf("reason %s: got %d %q, want 400 unsupported reason", reason, code, msg)
+		}
+	}
+
+	// 合法的原因會通過檢查，接著才因 ID 無效被擋下
+	for _, body := range []string{"", `{"reason":0}`, `{"reason":1}`, `{"reason":3}`, `{"reason":4}`, `{"reason":5}`} {
+		code, msg := revoke("not-an-id", body)
+		if code != http.StatusBadRequest || msg != "無效的 ID 格式" {
+			t.Errorf("body %q: got %d %q, want reason accepted and ID rejected", body, code, msg)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CloudflareAccountHandler struct {
+	Accounts  repository.CloudflareAccountRepository
+	CFService *service.CloudflareService
+}
+
+func NewCloudflareAccountHandler(accounts repository.CloudflareAccountRepository, cf *service.CloudflareService) *CloudflareAccountHandler {
+	return &CloudflareAccountHandler{Accounts: accounts, CFService: cf}
+}
+
+// cloudflareAccountRequest Token 不會出現在回應中，因此新增/更新使用獨立的請求結構
+type cloudflareAccountRequest struct {
+	Name     string `json:"name"`
+	APIToken string `json:"api_token"` // 更新時留空代表不變更
+}
+
+// ListAccounts 列出所有 Cloudflare 帳號 (含最近一次同步結果)
+func (h *CloudflareAccountHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.CFService.ListAccounts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// CreateAccount 新增 Cloudflare 帳號 (會先向 Cloudflare 驗證 Token)
+func (h *CloudflareAccountHandler) CreateAccount(c *gin.Context) {
+	var req cloudflareAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+
+	account := domain.CloudflareAccount{Name: req.Name, APIToken: req.APIToken}
+	if err := h.CFService.CreateAccount(c.Request.Context(), &account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "新增成功", "data": account})
+}
+
+// UpdateAccount 更新帳號名稱或 Token
+func (h *CloudflareAccountHandler) UpdateAccount(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req cloudflareAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	if _, err := h.Accounts.GetByID(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該帳號"})
+		return
+	}
+
+	account := domain.CloudflareAccount{ID: oid, Name: req.Name, APIToken: req.APIToken}
+	if err := h.CFService.UpdateAccount(c.Request.Context(), account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+}
+
+// DeleteAccount 刪除 Cloudflare 帳號
+// 該帳號的域名會在下次同步時視為已從 Cloudflare 移除
+func (h *CloudflareAccountHandler) DeleteAccount(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	if _, err := h.Accounts.GetByID(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該帳號"})
+		return
+	}
+	if err := h.Accounts.Delete(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
+}
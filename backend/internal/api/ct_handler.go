@@ -0,0 +1,64 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type CTHandler struct {
+	Repo    repository.CTRepository
+	Service *service.CTMonitorService
+}
+
+func NewCTHandler(r repository.CTRepository, s *service.CTMonitorService) *CTHandler {
+	return &CTHandler{Repo: r, Service: s}
+}
+
+// ListLogs 各 CT Log 的讀取進度
+func (h *CTHandler) ListLogs(c *gin.Context) {
+	states, err := h.Repo.ListLogStates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if states == nil {
+		states = []domain.CTLogState{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": states})
+}
+
+// ListIssuances 最近發現的憑證 (預設 100 筆)
+func (h *CTHandler) ListIssuances(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 64)
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	issuances, err := h.Repo.ListIssuances(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if issuances == nil {
+		issuances = []domain.CTIssuance{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": issuances})
+}
+
+// Poll 手動觸發一次 CT Log 讀取
+func (h *CTHandler) Poll(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"message": "CT 監控已在背景啟動"})
+
+	go func() {
+		if err := h.Service.Poll(context.Background()); err != nil {
+			logrus.Errorf("❌ [CT] 手動讀取失敗: %v", err)
+		}
+	}()
+}
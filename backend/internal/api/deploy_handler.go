@@ -0,0 +1,133 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DeployHandler struct {
+	Repo     repository.DeployTargetRepository
+	Certs    repository.CertificateRepository
+	Deployer *service.DeployService
+}
+
+func NewDeployHandler(r repository.DeployTargetRepository, certs repository.CertificateRepository, d *service.DeployService) *DeployHandler {
+	return &DeployHandler{Repo: r, Certs: certs, Deployer: d}
+}
+
+// ListTargets 列出域名的部署目標
+func (h *DeployHandler) ListTargets(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	targets, err := h.Repo.ListByDomainID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": targets})
+}
+
+// CreateTarget 新增部署目標
+func (h *DeployHandler) CreateTarget(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var target domain.DeployTarget
+	if err := c.ShouldBindJSON(&target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	target.ID = primitive.NilObjectID
+	target.DomainID = oid
+	target.CFCustomCertID = ""
+
+	if err := h.Deployer.ValidateTarget(&target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.Create(c.Request.Context(), &target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "新增成功", "data": target})
+}
+
+// UpdateTarget 更新部署目標
+func (h *DeployHandler) UpdateTarget(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var target domain.DeployTarget
+	if err := c.ShouldBindJSON(&target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+		return
+	}
+	target.ID = oid
+
+	if err := h.Deployer.ValidateTarget(&target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.Update(c.Request.Context(), target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+}
+
+// DeleteTarget 刪除部署目標
+func (h *DeployHandler) DeleteTarget(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	if err := h.Repo.Delete(c.Request.Context(), oid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
+}
+
+// RunTarget 立即將域名最新的憑證部署到此目標
+func (h *DeployHandler) RunTarget(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	target, err := h.Repo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該部署目標"})
+		return
+	}
+	mc, err := h.Certs.GetLatestByDomainID(c.Request.Context(), target.DomainID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "該域名尚無可部署的憑證"})
+		return
+	}
+
+	result := h.Deployer.DeployTo(c.Request.Context(), target, mc)
+	if result.Status != domain.DeployStatusSuccess {
+		c.JSON(http.StatusBadGateway, gin.H{"error": result.Error, "data": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "部署完成", "data": result})
+}
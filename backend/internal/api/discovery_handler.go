@@ -0,0 +1,110 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DiscoveryHandler struct {
+	Repo     repository.DiscoveryRepository
+	Service  *service.DiscoveryService
+	Scanner  *service.ScannerService
+	Notifier *service.NotifierService
+}
+
+func NewDiscoveryHandler(r repository.DiscoveryRepository, s *service.DiscoveryService, scanner *service.ScannerService, n *service.NotifierService) *DiscoveryHandler {
+	return &DiscoveryHandler{Repo: r, Service: s, Scanner: scanner, Notifier: n}
+}
+
+// ListDiscovered 探索到的主機名稱 (預設只列出待確認，status=all 列出全部)
+func (h *DiscoveryHandler) ListDiscovered(c *gin.Context) {
+	status := c.DefaultQuery("status", domain.DiscoveryStatusPending)
+	if status == "all" {
+		status = ""
+	}
+
+	hosts, err := h.Repo.List(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hosts == nil {
+		hosts = []domain.DiscoveredHost{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hosts, "total": len(hosts)})
+}
+
+// RunDiscovery 立即從 SAN / CT 紀錄更新探索結果
+func (h *DiscoveryHandler) RunDiscovery(c *gin.Context) {
+	created, err := h.Service.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("探索完成，新發現 %d 筆", created), "created": created})
+}
+
+// AcceptDiscovered 加入監控並在背景立即掃描
+func (h *DiscoveryHandler) AcceptDiscovered(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+	if _, err := h.Repo.GetByID(c.Request.Context(), objID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該項目"})
+		return
+	}
+
+	// Port 可省略 (預設 443)
+	var req struct {
+		Port int `json:"port"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	cert, err := h.Service.Accept(c.Request.Context(), objID, req.Port)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.Notifier.NotifyOperation(c.Request.Context(), service.EventAdd, cert.DomainName,
+		fmt.Sprintf("由探索建議加入 (%s, IP: %s)", cert.CFComment, c.ClientIP()))
+
+	created := *cert
+	go func() {
+		if _, _, err := h.Scanner.ScanOne(context.Background(), created, false); err != nil {
+			logrus.Errorf("❌ [Discovery] 掃描失敗 %s: %v", created.DomainName, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "已加入監控", "data": cert})
+}
+
+// IgnoreDiscovered 忽略探索結果
+func (h *DiscoveryHandler) IgnoreDiscovered(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	if err := h.Service.Ignore(c.Request.Context(), objID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該項目"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已忽略"})
+}
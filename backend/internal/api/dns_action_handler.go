@@ -0,0 +1,134 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DNSActionHandler struct {
+	Repo    repository.DomainRepository
+	Service *service.DNSActionService
+	Scanner *service.ScannerService
+}
+
+func NewDNSActionHandler(r repository.DomainRepository, s *service.DNSActionService, scanner *service.ScannerService) *DNSActionHandler {
+	return &DNSActionHandler{Repo: r, Service: s, Scanner: scanner}
+}
+
+// SetProxy 切換 Cloudflare Proxy (橘雲 / 灰雲)
+func (h *DNSActionHandler) SetProxy(c *gin.Context) {
+	objID, ok := h.findDomain(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Proxied *bool `json:"proxied" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.update(c, objID, service.DNSRecordChange{Proxied: req.Proxied})
+}
+
+// UpdateRecord 修改紀錄的 Content 或 Comment
+func (h *DNSActionHandler) UpdateRecord(c *gin.Context) {
+	objID, ok := h.findDomain(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Content *string `json:"content"`
+		Comment *string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.update(c, objID, service.DNSRecordChange{Content: req.Content, Comment: req.Comment})
+}
+
+// DeleteRecord 刪除 Cloudflare 紀錄並移除監控
+func (h *DNSActionHandler) DeleteRecord(c *gin.Context) {
+	objID, ok := h.findDomain(c)
+	if !ok {
+		return
+	}
+
+	cert, err := h.Service.DeleteRecord(c.Request.Context(), objID, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "紀錄已刪除", "data": cert})
+}
+
+// CreateRecord 在 Zone 中新增紀錄並加入監控，背景立即掃描
+func (h *DNSActionHandler) CreateRecord(c *gin.Context) {
+	var req struct {
+		Name    string `json:"name" binding:"required"`
+		Type    string `json:"type" binding:"required"`
+		Content string `json:"content" binding:"required"`
+		Proxied bool   `json:"proxied"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	record := service.DNSRecord{Name: req.Name, Type: req.Type, Content: req.Content, Proxied: req.Proxied, Comment: req.Comment}
+	cert, err := h.Service.CreateRecord(c.Request.Context(), c.Param("zone"), record, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.rescan(*cert)
+	c.JSON(http.StatusOK, gin.H{"message": "紀錄已新增", "data": cert})
+}
+
+// update 寫回變更，成功後在背景重新掃描
+func (h *DNSActionHandler) update(c *gin.Context, id primitive.ObjectID, change service.DNSRecordChange) {
+	cert, err := h.Service.UpdateRecord(c.Request.Context(), id, change, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.rescan(*cert)
+	c.JSON(http.StatusOK, gin.H{"message": "紀錄已更新", "data": cert})
+}
+
+// findDomain 解析 ID 並確認域名存在，失敗時直接回應
+func (h *DNSActionHandler) findDomain(c *gin.Context) (primitive.ObjectID, bool) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return objID, false
+	}
+	if _, err := h.Repo.GetByID(c.Request.Context(), objID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return objID, false
+	}
+	return objID, true
+}
+
+func (h *DNSActionHandler) rescan(cert domain.SSLCertificate) {
+	go func() {
+		if _, _, err := h.Scanner.ScanOne(context.Background(), cert, false); err != nil {
+			logrus.Errorf("❌ [DNS] 重新掃描失敗 %s: %v", cert.DomainName, err)
+		}
+	}()
+}
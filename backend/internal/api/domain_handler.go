@@ -26,16 +26,18 @@ type DomainHandler struct {
 	Scanner   *service.ScannerService
 	Notifier  *service.NotifierService
 	Cron      *service.CronService
+	Certs     repository.CertificateRepository
 }
 
 // [新增] 定義請求結構
 type UpdateSettingsRequest struct {
 	IsIgnored *bool `json:"is_ignored"`
 	Port      *int  `json:"port"`
+	AutoRenew *bool `json:"auto_renew"`
 }
 
-func NewDomainHandler(r repository.DomainRepository, c *service.CloudflareService, s *service.ScannerService, n *service.NotifierService, cron *service.CronService) *DomainHandler {
-	return &DomainHandler{Repo: r, CFService: c, Scanner: s, Notifier: n, Cron: cron}
+func NewDomainHandler(r repository.DomainRepository, c *service.CloudflareService, s *service.ScannerService, n *service.NotifierService, cron *service.CronService, certs repository.CertificateRepository) *DomainHandler {
+	return &DomainHandler{Repo: r, CFService: c, Scanner: s, Notifier: n, Cron: cron, Certs: certs}
 }
 
 // =============================================================================
@@ -69,6 +71,28 @@ func (h *DomainHandler) GetDomains(c *gin.Context) {
 	})
 }
 
+// GetDomain 獲取單一域名詳情 (含系統保管的最新憑證與 ARI 續簽時段)
+func (h *DomainHandler) GetDomain(c *gin.Context) {
+	oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	d, err := h.Repo.GetByID(c.Request.Context(), oid)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到該域名"})
+		return
+	}
+
+	resp := gin.H{"data": d}
+	if mc, err := h.Certs.GetLatestByDomainID(c.Request.Context(), oid); err == nil {
+		resp["managed_certificate"] = mc
+		resp["renewal_info"] = mc.RenewalInfo
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetZones 獲取所有主域名清單
 func (h *DomainHandler) GetZones(c *gin.Context) {
 	zones, err := h.Repo.GetUniqueZones(c.Request.Context())
@@ -132,12 +156,13 @@ func (h *DomainHandler) SyncDomains(c *gin.Context) {
 		}
 
 		h.Notifier.NotifyTaskFinish(ctx, service.EventSyncFinish, service.TaskSummaryData{
-			Added:    stats.Added,
-			Updated:  stats.Updated,
-			Deleted:  stats.Deleted,
-			Skipped:  stats.Skipped,
-			Duration: stats.Duration,
-			Details:  detailsBuilder.String(),
+			Added:     stats.Added,
+			Updated:   stats.Updated,
+			Deleted:   stats.Deleted,
+			Skipped:   stats.Skipped,
+			Unchanged: stats.Unchanged,
+			Duration:  stats.Duration,
+			Details:   detailsBuilder.String(),
 		})
 		logrus.Infof("🏁 [Sync] 手動同步完成 (耗時: %s)", stats.Duration)
 	}()
@@ -297,7 +322,7 @@ func (h *DomainHandler) BatchScanDomains(c *gin.Context) {
 	}()
 }
 
-// UpdateSettings 更新單一域名設定 (Port, Ignored)
+// UpdateSettings 更新單一域名設定 (Port, Ignored, AutoRenew)
 func (h *DomainHandler) UpdateSettings(c *gin.Context) {
 	idStr := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(idStr)
@@ -328,13 +353,18 @@ func (h *DomainHandler) UpdateSettings(c *gin.Context) {
 		newPort = *req.Port
 	}
 
-	err = h.Repo.UpdateSettings(c.Request.Context(), idStr, newIgnored, newPort)
+	newAutoRenew := currentDomain.AutoRenew
+	if req.AutoRenew != nil {
+		newAutoRenew = *req.AutoRenew
+	}
+
+	err = h.Repo.UpdateSettings(c.Request.Context(), idStr, newIgnored, newPort, newAutoRenew)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "設定已更新", "port": newPort, "is_ignored": newIgnored})
+	c.JSON(http.StatusOK, gin.H{"message": "設定已更新", "port": newPort, "is_ignored": newIgnored, "auto_renew": newAutoRenew})
 }
 
 // BatchUpdateSettings 批量更新設定
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"cert-manager/internal/service"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FileCertHandler struct {
+	Repo    repository.FileCertificateRepository
+	Service *service.FileCertService
+}
+
+func NewFileCertHandler(r repository.FileCertificateRepository, s *service.FileCertService) *FileCertHandler {
+	return &FileCertHandler{Repo: r, Service: s}
+}
+
+// ListFileCertificates 磁碟憑證清單 (依到期日排序)
+func (h *FileCertHandler) ListFileCertificates(c *gin.Context) {
+	certs, err := h.Repo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if certs == nil {
+		certs = []domain.FileCertificate{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": certs, "total": len(certs)})
+}
+
+// ScanFileCertificates 手動重新掃描所有監看目錄
+func (h *FileCertHandler) ScanFileCertificates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"message": "掃描任務已在背景啟動"})
+
+	go func() {
+		if err := h.Service.ScanAll(context.Background()); err != nil {
+			logrus.Errorf("❌ [FileCert] 背景掃描失敗: %v", err)
+		}
+	}()
+}
+
+// UpdateFileCertSettings 開關單一憑證的到期告警
+func (h *FileCertHandler) UpdateFileCertSettings(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 ID 格式"})
+		return
+	}
+
+	var req struct {
+		IsIgnored *bool `json:"is_ignored" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Repo.UpdateIgnored(c.Request.Context(), objID, *req.IsIgnored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "設定已更新"})
+}
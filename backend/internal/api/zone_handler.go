@@ -0,0 +1,110 @@
+package api
+
+import (
+	"cert-manager/internal/service"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const maxZoneFileSize = 10 << 20 // 10 MB
+
+type ZoneFileHandler struct {
+	Service  *service.ZoneFileService
+	Scanner  *service.ScannerService
+	Notifier *service.NotifierService
+}
+
+func NewZoneFileHandler(s *service.ZoneFileService, scanner *service.ScannerService, n *service.NotifierService) *ZoneFileHandler {
+	return &ZoneFileHandler{Service: s, Scanner: scanner, Notifier: n}
+}
+
+// ImportZone 匯入 BIND Zone File
+// 支援 multipart (欄位 file) 或直接以純文字作為 Body；Zone 名稱由 zone 參數或檔案中的 $ORIGIN 決定
+func (h *ZoneFileHandler) ImportZone(c *gin.Context) {
+	content, err := readZoneFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	zone := c.Query("zone")
+	if zone == "" {
+		zone = c.PostForm("zone")
+	}
+
+	result, err := h.Service.Import(c.Request.Context(), content, zone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(result.Created) > 0 {
+		h.Notifier.NotifyOperation(c.Request.Context(), service.EventAdd, result.Zone,
+			fmt.Sprintf("Zone File 匯入 %d 筆域名 (IP: %s)", len(result.Created), c.ClientIP()))
+
+		// 新域名在背景逐一掃描，不必等下一次排程
+		created := result.Created
+		go func() {
+			ctx := context.Background()
+			for _, d := range created {
+				if _, _, err := h.Scanner.ScanOne(ctx, d, false); err != nil {
+					logrus.Errorf("❌ [ZoneFile] 掃描失敗 %s: %v", d.DomainName, err)
+				}
+			}
+			logrus.Infof("🏁 [ZoneFile] %s 匯入的 %d 筆域名掃描完成", result.Zone, len(created))
+		}()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("匯入完成，新增 %d 筆域名", len(result.Created)), "data": result})
+}
+
+// ExportZone 以 BIND 格式匯出目前監控中的 Zone 紀錄
+func (h *ZoneFileHandler) ExportZone(c *gin.Context) {
+	zone := strings.TrimSuffix(c.Param("zone"), ".")
+	content, count, err := h.Service.Export(c.Request.Context(), zone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "該 Zone 沒有監控中的域名"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment;filename=%s.zone", zone))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(content))
+}
+
+// readZoneFile 讀取上傳的 Zone File 內容 (限制大小)
+func readZoneFile(c *gin.Context) (string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxZoneFileSize)
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return "", fmt.Errorf("請上傳 Zone File (欄位 file)")
+		}
+		f, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("讀取 Zone File 失敗: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return "", fmt.Errorf("Zone File 內容為空")
+	}
+	return string(data), nil
+}
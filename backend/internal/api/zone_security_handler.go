@@ -0,0 +1,30 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ZoneSecurityHandler struct {
+	Service *service.ZoneSecurityService
+}
+
+func NewZoneSecurityHandler(s *service.ZoneSecurityService) *ZoneSecurityHandler {
+	return &ZoneSecurityHandler{Service: s}
+}
+
+// ListZoneSecurity 各 Zone 的 SSL/TLS 模式、最低 TLS 版本與 Edge 憑證包 (同步時更新)
+func (h *ZoneSecurityHandler) ListZoneSecurity(c *gin.Context) {
+	zones, err := h.Service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if zones == nil {
+		zones = []domain.ZoneSecurity{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": zones})
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ZoneSyncHandler struct {
+	Service *service.DNSSyncService
+}
+
+func NewZoneSyncHandler(s *service.DNSSyncService) *ZoneSyncHandler {
+	return &ZoneSyncHandler{Service: s}
+}
+
+// ListZoneSync 各 Provider 帳號下 Zone 的同步進度 (最新紀錄修改時間、上次同步時間與紀錄數)
+func (h *ZoneSyncHandler) ListZoneSync(c *gin.Context) {
+	states, err := h.Service.ListSyncStates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if states == nil {
+		states = []domain.ZoneSyncState{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": states})
+}
@@ -9,6 +9,15 @@ type Config struct {
 	Server     ServerConfig
 	MongoDB    MongoConfig
 	Cloudflare CloudflareConfig
+	Route53    Route53Config
+	PowerDNS   PowerDNSConfig
+	AXFR       AXFRConfig
+	Kubernetes KubernetesConfig
+	FileWatch  FileWatchConfig `mapstructure:"file_watch"`
+	CT         CTConfig
+	Security   SecurityConfig
+	Acme       AcmeConfig
+	Deploy     DeployConfig
 }
 
 type ServerConfig struct {
@@ -21,7 +30,95 @@ type MongoConfig struct {
 }
 
 type CloudflareConfig struct {
-	APIToken string `mapstructure:"api_token"`
+	APIToken string `mapstructure:"api_token"` // 舊設定：首次啟動時遷移成 Cloudflare 帳號，之後改由 API 管理
+}
+
+// Route53Config 留空 AccessKeyID 代表不啟用 Route 53 同步
+type Route53Config struct {
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"` // 臨時憑證 (STS) 才需要
+	Endpoint        string // 預設 https://route53.amazonaws.com，測試時可指向本地 Mock
+	Region          string // 簽章用 Region，預設 us-east-1
+}
+
+// PowerDNSConfig 留空 APIURL 代表不啟用 PowerDNS 同步
+type PowerDNSConfig struct {
+	APIURL   string `mapstructure:"api_url"` // e.g. http://pdns:8081
+	APIKey   string `mapstructure:"api_key"`
+	ServerID string `mapstructure:"server_id"` // 預設 localhost
+}
+
+// AXFRConfig 以 Zone Transfer 同步的 DNS Server (BIND 等)
+type AXFRConfig struct {
+	Servers []AXFRServerConfig
+}
+
+type AXFRServerConfig struct {
+	Name          string
+	Address       string   // host:port，未指定 Port 時使用 53
+	Zones         []string // AXFR 無法列出 Zone，需逐一指定
+	TSIGName      string   `mapstructure:"tsig_name"`
+	TSIGSecret    string   `mapstructure:"tsig_secret"`    // Base64
+	TSIGAlgorithm string   `mapstructure:"tsig_algorithm"` // 預設 hmac-sha256
+}
+
+// KubernetesConfig 從 Ingress / HTTPRoute 探索主機名稱的叢集
+type KubernetesConfig struct {
+	Clusters []KubernetesClusterConfig
+}
+
+type KubernetesClusterConfig struct {
+	Name       string
+	InCluster  bool     `mapstructure:"in_cluster"` // 使用 Pod 的 ServiceAccount
+	Kubeconfig string   // 未指定時使用 KUBECONFIG 環境變數
+	Context    string   // 未指定時使用 current-context
+	Namespaces []string // 空值代表整個叢集
+}
+
+// FileWatchConfig 監看磁碟上的憑證檔案 (PEM / DER / PKCS#12 / JKS)
+type FileWatchConfig struct {
+	Directories []string // 遞迴監看，空值代表不啟用
+	Passwords   []string // 嘗試用來解開 PKCS#12 的密碼 (空密碼會自動嘗試)
+}
+
+// CTConfig Certificate Transparency 監控 (RFC 6962)，啟用與允許的 Issuer 在系統設定中調整
+type CTConfig struct {
+	Logs       []CTLogConfig
+	BatchSize  int   `mapstructure:"batch_size"`  // 每次 get-entries 的數量，預設 256
+	MaxEntries int64 `mapstructure:"max_entries"` // 每次排程每個 Log 最多讀取的 Entry 數量，預設 100000
+}
+
+type CTLogConfig struct {
+	Name string
+	URL  string // Log 的 Base URL (e.g. https://ct.googleapis.com/logs/us1/argon2025h2)
+}
+
+type SecurityConfig struct {
+	EncryptionKey string `mapstructure:"encryption_key"` // 用於加密保存的私鑰
+	// 未設定 encryption_key 時從此檔案讀取金鑰，檔案不存在則在首次啟動時產生 (權限 0600)
+	EncryptionKeyFile string `mapstructure:"encryption_key_file"`
+}
+
+type AcmeConfig struct {
+	// HTTP-01 獨立 Listener (e.g. ":80")，空字串代表只由主服務的路由回應
+	HTTP01Listen string `mapstructure:"http01_listen"`
+}
+
+// DeployConfig 部署目標可使用的 Hook 指令與輸出目錄，API 只能以名稱引用 (不接受任意指令或路徑)
+type DeployConfig struct {
+	Hooks       []DeployHookConfig
+	Directories []DeployDirectoryConfig
+}
+
+type DeployHookConfig struct {
+	Name    string
+	Command string // 透過 sh -c 執行，憑證路徑與資訊透過環境變數傳入
+}
+
+type DeployDirectoryConfig struct {
+	Name string
+	Path string // 絕對路徑
 }
 
 func LoadConfig() (*Config, error) {
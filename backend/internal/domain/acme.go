@@ -4,10 +4,61 @@ import (
 	"crypto"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"time"
 
 	"github.com/go-acme/lego/v4/registration"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// 常用的 ACME Directory
+const (
+	AcmeDirectoryLEProduction = "https://acme-v02.api.letsencrypt.org/directory"
+	AcmeDirectoryLEStaging    = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	AcmeDirectoryZeroSSL      = "https://acme.zerossl.com/v2/DV90"           // 需要 EAB
+	AcmeDirectoryGoogle       = "https://dv.acme-v02.api.pki.goog/directory" // 需要 EAB
+)
+
+// ACME 驗證方式
+const (
+	AcmeChallengeDNS01  = "dns-01"  // Cloudflare DNS
+	AcmeChallengeHTTP01 = "http-01" // 由本服務回應 /.well-known/acme-challenge/:token
+)
+
+// AcmeChallenge 等待 CA 驗證的 HTTP-01 Token (acme_challenges collection)
+// 存在 Mongo 讓每個 Replica 都能回應驗證請求
+type AcmeChallenge struct {
+	Token      string    `bson:"_id" json:"token"`
+	DomainName string    `bson:"domain_name" json:"domain_name"`
+	KeyAuth    string    `bson:"key_auth" json:"key_auth"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+}
+
+// AcmeAccount 一個 ACME CA 上的帳號 (acme_accounts collection)
+type AcmeAccount struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"` // 顯示用名稱 (e.g. "LE Staging")
+
+	Email        string `bson:"email" json:"email"`
+	DirectoryURL string `bson:"directory_url" json:"directory_url"`
+
+	// External Account Binding (ZeroSSL / Google Trust Services 等需要)
+	// HMAC 是機密，只在新增帳號時接受，不會出現在 API 回應中
+	EABKeyID   string `bson:"eab_key_id" json:"eab_key_id"`
+	EABHMACKey string `bson:"eab_hmac_key" json:"-"` // base64url
+
+	// 自訂根憑證 (PEM)，用於內部 CA (step-ca / Pebble) 的 TLS 驗證
+	RootCAPEM string `bson:"root_ca_pem" json:"root_ca_pem"`
+
+	// 預設帳號：簽發時未指定帳號就使用此帳號
+	IsDefault bool `bson:"is_default" json:"is_default"`
+
+	PrivateKey string `bson:"private_key" json:"-"` // 存 PEM 格式
+	RegData    string `bson:"reg_data" json:"-"`    // 存註冊資訊 JSON
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
 // AcmeUser 實作 lego 的 User 介面
 type AcmeUser struct {
 	Email        string
@@ -25,8 +76,21 @@ func (u *AcmeUser) GetPrivateKey() crypto.PrivateKey {
 	return u.PrivateKey
 }
 
-// 輔助函式：從 PEM 字串解析私鑰 (之後會用到)
+// 輔助函式：從 PEM 字串解析私鑰 (支援 EC / RSA / PKCS#8)
 func ParsePrivateKey(pemBytes []byte) (crypto.PrivateKey, error) {
 	block, _ := pem.Decode(pemBytes)
-	return x509.ParseECPrivateKey(block.Bytes)
+	if block == nil {
+		return nil, errors.New("invalid PEM private key")
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.New("unsupported private key type: " + block.Type)
+	}
 }
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InternalCA 內建私有 CA (internal_ca collection，只會有一筆)
+// 由 Root 簽出 Intermediate，再由 Intermediate 簽發內部主機的憑證
+type InternalCA struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"`
+
+	RootCertPEM string `bson:"root_cert_pem" json:"root_cert_pem"`
+	// 匯入既有 Root 時可以不提供私鑰 (建議 Root 私鑰離線保存)
+	RootEncryptedKey string `bson:"root_encrypted_key" json:"-"`
+
+	IntermediateCertPEM      string `bson:"intermediate_cert_pem" json:"intermediate_cert_pem"`
+	IntermediateEncryptedKey string `bson:"intermediate_encrypted_key" json:"-"`
+
+	RootNotAfter         time.Time `bson:"root_not_after" json:"root_not_after"`
+	IntermediateNotAfter time.Time `bson:"intermediate_not_after" json:"intermediate_not_after"`
+	HasRootKey           bool      `bson:"has_root_key" json:"has_root_key"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -15,20 +15,38 @@ const (
 	StatusPending         = "pending"
 )
 
+// DNS Provider 名稱 (SSLCertificate.Provider)
+const (
+	ProviderCloudflare = "cloudflare"
+	ProviderRoute53    = "route53"
+	ProviderPowerDNS   = "powerdns"
+	ProviderAXFR       = "axfr"
+	ProviderZoneFile   = "zonefile" // Zone File 匯入，不屬於任何同步來源，同步時不會被刪除
+	ProviderKubernetes = "kubernetes"
+	ProviderDiscovery  = "discovery" // 由探索建議接受加入，與 Zone File 相同不會被同步刪除
+)
+
 type SSLCertificate struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	DomainName string             `bson:"domain_name" json:"domain_name"`
 
-	// Cloudflare 資訊 (用於排序和識別 Proxy)
-	CFZoneID   string `bson:"cf_zone_id" json:"cf_zone_id"`
-	ZoneName   string `bson:"zone_name" json:"zone_name"`
-	CFRecordID string `bson:"cf_record_id" json:"cf_record_id"`
-	IsProxied  bool   `bson:"is_proxied" json:"is_proxied"` // 小橘雲是否開啟
-	Port       int    `bson:"port" json:"port"`
+	// DNS 來源資訊 (Provider 為空代表手動新增)
+	Provider  string `bson:"provider" json:"provider"` // e.g. "cloudflare"
+	ZoneID    string `bson:"zone_id" json:"zone_id"`
+	ZoneName  string `bson:"zone_name" json:"zone_name"`
+	RecordID  string `bson:"record_id" json:"record_id"`
+	IsProxied bool   `bson:"is_proxied" json:"is_proxied"` // 小橘雲是否開啟
+	Port      int    `bson:"port" json:"port"`
+
+	// Provider 上紀錄的最後修改時間 (Cloudflare modified_on)，增量同步據此判斷是否需要重新掃描
+	RecordModifiedOn time.Time `bson:"record_modified_on" json:"record_modified_on"`
+
+	// 來源 Cloudflare 帳號 (空值代表舊資料或手動新增)
+	CFAccountID primitive.ObjectID `bson:"cf_account_id,omitempty" json:"cf_account_id,omitempty"`
 
 	// 監控設定
 	IsIgnored bool `bson:"is_ignored" json:"is_ignored"` // 開關檢查按鈕
-	AutoRenew bool `bson:"auto_renew" json:"auto_renew"` // Let's Encrypt 預留欄位
+	AutoRenew bool `bson:"auto_renew" json:"auto_renew"` // 到期前自動透過 ACME 續簽
 
 	// 憑證狀態
 	Issuer        string    `bson:"issuer" json:"issuer"`
@@ -64,4 +82,58 @@ type SSLCertificate struct {
 	// ResolvedIP string `bson:"resolved_ip" json:"resolved_ip"` // [新增] 解析後的 IP
 	// true = 匹配, false = 不匹配 (例如 example.com 用了 google.com 的憑證)
 	IsMatch bool `bson:"is_match" json:"is_match"`
+
+	// 多筆 A / AAAA 時逐一對每個 IP 握手的結果 (單一 IP 時為空)
+	Endpoints []EndpointResult `bson:"endpoints" json:"endpoints"`
+	// 各節點憑證不一致、過期或名稱不符時的說明 (空字串代表一致)
+	EndpointIssue string `bson:"endpoint_issue" json:"endpoint_issue"`
+
+	// Cloudflare Origin CA 憑證 (Proxy 後方源站使用)，與上面掃描到的邊緣憑證分開追蹤
+	OriginCertID        primitive.ObjectID `bson:"origin_cert_id,omitempty" json:"origin_cert_id,omitempty"`
+	OriginNotAfter      time.Time          `bson:"origin_not_after,omitempty" json:"origin_not_after,omitempty"`
+	OriginDaysRemaining int                `bson:"origin_days_remaining,omitempty" json:"origin_days_remaining,omitempty"`
+
+	// Proxy 開啟時直接連線源站 (CFOriginValue，SNI 為 DomainName) 取得的實際憑證，告警與邊緣憑證分開
+	OriginCheck         *EndpointResult `bson:"origin_check,omitempty" json:"origin_check,omitempty"`
+	OriginLastAlertTime time.Time       `bson:"origin_last_alert_time" json:"origin_last_alert_time"`
+
+	// 同步時偵測到的 DNS 紀錄變更 (新的在後，只保留最近幾筆)
+	ChangeHistory []DNSChange `bson:"change_history,omitempty" json:"change_history,omitempty"`
+}
+
+// DNSChange 一次同步中偵測到的紀錄變更 (Content / Type / Proxy)
+type DNSChange struct {
+	DetectedAt time.Time `bson:"detected_at" json:"detected_at"`
+
+	OldType    string `bson:"old_type" json:"old_type"`
+	NewType    string `bson:"new_type" json:"new_type"`
+	OldContent string `bson:"old_content" json:"old_content"`
+	NewContent string `bson:"new_content" json:"new_content"`
+	OldProxied bool   `bson:"old_proxied" json:"old_proxied"`
+	NewProxied bool   `bson:"new_proxied" json:"new_proxied"`
+
+	// 從 Provider 稽核紀錄找到的變更者 (查不到或 Provider 不支援時為 nil)
+	Actor *DNSChangeActor `bson:"actor,omitempty" json:"actor,omitempty"`
+}
+
+// DNSChangeActor 稽核紀錄中的操作者
+type DNSChangeActor struct {
+	Email     string    `bson:"email" json:"email"`
+	IP        string    `bson:"ip" json:"ip"`
+	Type      string    `bson:"type" json:"type"`     // user / account / cloudflare ...
+	Action    string    `bson:"action" json:"action"` // e.g. "rec_set"
+	ChangedAt time.Time `bson:"changed_at" json:"changed_at"`
+}
+
+// EndpointResult 單一 IP 的 TLS 握手結果 (以網域名稱作為 SNI)
+type EndpointResult struct {
+	IP            string    `bson:"ip" json:"ip"`
+	Fingerprint   string    `bson:"fingerprint" json:"fingerprint"` // 葉憑證 SHA-256
+	Issuer        string    `bson:"issuer" json:"issuer"`
+	NotAfter      time.Time `bson:"not_after" json:"not_after"`
+	DaysRemaining int       `bson:"days_remaining" json:"days_remaining"`
+	TLSVersion    string    `bson:"tls_version" json:"tls_version"`
+	IsMatch       bool      `bson:"is_match" json:"is_match"`
+	Latency       int64     `bson:"latency" json:"latency"` // 毫秒 (ms)
+	Error         string    `bson:"error,omitempty" json:"error,omitempty"`
 }
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// 憑證來源
+const (
+	CertSourceAcme     = "acme"              // 由系統透過 ACME 簽發
+	CertSourceUpload   = "upload"            // 使用者手動上傳
+	CertSourceInternal = "internal"          // 由內建私有 CA 簽發
+	CertSourceOriginCA = "cloudflare_origin" // Cloudflare Origin CA (只被 Cloudflare Proxy 信任)
+)
+
+// ManagedCertificate 系統保管的憑證 (含私鑰)，一筆對應一次簽發/上傳
+type ManagedCertificate struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+
+	// 關聯的監控域名 (SSLCertificate.ID)
+	DomainID   primitive.ObjectID `bson:"domain_id" json:"domain_id"`
+	DomainName string             `bson:"domain_name" json:"domain_name"`
+	Source     string             `bson:"source" json:"source"`
+	// 簽發使用的 ACME 帳號 (僅 Source = acme)，續簽時沿用
+	AcmeAccountID primitive.ObjectID `bson:"acme_account_id,omitempty" json:"acme_account_id,omitempty"`
+	// Cloudflare Origin CA 的憑證 ID (僅 Source = cloudflare_origin)
+	CFOriginCertID string `bson:"cf_origin_cert_id,omitempty" json:"cf_origin_cert_id,omitempty"`
+
+	// 憑證內容 (PEM)
+	CertPEM  string `bson:"cert_pem" json:"cert_pem"`   // Leaf
+	ChainPEM string `bson:"chain_pem" json:"chain_pem"` // 中繼憑證
+	// 私鑰以 AES-GCM 加密後保存 (base64)，API 不直接輸出
+	EncryptedKey string `bson:"encrypted_key" json:"-"`
+
+	// 從 Leaf 解析出的資訊 (方便列表顯示與查詢)
+	Issuer       string    `bson:"issuer" json:"issuer"`
+	SerialNumber string    `bson:"serial_number" json:"serial_number"`
+	Fingerprint  string    `bson:"fingerprint" json:"fingerprint"` // SHA-256
+	SANs         []string  `bson:"sans" json:"sans"`
+	NotBefore    time.Time `bson:"not_before" json:"not_before"`
+	NotAfter     time.Time `bson:"not_after" json:"not_after"`
+
+	// ACME Renewal Information (RFC 9773)，CA 不支援時為 nil
+	RenewalInfo *RenewalInfo `bson:"renewal_info,omitempty" json:"renewal_info,omitempty"`
+
+	// 撤銷紀錄
+	RevokedAt        time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	RevocationReason *uint     `bson:"revocation_reason,omitempty" json:"revocation_reason,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// RenewalInfo CA 建議的續簽時段 (ARI)
+type RenewalInfo struct {
+	WindowStart    time.Time `bson:"window_start" json:"window_start"`
+	WindowEnd      time.Time `bson:"window_end" json:"window_end"`
+	RenewAt        time.Time `bson:"renew_at" json:"renew_at"` // 在時段內隨機挑選的續簽時間點
+	ExplanationURL string    `bson:"explanation_url,omitempty" json:"explanation_url,omitempty"`
+	CheckedAt      time.Time `bson:"checked_at" json:"checked_at"`
+	NextCheckAt    time.Time `bson:"next_check_at" json:"next_check_at"` // 依 Retry-After 決定下次查詢時間
+}
+
+// 可用的 RFC 5280 CRLReason (撤銷原因代碼)
+// CA/Browser Forum BR 與 Let's Encrypt 只接受這幾種，其他代碼 (cACompromise、certificateHold ...) 會被 CA 拒絕
+var RevocationReasons = map[uint]string{
+	0: "unspecified",
+	1: "keyCompromise",
+	3: "affiliationChanged",
+	4: "superseded",
+	5: "cessationOfOperation",
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cloudflare 帳號同步結果
+const (
+	CFSyncStatusSuccess = "success"
+	CFSyncStatusFailed  = "failed"
+)
+
+// CloudflareAccount 一組 Cloudflare API Token (cloudflare_accounts collection)
+// 每個帳號各自同步其可見的 Zone，域名以 SSLCertificate.CFAccountID 記錄來源
+type CloudflareAccount struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"` // 顯示用名稱 (e.g. "Production")
+
+	// Token 需具備 Zone:Read、DNS:Read (DNS-01 另需 DNS:Edit)
+	// 以憑證庫的金鑰 (security.encryption_key) 加密保存，APIToken 只在記憶體中使用
+	APIToken       string `bson:"-" json:"-"`
+	EncryptedToken string `bson:"encrypted_token" json:"-"`
+	LegacyToken    string `bson:"api_token,omitempty" json:"-"` // 舊版明文保存的 Token，讀取時遷移成 EncryptedToken
+	TokenHint      string `bson:"token_hint" json:"token_hint"` // Token 末 4 碼，方便辨識
+
+	// 最近一次同步結果
+	LastSyncAt     time.Time `bson:"last_sync_at" json:"last_sync_at"`
+	LastSyncStatus string    `bson:"last_sync_status" json:"last_sync_status"`
+	LastSyncError  string    `bson:"last_sync_error" json:"last_sync_error"`
+	LastSyncCount  int       `bson:"last_sync_count" json:"last_sync_count"` // 抓到的域名數量
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
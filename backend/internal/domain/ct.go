@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CTLogState 每個 CT Log 的讀取進度 (ct_log_states collection，以 Log URL 為 _id)
+type CTLogState struct {
+	URL  string `bson:"_id" json:"url"`
+	Name string `bson:"name" json:"name"`
+
+	NextIndex int64 `bson:"next_index" json:"next_index"` // 下一筆要讀取的 Entry
+	TreeSize  int64 `bson:"tree_size" json:"tree_size"`   // 最近一次 get-sth 的 Tree Size
+
+	LastPollAt time.Time `bson:"last_poll_at" json:"last_poll_at"`
+	LastError  string    `bson:"last_error" json:"last_error"`
+}
+
+// CTIssuance 在 CT Log 中發現、涵蓋到我們 Zone 的憑證 (ct_issuances collection)
+// Precert 與正式憑證的 Issuer + Serial 相同，只記錄 (與通知) 一次
+type CTIssuance struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Issuer       string             `bson:"issuer" json:"issuer"`           // Issuer DN
+	IssuerName   string             `bson:"issuer_name" json:"issuer_name"` // 顯示用 (CN 或 O)
+	SerialNumber string             `bson:"serial_number" json:"serial_number"`
+
+	DNSNames     []string  `bson:"dns_names" json:"dns_names"`
+	MatchedZones []string  `bson:"matched_zones" json:"matched_zones"`
+	NotBefore    time.Time `bson:"not_before" json:"not_before"`
+	NotAfter     time.Time `bson:"not_after" json:"not_after"`
+	IsPrecert    bool      `bson:"is_precert" json:"is_precert"`
+
+	LogName  string `bson:"log_name" json:"log_name"`
+	LogIndex int64  `bson:"log_index" json:"log_index"`
+
+	Allowed bool      `bson:"allowed" json:"allowed"` // Issuer 在允許清單內 (預期中的簽發)
+	SeenAt  time.Time `bson:"seen_at" json:"seen_at"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 部署目標類型
+const (
+	DeployTypeFile       = "file"       // 寫入 PEM 檔案到指定目錄
+	DeployTypeHook       = "hook"       // 執行設定檔中的指令 (憑證路徑透過環境變數傳入)
+	DeployTypeCloudflare = "cloudflare" // 上傳為 Cloudflare Custom Certificate
+	DeployTypeK8sSecret  = "k8s_secret" // 產生 Kubernetes TLS Secret Manifest
+)
+
+// 部署結果
+const (
+	DeployStatusSuccess = "success"
+	DeployStatusFailed  = "failed"
+)
+
+// DeployTarget 憑證簽發/續簽後的部署目標 (deploy_targets collection)
+// 一個域名可以有多個目標，依 Type 使用不同的欄位
+type DeployTarget struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DomainID primitive.ObjectID `bson:"domain_id" json:"domain_id"` // 關聯的 SSLCertificate
+	Name     string             `bson:"name" json:"name"`
+	Type     string             `bson:"type" json:"type"`
+	Enabled  bool               `bson:"enabled" json:"enabled"`
+
+	// file / k8s_secret / hook (選填): 輸出目錄的名稱 (config.yaml deploy.directories)
+	Directory string `bson:"directory" json:"directory"`
+	// file: 憑證檔權限 (八進位字串，預設 "0644")，私鑰固定 0600
+	FileMode string `bson:"file_mode" json:"file_mode"`
+
+	// hook: 指令的名稱 (config.yaml deploy.hooks)
+	Hook           string `bson:"hook" json:"hook"`
+	TimeoutSeconds int    `bson:"timeout_seconds" json:"timeout_seconds"` // 預設 300
+
+	// k8s_secret: 皆須為 DNS-1123 label
+	Namespace  string `bson:"namespace" json:"namespace"`
+	SecretName string `bson:"secret_name" json:"secret_name"`
+
+	// cloudflare: 上次上傳的 Custom Certificate ID (之後改用更新)
+	CFCustomCertID string `bson:"cf_custom_cert_id" json:"cf_custom_cert_id"`
+
+	// 最近一次部署結果
+	LastDeployAt   time.Time `bson:"last_deploy_at" json:"last_deploy_at"`
+	LastStatus     string    `bson:"last_status" json:"last_status"`
+	LastError      string    `bson:"last_error" json:"last_error"`
+	LastCertSerial string    `bson:"last_cert_serial" json:"last_cert_serial"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 探索到的主機名稱狀態
+const (
+	DiscoveryStatusPending  = "pending"  // 等待確認
+	DiscoveryStatusAccepted = "accepted" // 已加入監控
+	DiscoveryStatusIgnored  = "ignored"  // 已忽略，之後再被看到也不會重新出現
+)
+
+// 探索來源
+const (
+	DiscoverySourceCT  = "ct"  // CT Log 中的憑證
+	DiscoverySourceSAN = "san" // 已掃描憑證的 SAN
+)
+
+// DiscoveredHost 在憑證中看到、但尚未監控的主機名稱 (discovered_hosts collection，以 Hostname 唯一)
+type DiscoveredHost struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Hostname string             `bson:"hostname" json:"hostname"`
+	ZoneName string             `bson:"zone_name" json:"zone_name"`
+	Status   string             `bson:"status" json:"status"`
+	Sources  []string           `bson:"sources" json:"sources"`
+
+	// 發現的依據 (e.g. "CT: Let's Encrypt R10 #03A1..."、"SAN: www.example.com")
+	FirstSeenIn string    `bson:"first_seen_in" json:"first_seen_in"`
+	LastSeenIn  string    `bson:"last_seen_in" json:"last_seen_in"`
+	FirstSeenAt time.Time `bson:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt  time.Time `bson:"last_seen_at" json:"last_seen_at"`
+
+	DomainID primitive.ObjectID `bson:"domain_id,omitempty" json:"domain_id,omitempty"` // 接受後建立的監控域名
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// 檔案憑證格式
+const (
+	FileFormatPEM    = "pem"
+	FileFormatDER    = "der"
+	FileFormatPKCS12 = "pkcs12"
+	FileFormatJKS    = "jks"
+)
+
+// FileCertStatusError 檔案無法解析 (格式錯誤、密碼不符等)，詳見 ErrorMsg
+const FileCertStatusError = "parse_error"
+
+// FileCertificate 磁碟上的憑證 (mTLS Client 憑證、內部服務憑證等不對外的憑證)
+// 一個檔案可能包含多張憑證 (憑證鏈、Keystore)，每張一筆，以 Path + Entry 識別
+type FileCertificate struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Path   string             `bson:"path" json:"path"`
+	Entry  string             `bson:"entry" json:"entry"` // PEM/DER 為 "#序號"，JKS/PKCS#12 為 Alias
+	Format string             `bson:"format" json:"format"`
+
+	Subject      string    `bson:"subject" json:"subject"`
+	Issuer       string    `bson:"issuer" json:"issuer"`
+	SANs         []string  `bson:"sans" json:"sans"`
+	SerialNumber string    `bson:"serial_number" json:"serial_number"`
+	Fingerprint  string    `bson:"fingerprint" json:"fingerprint"` // SHA-256
+	IsCA         bool      `bson:"is_ca" json:"is_ca"`
+	NotBefore    time.Time `bson:"not_before" json:"not_before"`
+	NotAfter     time.Time `bson:"not_after" json:"not_after"`
+
+	DaysRemaining int    `bson:"days_remaining" json:"days_remaining"`
+	Status        string `bson:"status" json:"status"`
+	ErrorMsg      string `bson:"error_msg,omitempty" json:"error_msg,omitempty"`
+
+	IsIgnored     bool      `bson:"is_ignored" json:"is_ignored"`
+	LastAlertTime time.Time `bson:"last_alert_time" json:"last_alert_time"`
+	LastScanAt    time.Time `bson:"last_scan_at" json:"last_scan_at"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+}
+
+// DisplayName 告警與日誌使用的名稱
+func (f FileCertificate) DisplayName() string {
+	if f.Entry == "" {
+		return f.Path
+	}
+	return f.Path + " [" + f.Entry + "]"
+}
@@ -13,6 +13,7 @@ type NotificationSettings struct {
 	TelegramChatID   string `bson:"telegram_chat_id" json:"telegram_chat_id"`
 
 	// [新增] Let's Encrypt 設定
+	// 已改用 acme_accounts 管理多個 CA 帳號，這裡只在首次使用時遷移成預設帳號
 	AcmeEmail      string `bson:"acme_email" json:"acme_email"`
 	AcmePrivateKey string `bson:"acme_private_key" json:"acme_private_key"` // 存 PEM 格式
 	AcmeRegData    string `bson:"acme_reg_data" json:"acme_reg_data"`       // 存註冊資訊 JSON
@@ -47,6 +48,18 @@ type NotificationSettings struct {
 
 	NotifyOnZoneDelete         bool   `bson:"notify_on_zone_delete" json:"notify_on_zone_delete"`
 	NotifyOnZoneDeleteTemplate string `bson:"notify_on_zone_delete_template" json:"notify_on_zone_delete_tpl"`
+
+	// 憑證部署結果 (成功/失敗都會通知)
+	NotifyOnDeploy         bool   `bson:"notify_on_deploy" json:"notify_on_deploy"`
+	NotifyOnDeployTemplate string `bson:"notify_on_deploy_tpl" json:"notify_on_deploy_tpl"`
+
+	// CT Log 發現非允許清單 Issuer 簽發的憑證
+	NotifyOnUnexpectedIssuance         bool   `bson:"notify_on_unexpected_issuance" json:"notify_on_unexpected_issuance"`
+	NotifyOnUnexpectedIssuanceTemplate string `bson:"notify_on_unexpected_issuance_tpl" json:"notify_on_unexpected_issuance_tpl"`
+
+	// Zone SSL/TLS 異常 (憑證包卡在驗證中、Flexible 模式下源站憑證無效)
+	NotifyOnZoneSecurity         bool   `bson:"notify_on_zone_security" json:"notify_on_zone_security"`
+	NotifyOnZoneSecurityTemplate string `bson:"notify_on_zone_security_tpl" json:"notify_on_zone_security_tpl"`
 	// --- [新增] E. 排程與匯總通知設定 ---
 
 	// 1. Cloudflare 自動同步
@@ -55,9 +68,26 @@ type NotificationSettings struct {
 	NotifyOnSyncFinish bool   `bson:"notify_on_sync_finish" json:"notify_on_sync_finish"` // 完成後是否通知
 	SyncFinishTemplate string `bson:"sync_finish_tpl" json:"sync_finish_tpl"`             // 完成通知模板
 
+	// Zone 範圍 (填 Zone 名稱或 Zone ID)：Include 為空代表 Token 可見的全部 Zone
+	// 被排除的 Zone 不會被同步，也不會被當成已刪除
+	SyncIncludeZones    []string `bson:"sync_include_zones" json:"sync_include_zones"`
+	SyncExcludeZones    []string `bson:"sync_exclude_zones" json:"sync_exclude_zones"`
+	SyncZoneConcurrency int      `bson:"sync_zone_concurrency" json:"sync_zone_concurrency"` // 同時處理的 Zone 數量 (預設 3)
+
 	// 2. SSL 自動掃描
 	ScanEnabled        bool   `bson:"scan_enabled" json:"scan_enabled"`
 	ScanSchedule       string `bson:"scan_schedule" json:"scan_schedule"`
 	NotifyOnScanFinish bool   `bson:"notify_on_scan_finish" json:"notify_on_scan_finish"`
 	ScanFinishTemplate string `bson:"scan_finish_tpl" json:"scan_finish_tpl"`
+
+	// 3. ACME 自動續簽 (只處理 AutoRenew = true 的域名)
+	RenewEnabled    bool   `bson:"renew_enabled" json:"renew_enabled"`
+	RenewSchedule   string `bson:"renew_schedule" json:"renew_schedule"`
+	RenewBeforeDays int    `bson:"renew_before_days" json:"renew_before_days"` // 剩餘天數低於此值才續簽 (預設 30)
+
+	// 4. Certificate Transparency 監控 (Log 清單在設定檔 ct.logs)
+	CTEnabled  bool   `bson:"ct_enabled" json:"ct_enabled"`
+	CTSchedule string `bson:"ct_schedule" json:"ct_schedule"` // e.g. "*/10 * * * *"
+	// 允許的 Issuer (比對 Issuer DN，不分大小寫的部分字串，e.g. "Let's Encrypt")，其他 Issuer 視為非預期簽發
+	CTAllowedIssuers []string `bson:"ct_allowed_issuers" json:"ct_allowed_issuers"`
 }
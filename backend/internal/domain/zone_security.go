@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cloudflare SSL/TLS 加密模式
+const (
+	ZoneSSLOff      = "off"
+	ZoneSSLFlexible = "flexible" // 瀏覽器 -> Cloudflare 加密，Cloudflare -> 源站為 HTTP
+	ZoneSSLFull     = "full"     // 連到源站使用 HTTPS，但不驗證源站憑證
+	ZoneSSLStrict   = "strict"   // Full (strict)：源站憑證必須有效，否則回應 526
+)
+
+// Edge 憑證包狀態 (僅列出需要判斷的部分)
+const (
+	CertPackActive            = "active"
+	CertPackPendingValidation = "pending_validation"
+)
+
+// ZoneSecurity Zone 層級的 SSL/TLS 設定與 Edge 憑證包 (zone_security collection，以 Zone ID 為 _id)
+// 由同步流程更新，目前只有 Cloudflare 提供
+type ZoneSecurity struct {
+	ZoneID      string             `bson:"_id" json:"zone_id"`
+	ZoneName    string             `bson:"zone_name" json:"zone_name"`
+	Provider    string             `bson:"provider" json:"provider"`
+	CFAccountID primitive.ObjectID `bson:"cf_account_id,omitempty" json:"cf_account_id,omitempty"`
+
+	SSLMode          string            `bson:"ssl_mode" json:"ssl_mode"`               // off / flexible / full / strict
+	MinTLSVersion    string            `bson:"min_tls_version" json:"min_tls_version"` // e.g. "1.2"
+	CertificatePacks []CertificatePack `bson:"certificate_packs" json:"certificate_packs"`
+
+	// 目前的異常 (e.g. 憑證包卡在驗證中)，只有新出現的項目會發送通知
+	Issues []string `bson:"issues" json:"issues"`
+
+	LastError string    `bson:"last_error" json:"last_error"` // 最近一次讀取失敗的原因 (部分欄位可能是舊資料)
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CertificatePack Cloudflare Edge 憑證包 (Universal / Advanced / Custom)
+type CertificatePack struct {
+	ID                   string   `bson:"id" json:"id"`
+	Type                 string   `bson:"type" json:"type"` // universal / advanced ...
+	Hosts                []string `bson:"hosts" json:"hosts"`
+	Status               string   `bson:"status" json:"status"`
+	CertificateAuthority string   `bson:"certificate_authority" json:"certificate_authority"`
+	ValidationMethod     string   `bson:"validation_method" json:"validation_method"`
+	ValidationErrors     []string `bson:"validation_errors" json:"validation_errors"`
+
+	// 包內最早到期的憑證 (尚未簽發時為零值)
+	ExpiresOn     time.Time `bson:"expires_on" json:"expires_on"`
+	DaysRemaining int       `bson:"days_remaining" json:"days_remaining"`
+
+	// 第一次看到 pending_validation 的時間，用來判斷是否卡住 (其他狀態為零值)
+	PendingSince time.Time `bson:"pending_since,omitempty" json:"pending_since,omitempty"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ZoneSyncState 每個 Provider 帳號下 Zone 的增量同步進度 (zone_sync_states collection)
+// 同一個 Zone ID 可能同時出現在多個帳號 (e.g. 兩個 Cloudflare 帳號共用 Zone)，
+// _id 為 "Provider/帳號/Zone ID"；沒有帳號之分的 Provider (Route 53、PowerDNS) 為 "Provider/Zone ID"
+type ZoneSyncState struct {
+	ID       string `bson:"_id" json:"id"`
+	Provider string `bson:"provider" json:"provider"`
+	Account  string `bson:"account,omitempty" json:"account,omitempty"` // Cloudflare 帳號 ID、叢集或 Server 名稱
+	ZoneID   string `bson:"zone_id" json:"zone_id"`
+	ZoneName string `bson:"zone_name" json:"zone_name"`
+
+	// 已同步到的最新紀錄修改時間 (Cloudflare modified_on)，Provider 不提供修改時間時為零值
+	Cursor        time.Time `bson:"cursor" json:"cursor"`
+	LastSyncAt    time.Time `bson:"last_sync_at" json:"last_sync_at"`
+	RecordCount   int       `bson:"record_count" json:"record_count"`     // 納入監控的紀錄數
+	ModifiedCount int       `bson:"modified_count" json:"modified_count"` // 修改時間晚於上次 Cursor 的紀錄數 (首次同步為全部)
+}
@@ -0,0 +1,327 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JKS (Java KeyStore) 格式常數，參考 sun.security.provider.JavaKeyStore
+const (
+	jksMagic          = 0xFEEDFEED
+	jceksMagic        = 0xCECECECE
+	jksVersion        = 2
+	jksPrivateKeyTag  = 1
+	jksTrustedCertTag = 2
+	jksWhitener       = "Mighty Aphrodite"
+)
+
+// Sun 專有的私鑰保護演算法 (KeyProtector)
+var oidJKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+// EncodeJKS 將私鑰與憑證鏈打包成 JKS，私鑰與 Keystore 使用同一組密碼
+// certs[0] 必須是 Leaf，其餘為中繼憑證
+func EncodeJKS(alias string, key crypto.PrivateKey, certs []*x509.Certificate, password string) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("keystore: no certificate to encode")
+	}
+	if alias == "" {
+		alias = "certificate"
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	protected, err := protectJKSKey(pkcs8, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := func(v interface{}) { _ = binary.Write(&buf, binary.BigEndian, v) }
+
+	w(uint32(jksMagic))
+	w(uint32(jksVersion))
+	w(uint32(1)) // entry 數量
+
+	w(uint32(jksPrivateKeyTag))
+	writeJavaUTF(&buf, alias)
+	w(time.Now().UnixMilli())
+	w(uint32(len(protected)))
+	buf.Write(protected)
+
+	w(uint32(len(certs)))
+	for _, cert := range certs {
+		writeJavaUTF(&buf, "X.509")
+		w(uint32(len(cert.Raw)))
+		buf.Write(cert.Raw)
+	}
+
+	// 結尾的完整性雜湊：SHA1(密碼 + "Mighty Aphrodite" + 以上內容)
+	h := sha1.New()
+	h.Write(javaPassword(password))
+	h.Write([]byte(jksWhitener))
+	h.Write(buf.Bytes())
+	buf.Write(h.Sum(nil))
+
+	return buf.Bytes(), nil
+}
+
+// IsJKS 依檔頭判斷是否為 JKS / JCEKS
+func IsJKS(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	magic := binary.BigEndian.Uint32(data)
+	return magic == jksMagic || magic == jceksMagic
+}
+
+// DecodeJKS 解析 JKS / JCEKS，回傳第一個私鑰項目的私鑰與所有憑證
+// 憑證為明文 DER，不需要密碼；password 為空時不驗證完整性、也不解私鑰 (回傳 nil)。
+// 只支援 JKS 的 KeyProtector，JCEKS 的私鑰一律回傳 nil；私鑰項目的憑證鏈以 "alias#2" 起編號
+func DecodeJKS(data []byte, password string) (crypto.PrivateKey, []Entry, error) {
+	if !IsJKS(data) {
+		return nil, nil, errors.New("keystore: not a JKS / JCEKS file")
+	}
+	if password != "" {
+		if len(data) < sha1.Size {
+			return nil, nil, errors.New("keystore: JKS too short")
+		}
+		body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+		h := sha1.New()
+		h.Write(javaPassword(password))
+		h.Write([]byte(jksWhitener))
+		h.Write(body)
+		if !hmac.Equal(h.Sum(nil), digest) {
+			return nil, nil, ErrIncorrectPassword
+		}
+	}
+
+	r := bytes.NewReader(data)
+	var header struct {
+		Magic, Version, Count uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, nil, fmt.Errorf("keystore: invalid JKS: %w", err)
+	}
+	if header.Version != 1 && header.Version != 2 {
+		return nil, nil, fmt.Errorf("keystore: unsupported JKS version %d", header.Version)
+	}
+
+	var key crypto.PrivateKey
+	var entries []Entry
+	readCert := func(alias string) error {
+		if header.Version == 2 {
+			certType, err := readJavaUTF(r)
+			if err != nil {
+				return err
+			}
+			if certType != "X.509" {
+				return fmt.Errorf("keystore: unsupported certificate type %s", certType)
+			}
+		}
+		der, err := readJKSBytes(r)
+		if err != nil {
+			return err
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("keystore: invalid certificate (%s): %w", alias, err)
+		}
+		entries = append(entries, Entry{Alias: alias, Cert: cert})
+		return nil
+	}
+
+	for i := uint32(0); i < header.Count; i++ {
+		var tag uint32
+		if err := binary.Read(r, binary.BigEndian, &tag); err != nil {
+			return nil, nil, fmt.Errorf("keystore: invalid JKS: %w", err)
+		}
+		alias, err := readJavaUTF(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := r.Seek(8, io.SeekCurrent); err != nil { // 建立時間 (ms)
+			return nil, nil, err
+		}
+
+		switch tag {
+		case jksPrivateKeyTag: // 加密私鑰 + 憑證鏈
+			protected, err := readJKSBytes(r)
+			if err != nil {
+				return nil, nil, err
+			}
+			if key == nil && password != "" && binary.BigEndian.Uint32(data) == jksMagic {
+				if key, err = recoverJKSKey(protected, password); err != nil {
+					return nil, nil, err
+				}
+			}
+			var chainLen uint32
+			if err := binary.Read(r, binary.BigEndian, &chainLen); err != nil {
+				return nil, nil, fmt.Errorf("keystore: invalid JKS: %w", err)
+			}
+			for j := uint32(0); j < chainLen; j++ {
+				entry := alias
+				if j > 0 {
+					entry = fmt.Sprintf("%s#%d", alias, j+1)
+				}
+				if err := readCert(entry); err != nil {
+					return nil, nil, err
+				}
+			}
+		case jksTrustedCertTag:
+			if err := readCert(alias); err != nil {
+				return nil, nil, err
+			}
+		default:
+			// JCEKS 的 SecretKeyEntry 是 Java 序列化物件，無法跳過，只回傳目前已解析的憑證
+			if len(entries) > 0 {
+				return key, entries, nil
+			}
+			return nil, nil, fmt.Errorf("keystore: unsupported keystore entry type %d", tag)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil, errors.New("keystore: no certificate in keystore")
+	}
+	return key, entries, nil
+}
+
+// recoverJKSKey protectJKSKey 的反向：解出 PKCS#8 並以結尾的 SHA1 確認密碼
+func recoverJKSKey(der []byte, password string) (crypto.PrivateKey, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("keystore: invalid JKS key entry: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidJKSKeyProtector) {
+		return nil, fmt.Errorf("keystore: unsupported JKS key protection %s", info.Algorithm.Algorithm)
+	}
+	protected := info.EncryptedData
+	if len(protected) < 2*sha1.Size {
+		return nil, errors.New("keystore: invalid JKS key entry")
+	}
+	passwd := javaPassword(password)
+	salt := protected[:sha1.Size]
+	encrypted := protected[sha1.Size : len(protected)-sha1.Size]
+	check := protected[len(protected)-sha1.Size:]
+
+	plain := make([]byte, len(encrypted))
+	copy(plain, encrypted)
+	digest := salt
+	for offset := 0; offset < len(plain); offset += sha1.Size {
+		h := sha1.New()
+		h.Write(passwd)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for i := 0; i < sha1.Size && offset+i < len(plain); i++ {
+			plain[offset+i] ^= digest[i]
+		}
+	}
+
+	h := sha1.New()
+	h.Write(passwd)
+	h.Write(plain)
+	if !hmac.Equal(h.Sum(nil), check) {
+		return nil, ErrIncorrectPassword
+	}
+	key, err := x509.ParsePKCS8PrivateKey(plain)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid private key: %w", err)
+	}
+	return key, nil
+}
+
+// protectJKSKey 以 KeyProtector 演算法加密 PKCS#8 私鑰，回傳 EncryptedPrivateKeyInfo
+// 格式：salt(20) + (明文 XOR 金鑰流) + SHA1(密碼 + 明文)
+func protectJKSKey(plain []byte, password string) ([]byte, error) {
+	passwd := javaPassword(password)
+
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]byte, len(plain))
+	copy(encrypted, plain)
+	digest := salt
+	for offset := 0; offset < len(plain); offset += sha1.Size {
+		h := sha1.New()
+		h.Write(passwd)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for i := 0; i < sha1.Size && offset+i < len(plain); i++ {
+			encrypted[offset+i] ^= digest[i]
+		}
+	}
+
+	check := sha1.New()
+	check.Write(passwd)
+	check.Write(plain)
+
+	protected := append(append(salt, encrypted...), check.Sum(nil)...)
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     algorithmIdentifier{Algorithm: oidJKSKeyProtector, Parameters: asn1.NullRawValue},
+		EncryptedData: protected,
+	})
+}
+
+// javaPassword Java 的 char[] 轉 byte (每個字元 2 bytes, Big Endian)
+func javaPassword(password string) []byte {
+	return bmpString(password)
+}
+
+// writeJavaUTF 對應 DataOutputStream.writeUTF (此處只處理 BMP 內的字元)
+func writeJavaUTF(buf *bytes.Buffer, s string) {
+	var encoded []byte
+	for _, r := range s {
+		switch {
+		case r >= 0x01 && r <= 0x7F:
+			encoded = append(encoded, byte(r))
+		case r <= 0x7FF:
+			encoded = append(encoded, byte(0xC0|(r>>6)), byte(0x80|(r&0x3F)))
+		default:
+			encoded = append(encoded, byte(0xE0|(r>>12)), byte(0x80|((r>>6)&0x3F)), byte(0x80|(r&0x3F)))
+		}
+	}
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(encoded)))
+	buf.Write(encoded)
+}
+
+// readJavaUTF 對應 DataInputStream.readUTF (2 bytes 長度 + 內容)
+func readJavaUTF(r *bytes.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", fmt.Errorf("keystore: invalid JKS: %w", err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("keystore: invalid JKS: %w", err)
+	}
+	return string(buf), nil
+}
+
+// readJKSBytes 4 bytes 長度 + 內容 (長度超過剩餘資料視為格式錯誤，避免配置過大的記憶體)
+func readJKSBytes(r *bytes.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, fmt.Errorf("keystore: invalid JKS: %w", err)
+	}
+	if int64(n) > int64(r.Len()) {
+		return nil, errors.New("keystore: invalid JKS: length exceeds file size")
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("keystore: invalid JKS: %w", err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,171 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testChain 產生 CA 簽發的 Leaf，回傳 Leaf 私鑰與 [Leaf, CA]
+func testChain(t *testing.T, leafKey crypto.Signer) []*x509.Certificate {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Keystore Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, ca, leafKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*x509.Certificate{leaf, ca}
+}
+
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"ecdsa": ecKey, "rsa": rsaKey}
+}
+
+// checkRoundTrip 解出的私鑰與憑證鏈必須與打包前相同 (順序不拘)
+func checkRoundTrip(t *testing.T, want crypto.Signer, chain []*x509.Certificate, gotKey crypto.PrivateKey, entries []Entry) {
+	t.Helper()
+	signer, ok := gotKey.(crypto.Signer)
+	if !ok {
+		t.Fatalf("key type = %T", gotKey)
+	}
+	if !signer.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(want.Public()) {
+		t.Error("decoded private key differs from the encoded one")
+	}
+	if len(entries) != len(chain) {
+		t.Fatalf("got %d certificates, want %d", len(entries), len(chain))
+	}
+	for _, c := range chain {
+		found := false
+		for _, e := range entries {
+			if e.Cert.Equal(c) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("certificate %q missing after round trip", c.Subject.CommonName)
+		}
+	}
+}
+
+func TestPKCS12RoundTrip(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			chain := testChain(t, key)
+			data, err := EncodePKCS12(key, chain, "www", "p@ss wörd")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotKey, entries, err := DecodePKCS12(data, "p@ss wörd")
+			if err != nil {
+				t.Fatalf("DecodePKCS12: %v", err)
+			}
+			checkRoundTrip(t, key, chain, gotKey, entries)
+			for _, e := range entries {
+				if e.Cert.Equal(chain[0]) && e.Alias != "www" {
+					t.Errorf("leaf alias = %q, want %q", e.Alias, "www")
+				}
+			}
+
+			if _, _, err := DecodePKCS12(data, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+				t.Errorf("wrong password: err = %v, want ErrIncorrectPassword", err)
+			}
+		})
+	}
+}
+
+func TestJKSRoundTrip(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			chain := testChain(t, key)
+			data, err := EncodeJKS("www", key, chain, "changeit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsJKS(data) {
+				t.Fatal("IsJKS = false for EncodeJKS output")
+			}
+
+			gotKey, entries, err := DecodeJKS(data, "changeit")
+			if err != nil {
+				t.Fatalf("DecodeJKS: %v", err)
+			}
+			checkRoundTrip(t, key, chain, gotKey, entries)
+			if entries[0].Alias != "www" || entries[1].Alias != "www#2" {
+				t.Errorf("aliases = %q, %q", entries[0].Alias, entries[1].Alias)
+			}
+
+			// 不給密碼時只讀取憑證
+			noKey, certsOnly, err := DecodeJKS(data, "")
+			if err != nil || noKey != nil || len(certsOnly) != len(chain) {
+				t.Errorf("without password: key=%v certs=%d err=%v", noKey, len(certsOnly), err)
+			}
+
+			if _, _, err := DecodeJKS(data, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+				t.Errorf("wrong password: err = %v, want ErrIncorrectPassword", err)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsEmptyChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EncodePKCS12(key, nil, "a", "p"); err == nil {
+		t.Error("EncodePKCS12 accepted an empty chain")
+	}
+	if _, err := EncodeJKS("a", key, nil, "p"); err == nil {
+		t.Error("EncodeJKS accepted an empty chain")
+	}
+}
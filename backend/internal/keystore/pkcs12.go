@@ -0,0 +1,695 @@
+// Package keystore 負責憑證打包格式 (PKCS#12 / JKS) 的編碼與解析
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// 產生 PKCS#12 的參數 (與 OpenSSL 3 預設值相同：PBES2 + AES-256-CBC，MAC 使用 SHA-256)
+const (
+	pkcs12Iterations = 2048
+	pkcs12SaltLen    = 16
+)
+
+var (
+	oidDataContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertTypeX509        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBES2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSHA1        = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHmacWithSHA384      = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHmacWithSHA512      = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC          = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidSHA1                = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	// PKCS#12 傳統 PBE (pbeWithSHAAnd3-KeyTripleDES-CBC、pbeWithSHAAnd40BitRC2-CBC ...) 的共同前綴
+	oidPKCS12PBE = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1}
+)
+
+// ErrIncorrectPassword 密碼錯誤 (MAC 或私鑰完整性驗證失敗)
+var ErrIncorrectPassword = errors.New("keystore: incorrect password")
+
+// errLegacyPBE 遇到傳統 PBE 演算法，改由 x/crypto/pkcs12 解析整個檔案
+var errLegacyPBE = errors.New("keystore: legacy PKCS#12 PBE algorithm")
+
+// Entry 從 Keystore 解析出的一張憑證
+type Entry struct {
+	Alias string // PKCS#12 的 friendlyName / JKS 的 alias (沒有時為空字串)
+	Cert  *x509.Certificate
+}
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type digestInfo struct {
+	Algorithm algorithmIdentifier
+	Digest    []byte
+}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     algorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	Kdf              algorithmIdentifier
+	EncryptionScheme algorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	Prf        algorithmIdentifier
+}
+
+// 解析用的結構：MacData、KeyLength、Prf 在標準中都是選填
+type pfxPduDecode struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macDataDecode `asn1:"optional"`
+}
+
+type macDataDecode struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type pbkdf2ParamsDecode struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                 `asn1:"optional"`
+	Prf        algorithmIdentifier `asn1:"optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm algorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+// EncodePKCS12 將私鑰與憑證鏈打包成 PKCS#12 (.p12/.pfx)
+// certs[0] 必須是 Leaf，其餘為中繼憑證
+func EncodePKCS12(key crypto.PrivateKey, certs []*x509.Certificate, alias, password string) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("keystore: no certificate to encode")
+	}
+
+	// Leaf 與私鑰用相同的 localKeyId 綁定 (慣例使用 Leaf 的 SHA-1 指紋)
+	keyID := sha1.Sum(certs[0].Raw)
+	leafAttrs, err := bagAttributes(alias, keyID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 憑證區 (不加密，與多數工具相同)
+	var certBags []safeBag
+	for i, cert := range certs {
+		bag, err := asn1.Marshal(certBag{ID: oidCertTypeX509, Data: cert.Raw})
+		if err != nil {
+			return nil, err
+		}
+		sb := safeBag{ID: oidCertBag, Value: explicitValue(bag)}
+		if i == 0 {
+			sb.Attributes = leafAttrs
+		}
+		certBags = append(certBags, sb)
+	}
+
+	// 2. 私鑰區 (PKCS#8 + PBES2 加密)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	shrouded, err := encryptPBES2(pkcs8, password)
+	if err != nil {
+		return nil, err
+	}
+	keyBags := []safeBag{{ID: oidPKCS8ShroudedKeyBag, Value: explicitValue(shrouded), Attributes: leafAttrs}}
+
+	var authSafe []contentInfo
+	for _, bags := range [][]safeBag{certBags, keyBags} {
+		ci, err := dataContentInfo(bags)
+		if err != nil {
+			return nil, err
+		}
+		authSafe = append(authSafe, ci)
+	}
+
+	authSafeBytes, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 整體完整性 MAC (PKCS#12 KDF + HMAC-SHA256)
+	macSalt := make([]byte, pkcs12SaltLen)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, err
+	}
+	macKey := pkcs12KDF(sha256.New, bmpPassword(password), macSalt, 3, pkcs12Iterations, sha256.Size)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(authSafeBytes)
+
+	authSafeOctets, err := asn1.Marshal(authSafeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pfxPdu{
+		Version: 3,
+		AuthSafe: contentInfo{
+			ContentType: oidDataContentType,
+			Content:     explicitValue(authSafeOctets),
+		},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// DecodePKCS12 解析 PKCS#12，回傳私鑰 (沒有時為 nil) 與所有憑證
+// 支援 PBES2 (PBKDF2 + AES-CBC / 3DES，OpenSSL 3 與 EncodePKCS12 的預設) 與傳統 PBE (3DES / RC2)；
+// 傳統 PBE 交給 x/crypto/pkcs12 處理，因此同一個檔案混用 PBES2 與 RC2 時無法解析
+func DecodePKCS12(data []byte, password string) (crypto.PrivateKey, []Entry, error) {
+	key, entries, err := decodePKCS12(data, password)
+	if errors.Is(err, errLegacyPBE) {
+		return decodeLegacyPKCS12(data, password)
+	}
+	return key, entries, err
+}
+
+func decodePKCS12(data []byte, password string) (crypto.PrivateKey, []Entry, error) {
+	var pfx pfxPduDecode
+	if rest, err := asn1.Unmarshal(data, &pfx); err != nil {
+		return nil, nil, fmt.Errorf("keystore: invalid PKCS#12: %w", err)
+	} else if len(rest) != 0 {
+		return nil, nil, errors.New("keystore: trailing data after PKCS#12")
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, nil, errors.New("keystore: only password-protected PKCS#12 is supported")
+	}
+	var authSafeBytes []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeBytes); err != nil {
+		return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 authSafe: %w", err)
+	}
+
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) > 0 {
+		if err := verifyPKCS12MAC(pfx.MacData, authSafeBytes, password); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var authSafe []contentInfo
+	if _, err := asn1.Unmarshal(authSafeBytes, &authSafe); err != nil {
+		return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 authSafe: %w", err)
+	}
+
+	var key crypto.PrivateKey
+	var entries []Entry
+	for _, ci := range authSafe {
+		var bagsDER []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContentType):
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &bagsDER); err != nil {
+				return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 data: %w", err)
+			}
+		case ci.ContentType.Equal(oidEncryptedDataType):
+			var ed encryptedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 encryptedData: %w", err)
+			}
+			plain, err := decryptPBE(ed.EncryptedContentInfo.ContentEncryptionAlgorithm, ed.EncryptedContentInfo.EncryptedContent, password)
+			if err != nil {
+				return nil, nil, err
+			}
+			bagsDER = plain
+		default:
+			return nil, nil, fmt.Errorf("keystore: unsupported PKCS#12 content type %s", ci.ContentType)
+		}
+
+		var bags []safeBag
+		if _, err := asn1.Unmarshal(bagsDER, &bags); err != nil {
+			return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 bags: %w", err)
+		}
+		for _, bag := range bags {
+			switch {
+			case bag.ID.Equal(oidCertBag):
+				var cb certBag
+				if _, err := asn1.Unmarshal(bag.Value.Bytes, &cb); err != nil {
+					return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 certBag: %w", err)
+				}
+				if !cb.ID.Equal(oidCertTypeX509) {
+					continue
+				}
+				cert, err := x509.ParseCertificate(cb.Data)
+				if err != nil {
+					return nil, nil, fmt.Errorf("keystore: invalid certificate: %w", err)
+				}
+				entries = append(entries, Entry{Alias: friendlyName(bag.Attributes), Cert: cert})
+			case bag.ID.Equal(oidPKCS8ShroudedKeyBag), bag.ID.Equal(oidKeyBag):
+				if key != nil {
+					continue // 只取第一把私鑰
+				}
+				pkcs8 := bag.Value.Bytes
+				if bag.ID.Equal(oidPKCS8ShroudedKeyBag) {
+					var info encryptedPrivateKeyInfo
+					if _, err := asn1.Unmarshal(bag.Value.Bytes, &info); err != nil {
+						return nil, nil, fmt.Errorf("keystore: invalid PKCS#12 key bag: %w", err)
+					}
+					plain, err := decryptPBE(info.Algorithm, info.EncryptedData, password)
+					if err != nil {
+						return nil, nil, err
+					}
+					pkcs8 = plain
+				}
+				parsed, err := x509.ParsePKCS8PrivateKey(pkcs8)
+				if err != nil {
+					return nil, nil, fmt.Errorf("keystore: invalid private key: %w", err)
+				}
+				key = parsed
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil, errors.New("keystore: no certificate in PKCS#12")
+	}
+	return key, entries, nil
+}
+
+// verifyPKCS12MAC 驗證整體 MAC (RFC 7292 附錄 B 的 KDF + HMAC)
+// 空密碼依實作不同可能編碼成兩個 0 或完全空白，兩種都接受
+func verifyPKCS12MAC(md macDataDecode, content []byte, password string) error {
+	newHash, err := digestHash(md.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	candidates := [][]byte{bmpPassword(password)}
+	if password == "" {
+		candidates = append(candidates, nil)
+	}
+	for _, pw := range candidates {
+		macKey := pkcs12KDF(newHash, pw, md.MacSalt, 3, md.Iterations, newHash().Size())
+		mac := hmac.New(newHash, macKey)
+		mac.Write(content)
+		if hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+			return nil
+		}
+	}
+	return ErrIncorrectPassword
+}
+
+// decryptPBE 解密 PBES2 (PBKDF2 + AES-CBC / 3DES-CBC) 加密的內容；傳統 PBE 回傳 errLegacyPBE
+func decryptPBE(alg algorithmIdentifier, encrypted []byte, password string) ([]byte, error) {
+	if hasPrefix(alg.Algorithm, oidPKCS12PBE) {
+		return nil, errLegacyPBE
+	}
+	if !alg.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("keystore: unsupported encryption algorithm %s", alg.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("keystore: invalid PBES2 parameters: %w", err)
+	}
+	if !params.Kdf.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("keystore: unsupported PBES2 key derivation %s", params.Kdf.Algorithm)
+	}
+	var kdf pbkdf2ParamsDecode
+	if _, err := asn1.Unmarshal(params.Kdf.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("keystore: invalid PBKDF2 parameters: %w", err)
+	}
+	prf, err := hmacHash(kdf.Prf.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyLen int
+	var newCipher func([]byte) (cipher.Block, error)
+	scheme := params.EncryptionScheme.Algorithm
+	switch {
+	case scheme.Equal(oidAES128CBC):
+		keyLen, newCipher = 16, aes.NewCipher
+	case scheme.Equal(oidAES192CBC):
+		keyLen, newCipher = 24, aes.NewCipher
+	case scheme.Equal(oidAES256CBC):
+		keyLen, newCipher = 32, aes.NewCipher
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLen, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, fmt.Errorf("keystore: unsupported PBES2 cipher %s", scheme)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("keystore: invalid PBES2 IV: %w", err)
+	}
+
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.Iterations, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, errors.New("keystore: invalid PBES2 ciphertext")
+	}
+	plain := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, encrypted)
+
+	// PKCS#7 padding 不正確通常代表密碼錯誤 (沒有 MAC 時唯一的檢查)
+	padLen := int(plain[len(plain)-1])
+	if padLen == 0 || padLen > block.BlockSize() || !bytes.Equal(plain[len(plain)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, ErrIncorrectPassword
+	}
+	return plain[:len(plain)-padLen], nil
+}
+
+// decodeLegacyPKCS12 以 x/crypto/pkcs12 解析只使用傳統 PBE 的檔案
+func decodeLegacyPKCS12(data []byte, password string) (crypto.PrivateKey, []Entry, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return nil, nil, ErrIncorrectPassword
+		}
+		return nil, nil, fmt.Errorf("keystore: %w", err)
+	}
+
+	var key crypto.PrivateKey
+	var entries []Entry
+	for _, block := range blocks {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("keystore: invalid certificate: %w", err)
+			}
+			entries = append(entries, Entry{Alias: block.Headers["friendlyName"], Cert: cert})
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			if key == nil {
+				if key, err = parsePEMKey(block); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil, errors.New("keystore: no certificate in PKCS#12")
+	}
+	return key, entries, nil
+}
+
+func parsePEMKey(block *pem.Block) (crypto.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("keystore: invalid private key")
+}
+
+// friendlyName 取出 bag 的 friendlyName 屬性 (BMPString)
+func friendlyName(attrs []pkcs12Attribute) string {
+	for _, attr := range attrs {
+		if !attr.ID.Equal(oidFriendlyName) {
+			continue
+		}
+		var name asn1.RawValue
+		if _, err := asn1.Unmarshal(attr.Value.Bytes, &name); err != nil || name.Tag != asn1.TagBMPString || len(name.Bytes)%2 != 0 {
+			return ""
+		}
+		units := make([]uint16, len(name.Bytes)/2)
+		for i := range units {
+			units[i] = uint16(name.Bytes[2*i])<<8 | uint16(name.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(units))
+	}
+	return ""
+}
+
+// digestHash MAC 使用的雜湊演算法
+func digestHash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("keystore: unsupported MAC digest %s", oid)
+}
+
+// hmacHash PBKDF2 的 PRF (未指定時預設為 hmacWithSHA1)
+func hmacHash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHmacWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHmacWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHmacWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHmacWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("keystore: unsupported PBKDF2 PRF %s", oid)
+}
+
+func hasPrefix(oid, prefix asn1.ObjectIdentifier) bool {
+	return len(oid) > len(prefix) && oid[:len(prefix)].Equal(prefix)
+}
+
+func dataContentInfo(bags []safeBag) (contentInfo, error) {
+	contents, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	octets, err := asn1.Marshal(contents)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	return contentInfo{ContentType: oidDataContentType, Content: explicitValue(octets)}, nil
+}
+
+func bagAttributes(alias string, keyID []byte) ([]pkcs12Attribute, error) {
+	idValue, err := asn1.Marshal(keyID)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []pkcs12Attribute{{ID: oidLocalKeyID, Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: idValue}}}
+
+	if alias != "" {
+		name := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagBMPString, Bytes: bmpString(alias)}
+		nameValue, err := asn1.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, pkcs12Attribute{ID: oidFriendlyName, Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: nameValue}})
+	}
+	return attrs, nil
+}
+
+// encryptPBES2 以 PBKDF2-HMAC-SHA256 + AES-256-CBC 產生 EncryptedPrivateKeyInfo
+func encryptPBES2(plain []byte, password string) ([]byte, error) {
+	salt := make([]byte, pkcs12SaltLen)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, pkcs12Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	padLen := aes.BlockSize - len(plain)%aes.BlockSize
+	padded := make([]byte, len(plain)+padLen)
+	copy(padded, plain)
+	for i := len(plain); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: pkcs12Iterations,
+		Prf:        algorithmIdentifier{Algorithm: oidHmacWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		Kdf:              algorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme: algorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     algorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// pkcs12KDF 實作 RFC 7292 附錄 B.2 的金鑰衍生
+func pkcs12KDF(newHash func() hash.Hash, password, salt []byte, id byte, iterations, size int) []byte {
+	v := newHash().BlockSize()
+
+	fill := func(src []byte) []byte {
+		if len(src) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(src)+v-1)/v))
+		for i := range out {
+			out[i] = src[i%len(src)]
+		}
+		return out
+	}
+
+	D := make([]byte, v)
+	for i := range D {
+		D[i] = id
+	}
+	I := append(fill(salt), fill(password)...)
+
+	var out []byte
+	for len(out) < size {
+		h := newHash()
+		h.Write(D)
+		h.Write(I)
+		A := h.Sum(nil)
+		for r := 1; r < iterations; r++ {
+			h.Reset()
+			h.Write(A)
+			A = h.Sum(nil)
+		}
+		out = append(out, A...)
+
+		// I_j = (I_j + B + 1) mod 2^(v*8)
+		B := fill(A)[:v]
+		for j := 0; j < len(I); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(I[j+k]) + int(B[k]) + carry
+				I[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return out[:size]
+}
+
+// explicitValue 包成 [0] EXPLICIT
+func explicitValue(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// bmpString 轉成 UTF-16BE (不含結尾)
+func bmpString(s string) []byte {
+	var out []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return out
+}
+
+// bmpPassword PKCS#12 KDF 使用的密碼格式：UTF-16BE 並以兩個 0 結尾
+func bmpPassword(password string) []byte {
+	return append(bmpString(password), 0, 0)
+}
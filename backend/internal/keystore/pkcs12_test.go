@@ -0,0 +1,72 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"os"
+	"testing"
+)
+
+// testdata 由 OpenSSL 3.0 產生 (密碼 changeit，Leaf 為 app.example.com，由 Test CA 簽發，alias 為 app)：
+//
+//	openssl pkcs12 -export -inkey leaf.key -in leaf.crt -certfile ca.crt -name app -out openssl3-aes256.p12
+//	openssl pkcs12 -export -legacy -inkey leaf.key -in leaf.crt -certfile ca.crt -name app -out legacy-rc2.p12
+//	openssl pkcs12 -export -keypbe PBE-SHA1-3DES -certpbe PBE-SHA1-3DES -macalg sha1 \
+//	    -inkey leaf.key -in leaf.crt -certfile ca.crt -name app -out legacy-3des.p12
+func TestDecodePKCS12OpenSSLFiles(t *testing.T) {
+	for _, name := range []string{"openssl3-aes256.p12", "legacy-rc2.p12", "legacy-3des.p12"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, entries, err := DecodePKCS12(data, "changeit")
+			if err != nil {
+				t.Fatalf("DecodePKCS12: %v", err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("got %d certificates, want 2", len(entries))
+			}
+
+			var leaf Entry
+			for _, e := range entries {
+				if e.Cert.Subject.CommonName == "app.example.com" {
+					leaf = e
+				}
+			}
+			if leaf.Cert == nil {
+				t.Fatal("leaf certificate not found")
+			}
+			if leaf.Alias != "app" {
+				t.Errorf("leaf alias = %q, want %q", leaf.Alias, "app")
+			}
+
+			ecKey, ok := key.(*ecdsa.PrivateKey)
+			if !ok {
+				t.Fatalf("key type = %T, want *ecdsa.PrivateKey", key)
+			}
+			if !ecKey.PublicKey.Equal(leaf.Cert.PublicKey) {
+				t.Error("private key does not match the leaf certificate")
+			}
+		})
+	}
+}
+
+func TestDecodePKCS12WrongPassword(t *testing.T) {
+	for _, name := range []string{"openssl3-aes256.p12", "legacy-rc2.p12"} {
+		data, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := DecodePKCS12(data, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("%s: err = %v, want ErrIncorrectPassword", name, err)
+		}
+	}
+}
+
+func TestDecodePKCS12Garbage(t *testing.T) {
+	if _, _, err := DecodePKCS12([]byte("not a keystore"), "changeit"); err == nil || errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("err = %v, want a format error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AcmeAccountRepository 管理 ACME 帳號 (acme_accounts collection)
+type AcmeAccountRepository interface {
+	Create(ctx context.Context, account *domain.AcmeAccount) error
+	// 更新帳號設定 (不含私鑰；註冊資訊會一併覆寫，CA 變更時應清空)
+	Update(ctx context.Context, account domain.AcmeAccount) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.AcmeAccount, error)
+	GetDefault(ctx context.Context) (*domain.AcmeAccount, error)
+	List(ctx context.Context) ([]domain.AcmeAccount, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// 將指定帳號設為預設 (其他帳號取消預設)
+	SetDefault(ctx context.Context, id primitive.ObjectID) error
+	// 保存私鑰與註冊資訊 (空字串代表不更新)
+	UpdateKeyData(ctx context.Context, id primitive.ObjectID, privateKey, regData string) error
+	// 更換私鑰並清除註冊資訊 (舊的註冊綁定舊私鑰，不能再沿用)
+	ReplaceKey(ctx context.Context, id primitive.ObjectID, privateKey string) error
+}
+
+type mongoAcmeAccountRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAcmeAccountRepo(db *mongo.Database) AcmeAccountRepository {
+	return &mongoAcmeAccountRepo{
+		collection: db.Collection("acme_accounts"),
+	}
+}
+
+func (r *mongoAcmeAccountRepo) Create(ctx context.Context, account *domain.AcmeAccount) error {
+	if account.ID.IsZero() {
+		account.ID = primitive.NewObjectID()
+	}
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, account)
+	return err
+}
+
+func (r *mongoAcmeAccountRepo) Update(ctx context.Context, account domain.AcmeAccount) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":          account.Name,
+			"email":         account.Email,
+			"directory_url": account.DirectoryURL,
+			"eab_key_id":    account.EABKeyID,
+			"eab_hmac_key":  account.EABHMACKey,
+			"root_ca_pem":   account.RootCAPEM,
+			"reg_data":      account.RegData,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": account.ID}, update)
+	return err
+}
+
+func (r *mongoAcmeAccountRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.AcmeAccount, error) {
+	var account domain.AcmeAccount
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *mongoAcmeAccountRepo) GetDefault(ctx context.Context) (*domain.AcmeAccount, error) {
+	var account domain.AcmeAccount
+	if err := r.collection.FindOne(ctx, bson.M{"is_default": true}).Decode(&account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *mongoAcmeAccountRepo) List(ctx context.Context) ([]domain.AcmeAccount, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.AcmeAccount
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoAcmeAccountRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoAcmeAccountRepo) SetDefault(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$ne": id}}, bson.M{"$set": bson.M{"is_default": false}}); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"is_default": true}})
+	return err
+}
+
+func (r *mongoAcmeAccountRepo) UpdateKeyData(ctx context.Context, id primitive.ObjectID, privateKey, regData string) error {
+	updateFields := bson.M{}
+	if privateKey != "" {
+		updateFields["private_key"] = privateKey
+	}
+	if regData != "" {
+		updateFields["reg_data"] = regData
+	}
+	if len(updateFields) == 0 {
+		return nil
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updateFields})
+	return err
+}
+
+func (r *mongoAcmeAccountRepo) ReplaceKey(ctx context.Context, id primitive.ObjectID, privateKey string) error {
+	update := bson.M{
+		"$set":   bson.M{"private_key": privateKey},
+		"$unset": bson.M{"reg_data": ""},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
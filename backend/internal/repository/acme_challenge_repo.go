@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AcmeChallengeRepository 保存 HTTP-01 驗證中的 Token (acme_challenges collection)
+type AcmeChallengeRepository interface {
+	Save(ctx context.Context, challenge domain.AcmeChallenge) error
+	GetByToken(ctx context.Context, token string) (*domain.AcmeChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type mongoAcmeChallengeRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAcmeChallengeRepo(db *mongo.Database) AcmeChallengeRepository {
+	coll := db.Collection("acme_challenges")
+
+	// 驗證通常幾分鐘內結束，CleanUp 沒執行到 (例如程式中斷) 的 Token 一小時後自動清除
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(time.Hour.Seconds())),
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ 建立 acme_challenges TTL 索引失敗: %v", err)
+	}
+
+	return &mongoAcmeChallengeRepo{collection: coll}
+}
+
+func (r *mongoAcmeChallengeRepo) Save(ctx context.Context, challenge domain.AcmeChallenge) error {
+	if challenge.CreatedAt.IsZero() {
+		challenge.CreatedAt = time.Now()
+	}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": challenge.Token}, challenge, opts)
+	return err
+}
+
+func (r *mongoAcmeChallengeRepo) GetByToken(ctx context.Context, token string) (*domain.AcmeChallenge, error) {
+	var challenge domain.AcmeChallenge
+	if err := r.collection.FindOne(ctx, bson.M{"_id": token}).Decode(&challenge); err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *mongoAcmeChallengeRepo) Delete(ctx context.Context, token string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": token})
+	return err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CertificateRepository 管理系統保管的憑證 (certificates collection)
+type CertificateRepository interface {
+	Create(ctx context.Context, cert *domain.ManagedCertificate) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ManagedCertificate, error)
+	// 取得某個域名最新的一張憑證
+	GetLatestByDomainID(ctx context.Context, domainID primitive.ObjectID) (*domain.ManagedCertificate, error)
+	ListByDomainID(ctx context.Context, domainID primitive.ObjectID) ([]domain.ManagedCertificate, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	UpdateRenewalInfo(ctx context.Context, id primitive.ObjectID, info domain.RenewalInfo) error
+	MarkRevoked(ctx context.Context, id primitive.ObjectID, reason uint) error
+}
+
+type mongoCertificateRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCertificateRepo(db *mongo.Database) CertificateRepository {
+	return &mongoCertificateRepo{
+		collection: db.Collection("certificates"),
+	}
+}
+
+func (r *mongoCertificateRepo) Create(ctx context.Context, cert *domain.ManagedCertificate) error {
+	if cert.ID.IsZero() {
+		cert.ID = primitive.NewObjectID()
+	}
+	if cert.CreatedAt.IsZero() {
+		cert.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, cert)
+	return err
+}
+
+func (r *mongoCertificateRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.ManagedCertificate, error) {
+	var cert domain.ManagedCertificate
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (r *mongoCertificateRepo) GetLatestByDomainID(ctx context.Context, domainID primitive.ObjectID) (*domain.ManagedCertificate, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	var cert domain.ManagedCertificate
+	if err := r.collection.FindOne(ctx, bson.M{"domain_id": domainID}, opts).Decode(&cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (r *mongoCertificateRepo) ListByDomainID(ctx context.Context, domainID primitive.ObjectID) ([]domain.ManagedCertificate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"domain_id": domainID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.ManagedCertificate
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoCertificateRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoCertificateRepo) UpdateRenewalInfo(ctx context.Context, id primitive.ObjectID, info domain.RenewalInfo) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"renewal_info": info}})
+	return err
+}
+
+func (r *mongoCertificateRepo) MarkRevoked(ctx context.Context, id primitive.ObjectID, reason uint) error {
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now(), "revocation_reason": reason}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CloudflareAccountRepository 管理 Cloudflare 帳號 (cloudflare_accounts collection)
+type CloudflareAccountRepository interface {
+	Create(ctx context.Context, account *domain.CloudflareAccount) error
+	// 更新名稱與加密後的 Token (EncryptedToken 為空字串代表不更新；更新時一併移除舊版明文 Token)
+	Update(ctx context.Context, account domain.CloudflareAccount) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CloudflareAccount, error)
+	List(ctx context.Context) ([]domain.CloudflareAccount, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// 記錄同步結果 (syncErr 為 nil 代表成功)
+	UpdateSyncResult(ctx context.Context, id primitive.ObjectID, count int, syncErr error) error
+}
+
+type mongoCloudflareAccountRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoCloudflareAccountRepo(db *mongo.Database) CloudflareAccountRepository {
+	return &mongoCloudflareAccountRepo{
+		collection: db.Collection("cloudflare_accounts"),
+	}
+}
+
+func (r *mongoCloudflareAccountRepo) Create(ctx context.Context, account *domain.CloudflareAccount) error {
+	if account.ID.IsZero() {
+		account.ID = primitive.NewObjectID()
+	}
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, account)
+	return err
+}
+
+func (r *mongoCloudflareAccountRepo) Update(ctx context.Context, account domain.CloudflareAccount) error {
+	update := bson.M{}
+	updateFields := bson.M{"name": account.Name}
+	if account.EncryptedToken != "" {
+		updateFields["encrypted_token"] = account.EncryptedToken
+		updateFields["token_hint"] = account.TokenHint
+		update["$unset"] = bson.M{"api_token": ""}
+	}
+	update["$set"] = updateFields
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": account.ID}, update)
+	return err
+}
+
+func (r *mongoCloudflareAccountRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CloudflareAccount, error) {
+	var account domain.CloudflareAccount
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *mongoCloudflareAccountRepo) List(ctx context.Context) ([]domain.CloudflareAccount, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.CloudflareAccount
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoCloudflareAccountRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoCloudflareAccountRepo) UpdateSyncResult(ctx context.Context, id primitive.ObjectID, count int, syncErr error) error {
+	status, errMsg := domain.CFSyncStatusSuccess, ""
+	if syncErr != nil {
+		status, errMsg = domain.CFSyncStatusFailed, syncErr.Error()
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"last_sync_at":     time.Now(),
+			"last_sync_status": status,
+			"last_sync_error":  errMsg,
+			"last_sync_count":  count,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CTRepository 保存 CT Log 讀取進度 (ct_log_states) 與發現的憑證 (ct_issuances)
+type CTRepository interface {
+	GetLogState(ctx context.Context, url string) (*domain.CTLogState, error) // 尚未讀取過回傳 nil
+	SaveLogState(ctx context.Context, state domain.CTLogState) error
+	ListLogStates(ctx context.Context) ([]domain.CTLogState, error)
+
+	// InsertIssuance 相同 Issuer + Serial 已存在時回傳 false (不重複通知)
+	InsertIssuance(ctx context.Context, issuance domain.CTIssuance) (bool, error)
+	ListIssuances(ctx context.Context, limit int64) ([]domain.CTIssuance, error)
+}
+
+type mongoCTRepo struct {
+	states    *mongo.Collection
+	issuances *mongo.Collection
+}
+
+func NewMongoCTRepo(db *mongo.Database) CTRepository {
+	issuances := db.Collection("ct_issuances")
+
+	_, err := issuances.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "issuer", Value: 1}, {Key: "serial_number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "seen_at", Value: -1}}},
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ 建立 ct_issuances 索引失敗: %v", err)
+	}
+
+	return &mongoCTRepo{states: db.Collection("ct_log_states"), issuances: issuances}
+}
+
+func (r *mongoCTRepo) GetLogState(ctx context.Context, url string) (*domain.CTLogState, error) {
+	var state domain.CTLogState
+	err := r.states.FindOne(ctx, bson.M{"_id": url}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *mongoCTRepo) SaveLogState(ctx context.Context, state domain.CTLogState) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.states.ReplaceOne(ctx, bson.M{"_id": state.URL}, state, opts)
+	return err
+}
+
+func (r *mongoCTRepo) ListLogStates(ctx context.Context) ([]domain.CTLogState, error) {
+	cursor, err := r.states.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.CTLogState
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoCTRepo) InsertIssuance(ctx context.Context, issuance domain.CTIssuance) (bool, error) {
+	if issuance.SeenAt.IsZero() {
+		issuance.SeenAt = time.Now()
+	}
+	if _, err := r.issuances.InsertOne(ctx, issuance); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *mongoCTRepo) ListIssuances(ctx context.Context, limit int64) ([]domain.CTIssuance, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "seen_at", Value: -1}}).SetLimit(limit)
+	cursor, err := r.issuances.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.CTIssuance
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeployTargetRepository 管理憑證部署目標 (deploy_targets collection)
+type DeployTargetRepository interface {
+	Create(ctx context.Context, target *domain.DeployTarget) error
+	// 更新目標設定 (不含部署結果)
+	Update(ctx context.Context, target domain.DeployTarget) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.DeployTarget, error)
+	ListByDomainID(ctx context.Context, domainID primitive.ObjectID) ([]domain.DeployTarget, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// 記錄部署結果 (cfCustomCertID 為空字串代表不更新)
+	UpdateResult(ctx context.Context, id primitive.ObjectID, status, errMsg, certSerial, cfCustomCertID string) error
+}
+
+type mongoDeployTargetRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDeployTargetRepo(db *mongo.Database) DeployTargetRepository {
+	coll := db.Collection("deploy_targets")
+
+	// 舊版目標直接保存指令與絕對路徑：停用並保留紀錄，改用 config.yaml 的名稱後再啟用
+	legacy := bson.M{"$or": []bson.M{
+		{"command": bson.M{"$exists": true}},
+		{"directory": bson.M{"$regex": "^/"}},
+	}}
+	res, err := coll.UpdateMany(context.Background(), legacy, bson.M{
+		"$set": bson.M{
+			"enabled":     false,
+			"last_status": domain.DeployStatusFailed,
+			"last_error":  "舊版設定 (直接指定指令或路徑) 已停用，請改用 config.yaml deploy 中的名稱",
+		},
+		"$unset": bson.M{"command": ""},
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ 停用舊版部署目標失敗: %v", err)
+	} else if res.ModifiedCount > 0 {
+		logrus.Warnf("⚠️ 已停用 %d 個舊版部署目標 (指令與目錄需改用 config.yaml deploy 中的名稱)", res.ModifiedCount)
+	}
+
+	return &mongoDeployTargetRepo{collection: coll}
+}
+
+func (r *mongoDeployTargetRepo) Create(ctx context.Context, target *domain.DeployTarget) error {
+	if target.ID.IsZero() {
+		target.ID = primitive.NewObjectID()
+	}
+	if target.CreatedAt.IsZero() {
+		target.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, target)
+	return err
+}
+
+func (r *mongoDeployTargetRepo) Update(ctx context.Context, target domain.DeployTarget) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":            target.Name,
+			"type":            target.Type,
+			"enabled":         target.Enabled,
+			"directory":       target.Directory,
+			"file_mode":       target.FileMode,
+			"hook":            target.Hook,
+			"timeout_seconds": target.TimeoutSeconds,
+			"namespace":       target.Namespace,
+			"secret_name":     target.SecretName,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": target.ID}, update)
+	return err
+}
+
+func (r *mongoDeployTargetRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.DeployTarget, error) {
+	var target domain.DeployTarget
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *mongoDeployTargetRepo) ListByDomainID(ctx context.Context, domainID primitive.ObjectID) ([]domain.DeployTarget, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"domain_id": domainID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.DeployTarget
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoDeployTargetRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoDeployTargetRepo) UpdateResult(ctx context.Context, id primitive.ObjectID, status, errMsg, certSerial, cfCustomCertID string) error {
+	fields := bson.M{
+		"last_deploy_at":   time.Now(),
+		"last_status":      status,
+		"last_error":       errMsg,
+		"last_cert_serial": certSerial,
+	}
+	if cfCustomCertID != "" {
+		fields["cf_custom_cert_id"] = cfCustomCertID
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	return err
+}
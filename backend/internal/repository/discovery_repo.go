@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DiscoveryRepository 探索到的主機名稱 (discovered_hosts collection)
+type DiscoveryRepository interface {
+	// Record 記錄一次發現，新主機名稱以 pending 建立 (已接受/忽略的保留原狀態)；新建立時回傳 true
+	Record(ctx context.Context, hostname, zone, source, seenIn string) (bool, error)
+	// List status 為空代表全部
+	List(ctx context.Context, status string) ([]domain.DiscoveredHost, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.DiscoveredHost, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string, domainID primitive.ObjectID) error
+	// DeletePending 移除已被其他方式 (同步、手動新增) 納入監控的待確認項目
+	DeletePending(ctx context.Context, hostnames []string) error
+}
+
+type mongoDiscoveryRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDiscoveryRepo(db *mongo.Database) DiscoveryRepository {
+	coll := db.Collection("discovered_hosts")
+
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "hostname", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ 建立 discovered_hosts 索引失敗: %v", err)
+	}
+
+	return &mongoDiscoveryRepo{collection: coll}
+}
+
+func (r *mongoDiscoveryRepo) Record(ctx context.Context, hostname, zone, source, seenIn string) (bool, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"zone_name":    zone,
+			"last_seen_in": seenIn,
+			"last_seen_at": now,
+		},
+		"$addToSet": bson.M{"sources": source},
+		"$setOnInsert": bson.M{
+			"status":        domain.DiscoveryStatusPending,
+			"first_seen_in": seenIn,
+			"first_seen_at": now,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	res, err := r.collection.UpdateOne(ctx, bson.M{"hostname": hostname}, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *mongoDiscoveryRepo) List(ctx context.Context, status string) ([]domain.DiscoveredHost, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "zone_name", Value: 1}, {Key: "hostname", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.DiscoveredHost
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *mongoDiscoveryRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.DiscoveredHost, error) {
+	var host domain.DiscoveredHost
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&host); err != nil {
+		return nil, err
+	}
+	return &host, nil
+}
+
+func (r *mongoDiscoveryRepo) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string, domainID primitive.ObjectID) error {
+	set := bson.M{"status": status}
+	if !domainID.IsZero() {
+		set["domain_id"] = domainID
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+func (r *mongoDiscoveryRepo) DeletePending(ctx context.Context, hostnames []string) error {
+	if len(hostnames) == 0 {
+		return nil
+	}
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"status":   domain.DiscoveryStatusPending,
+		"hostname": bson.M{"$in": hostnames},
+	})
+	return err
+}
@@ -12,6 +12,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// 每個域名保留的 DNS 變更紀錄筆數
+const maxChangeHistory = 20
+
 // 1. 修改介面簽章 (加入 proxiedFilter 和 ignoredFilter)
 // proxiedFilter: "true" (只顯Proxy), "false" (只顯非Proxy), "" (全部)
 // ignoredFilter: "true" (顯示忽略的), "false" (隱藏忽略的-預設)
@@ -20,7 +23,7 @@ type DomainRepository interface {
 	List(ctx context.Context, page, pageSize int64, sortBy, search, statusFilter, proxiedFilter, ignoredFilter, zoneFilter string) ([]domain.SSLCertificate, int64, error)
 	UpdateCertInfo(ctx context.Context, cert domain.SSLCertificate) error
 	// [新增] 更新設定 (用於切換是否忽略)
-	UpdateSettings(ctx context.Context, id string, isIgnored bool, port int) error
+	UpdateSettings(ctx context.Context, id string, isIgnored bool, port int, autoRenew bool) error
 	GetUniqueZones(ctx context.Context) ([]string, error)
 
 	// [新增] 設定相關
@@ -29,6 +32,10 @@ type DomainRepository interface {
 
 	// [新增] 更新告警時間
 	UpdateAlertTime(ctx context.Context, domainID primitive.ObjectID) error
+	// 源站憑證告警時間 (與邊緣憑證分開防騷擾)
+	UpdateOriginAlertTime(ctx context.Context, domainID primitive.ObjectID) error
+	// AppendChangeHistory 追加一筆 DNS 變更紀錄 (只保留最近 maxChangeHistory 筆)
+	AppendChangeHistory(ctx context.Context, domainID primitive.ObjectID, change domain.DNSChange) error
 
 	GetStatistics(ctx context.Context) (*domain.DashboardStats, error)
 
@@ -39,6 +46,14 @@ type DomainRepository interface {
 	Create(ctx context.Context, cert domain.SSLCertificate) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.SSLCertificate, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// 記錄 Cloudflare Origin CA 憑證 (與邊緣憑證分開)
+	UpdateOriginCert(ctx context.Context, id primitive.ObjectID, certID primitive.ObjectID, notAfter time.Time) error
+	// 依 origin_not_after 重新計算 origin_days_remaining
+	RefreshOriginDays(ctx context.Context) error
+
+	// 撈出開啟自動續簽且剩餘天數低於 withinDays 的域名 (withinDays <= 0 代表不限天數)
+	ListAutoRenew(ctx context.Context, withinDays int) ([]domain.SSLCertificate, error)
 }
 
 type mongoDomainRepo struct {
@@ -130,9 +145,25 @@ func (r *mongoDomainRepo) GetStatistics(ctx context.Context) (*domain.DashboardS
 }
 
 func NewMongoDomainRepo(db *mongo.Database) DomainRepository {
-	return &mongoDomainRepo{
-		collection: db.Collection("domains"),
+	coll := db.Collection("domains")
+
+	// 舊資料遷移：cf_zone_id / cf_record_id 改為通用的 provider / zone_id / record_id
+	migrate := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"provider":  bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$cf_zone_id", ""}}, domain.ProviderCloudflare, ""}},
+			"zone_id":   "$cf_zone_id",
+			"record_id": "$cf_record_id",
+		}}},
+		{{Key: "$unset", Value: bson.A{"cf_zone_id", "cf_record_id"}}},
 	}
+	res, err := coll.UpdateMany(context.Background(), bson.M{"cf_zone_id": bson.M{"$exists": true}}, migrate)
+	if err != nil {
+		logrus.Warnf("⚠️ 遷移 domains 的 DNS Provider 欄位失敗: %v", err)
+	} else if res.ModifiedCount > 0 {
+		logrus.Infof("🔁 已遷移 %d 筆域名的 DNS Provider 欄位", res.ModifiedCount)
+	}
+
+	return &mongoDomainRepo{collection: coll}
 }
 
 // 1. 實作 GetUniqueZones (使用 MongoDB Distinct)
@@ -180,24 +211,28 @@ func (r *mongoDomainRepo) GetUniqueZones(ctx context.Context) ([]string, error)
 // 	return err
 // }
 
-// Upsert: 根據 DomainName 和 CFRecordID 判斷，有則更新，無則新增
+// Upsert: 根據 DomainName 和 RecordID 判斷，有則更新，無則新增
 // [修正] 必須包含所有 SSL 欄位，否則 CronService 同步時會遺失掃描結果
 func (r *mongoDomainRepo) Upsert(ctx context.Context, cert domain.SSLCertificate) error {
 	filter := bson.M{
-		"domain_name":  cert.DomainName,
-		"cf_record_id": cert.CFRecordID,
+		"domain_name": cert.DomainName,
+		"record_id":   cert.RecordID,
 	}
 
 	update := bson.M{
 		"$set": bson.M{
-			// --- Cloudflare 資訊 ---
-			"cf_zone_id":      cert.CFZoneID,
+			// --- DNS 來源資訊 ---
+			"provider":        cert.Provider,
+			"zone_id":         cert.ZoneID,
 			"zone_name":       cert.ZoneName,
 			"is_proxied":      cert.IsProxied,
 			"cf_record_type":  cert.CFRecordType,
 			"cf_origin_value": cert.CFOriginValue,
 			"port":            cert.Port, // 確保 Port 也被更新
 			"cf_comment":      cert.CFComment,
+			"cf_account_id":   cert.CFAccountID,
+
+			"record_modified_on": cert.RecordModifiedOn,
 
 			// --- 系統狀態 ---
 			"status":          cert.Status,
@@ -214,6 +249,9 @@ func (r *mongoDomainRepo) Upsert(ctx context.Context, cert domain.SSLCertificate
 			"http_status_code": cert.HTTPStatusCode,
 			"latency":          cert.Latency,
 			"is_match":         cert.IsMatch,
+			"endpoints":        cert.Endpoints,
+			"endpoint_issue":   cert.EndpointIssue,
+			"origin_check":     cert.OriginCheck,
 
 			// --- [關鍵新增] 網路/WHOIS 資訊 ---
 			"domain_expiry_date": cert.DomainExpiryDate,
@@ -402,6 +440,9 @@ func (r *mongoDomainRepo) UpdateCertInfo(ctx context.Context, cert domain.SSLCer
 			"cf_record_type":     cert.CFRecordType, // [新增]
 			"cf_origin_value":    cert.CFOriginValue,
 			"cf_comment":         cert.CFComment,
+			"endpoints":          cert.Endpoints,
+			"endpoint_issue":     cert.EndpointIssue,
+			"origin_check":       cert.OriginCheck,
 		},
 	}
 
@@ -410,11 +451,11 @@ func (r *mongoDomainRepo) UpdateCertInfo(ctx context.Context, cert domain.SSLCer
 }
 
 // 3. [新增] 實作 UpdateSettings
-func (r *mongoDomainRepo) UpdateSettings(ctx context.Context, id string, isIgnored bool, port int) error {
+func (r *mongoDomainRepo) UpdateSettings(ctx context.Context, id string, isIgnored bool, port int, autoRenew bool) error {
 	oid, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{"_id": oid}
 	update := bson.M{
-		"$set": bson.M{"is_ignored": isIgnored, "port": port},
+		"$set": bson.M{"is_ignored": isIgnored, "port": port, "auto_renew": autoRenew},
 	}
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
@@ -452,6 +493,23 @@ func (r *mongoDomainRepo) UpdateAlertTime(ctx context.Context, domainID primitiv
 	return err
 }
 
+func (r *mongoDomainRepo) UpdateOriginAlertTime(ctx context.Context, domainID primitive.ObjectID) error {
+	filter := bson.M{"_id": domainID}
+	update := bson.M{"$set": bson.M{"origin_last_alert_time": time.Now()}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *mongoDomainRepo) AppendChangeHistory(ctx context.Context, domainID primitive.ObjectID, change domain.DNSChange) error {
+	filter := bson.M{"_id": domainID}
+	update := bson.M{"$push": bson.M{"change_history": bson.M{
+		"$each":  []domain.DNSChange{change},
+		"$slice": -maxChangeHistory,
+	}}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
 // 實作
 func (r *mongoDomainRepo) UpdateAcmeData(ctx context.Context, email, privateKey, regData string) error {
 	coll := r.collection.Database().Collection("settings")
@@ -507,3 +565,55 @@ func (r *mongoDomainRepo) Delete(ctx context.Context, id primitive.ObjectID) err
 	_, err := r.collection.DeleteOne(ctx, filter)
 	return err
 }
+
+// 實作 ListAutoRenew
+func (r *mongoDomainRepo) ListAutoRenew(ctx context.Context, withinDays int) ([]domain.SSLCertificate, error) {
+	filter := bson.M{
+		"auto_renew": true,
+		"is_ignored": false,
+	}
+	if withinDays > 0 {
+		filter["days_remaining"] = bson.M{"$lt": withinDays}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "days_remaining", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.SSLCertificate
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// 實作 UpdateOriginCert
+func (r *mongoDomainRepo) UpdateOriginCert(ctx context.Context, id primitive.ObjectID, certID primitive.ObjectID, notAfter time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"origin_cert_id":        certID,
+			"origin_not_after":      notAfter,
+			"origin_days_remaining": int(time.Until(notAfter).Hours() / 24),
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// 實作 RefreshOriginDays (用 Pipeline Update 一次更新所有有 Origin 憑證的域名)
+func (r *mongoDomainRepo) RefreshOriginDays(ctx context.Context) error {
+	filter := bson.M{"origin_not_after": bson.M{"$exists": true}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"origin_days_remaining": bson.M{
+				"$floor": bson.M{
+					"$divide": bson.A{bson.M{"$subtract": bson.A{"$origin_not_after", "$$NOW"}}, 24 * 60 * 60 * 1000},
+				},
+			},
+		}}},
+	}
+	_, err := r.collection.UpdateMany(ctx, filter, pipeline)
+	return err
+}
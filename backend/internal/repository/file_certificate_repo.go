@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FileCertificateRepository 管理磁碟憑證清單 (file_certificates collection)
+type FileCertificateRepository interface {
+	// ReplaceFile 以檔案為單位更新：寫入 entries 並刪除該檔案中已不存在的項目 (保留忽略設定與告警時間)
+	ReplaceFile(ctx context.Context, path string, entries []domain.FileCertificate) error
+	// DeleteByPath 刪除檔案，或目錄底下所有檔案的項目
+	DeleteByPath(ctx context.Context, path string) error
+	List(ctx context.Context) ([]domain.FileCertificate, error)
+	ListByPath(ctx context.Context, path string) ([]domain.FileCertificate, error)
+	UpdateIgnored(ctx context.Context, id primitive.ObjectID, isIgnored bool) error
+	UpdateAlertTime(ctx context.Context, id primitive.ObjectID) error
+}
+
+type mongoFileCertificateRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoFileCertificateRepo(db *mongo.Database) FileCertificateRepository {
+	coll := db.Collection("file_certificates")
+
+	_, err := coll.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "path", Value: 1}, {Key: "entry", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ 建立 file_certificates 索引失敗: %v", err)
+	}
+
+	return &mongoFileCertificateRepo{collection: coll}
+}
+
+func (r *mongoFileCertificateRepo) ReplaceFile(ctx context.Context, path string, entries []domain.FileCertificate) error {
+	now := time.Now()
+	keep := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keep = append(keep, e.Entry)
+		update := bson.M{
+			"$set": bson.M{
+				"format":         e.Format,
+				"subject":        e.Subject,
+				"issuer":         e.Issuer,
+				"sans":           e.SANs,
+				"serial_number":  e.SerialNumber,
+				"fingerprint":    e.Fingerprint,
+				"is_ca":          e.IsCA,
+				"not_before":     e.NotBefore,
+				"not_after":      e.NotAfter,
+				"days_remaining": e.DaysRemaining,
+				"status":         e.Status,
+				"error_msg":      e.ErrorMsg,
+				"last_scan_at":   now,
+			},
+			"$setOnInsert": bson.M{
+				"created_at":      now,
+				"is_ignored":      false,
+				"last_alert_time": time.Time{},
+			},
+		}
+		opts := options.Update().SetUpsert(true)
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"path": path, "entry": e.Entry}, update, opts); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"path": path, "entry": bson.M{"$nin": keep}})
+	return err
+}
+
+func (r *mongoFileCertificateRepo) DeleteByPath(ctx context.Context, path string) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"path": path},
+		bson.M{"path": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(path+"/")}},
+	}}
+	_, err := r.collection.DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *mongoFileCertificateRepo) List(ctx context.Context) ([]domain.FileCertificate, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *mongoFileCertificateRepo) ListByPath(ctx context.Context, path string) ([]domain.FileCertificate, error) {
+	return r.find(ctx, bson.M{"path": path})
+}
+
+func (r *mongoFileCertificateRepo) UpdateIgnored(ctx context.Context, id primitive.ObjectID, isIgnored bool) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"is_ignored": isIgnored}})
+	return err
+}
+
+func (r *mongoFileCertificateRepo) UpdateAlertTime(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_alert_time": time.Now()}})
+	return err
+}
+
+func (r *mongoFileCertificateRepo) find(ctx context.Context, filter bson.M) ([]domain.FileCertificate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "not_after", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.FileCertificate
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InternalCARepository 保存內建私有 CA (internal_ca collection，單筆)
+type InternalCARepository interface {
+	Get(ctx context.Context) (*domain.InternalCA, error)
+	// 取代目前的 CA (產生或匯入新的 CA 時使用)
+	Save(ctx context.Context, ca *domain.InternalCA) error
+}
+
+type mongoInternalCARepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoInternalCARepo(db *mongo.Database) InternalCARepository {
+	return &mongoInternalCARepo{
+		collection: db.Collection("internal_ca"),
+	}
+}
+
+func (r *mongoInternalCARepo) Get(ctx context.Context) (*domain.InternalCA, error) {
+	var ca domain.InternalCA
+	if err := r.collection.FindOne(ctx, bson.M{}).Decode(&ca); err != nil {
+		return nil, err
+	}
+	return &ca, nil
+}
+
+func (r *mongoInternalCARepo) Save(ctx context.Context, ca *domain.InternalCA) error {
+	if ca.CreatedAt.IsZero() {
+		ca.CreatedAt = time.Now()
+	}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{}, ca, opts)
+	return err
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ZoneSecurityRepository Zone 的 SSL/TLS 設定與 Edge 憑證包 (zone_security collection)
+type ZoneSecurityRepository interface {
+	Get(ctx context.Context, zoneID string) (*domain.ZoneSecurity, error) // 尚未同步過回傳 nil
+	Save(ctx context.Context, zone domain.ZoneSecurity) error
+	List(ctx context.Context) ([]domain.ZoneSecurity, error)
+}
+
+type mongoZoneSecurityRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoZoneSecurityRepo(db *mongo.Database) ZoneSecurityRepository {
+	return &mongoZoneSecurityRepo{collection: db.Collection("zone_security")}
+}
+
+func (r *mongoZoneSecurityRepo) Get(ctx context.Context, zoneID string) (*domain.ZoneSecurity, error) {
+	var zone domain.ZoneSecurity
+	err := r.collection.FindOne(ctx, bson.M{"_id": zoneID}).Decode(&zone)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *mongoZoneSecurityRepo) Save(ctx context.Context, zone domain.ZoneSecurity) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": zone.ZoneID}, zone, opts)
+	return err
+}
+
+func (r *mongoZoneSecurityRepo) List(ctx context.Context) ([]domain.ZoneSecurity, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "zone_name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.ZoneSecurity
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
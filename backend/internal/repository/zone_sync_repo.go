@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ZoneSyncRepository 每個 Provider 帳號下 Zone 的增量同步進度 (zone_sync_states collection)
+type ZoneSyncRepository interface {
+	Get(ctx context.Context, id string) (*domain.ZoneSyncState, error) // id 見 domain.ZoneSyncState，尚未同步過回傳 nil
+	Save(ctx context.Context, state domain.ZoneSyncState) error
+	List(ctx context.Context) ([]domain.ZoneSyncState, error)
+}
+
+type mongoZoneSyncRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMongoZoneSyncRepo(db *mongo.Database) ZoneSyncRepository {
+	coll := db.Collection("zone_sync_states")
+
+	// 舊資料以 Zone ID 為 _id，無法分辨屬於哪個帳號：直接移除，下次同步會以新的 Key 重新記錄
+	res, err := coll.DeleteMany(context.Background(), bson.M{"zone_id": bson.M{"$exists": false}})
+	if err != nil {
+		logrus.Warnf("⚠️ 清除舊版 zone_sync_states 失敗: %v", err)
+	} else if res.DeletedCount > 0 {
+		logrus.Infof("🔁 已清除 %d 筆舊版 Zone 同步進度 (下次同步重新記錄)", res.DeletedCount)
+	}
+
+	return &mongoZoneSyncRepo{collection: coll}
+}
+
+func (r *mongoZoneSyncRepo) Get(ctx context.Context, id string) (*domain.ZoneSyncState, error) {
+	var state domain.ZoneSyncState
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *mongoZoneSyncRepo) Save(ctx context.Context, state domain.ZoneSyncState) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": state.ID}, state, opts)
+	return err
+}
+
+func (r *mongoZoneSyncRepo) List(ctx context.Context) ([]domain.ZoneSyncState, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "zone_name", Value: 1}, {Key: "provider", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.ZoneSyncState
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,676 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/go-acme/lego/v4/providers/dns/cloudflare"
+	"github.com/go-acme/lego/v4/registration"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 簽發任務狀態
+const (
+	IssueStatusRunning = "running"
+	IssueStatusSuccess = "success"
+	IssueStatusFailed  = "failed"
+)
+
+// IssueStep 簽發過程中的單一步驟 (給前端顯示進度用)
+type IssueStep struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// IssueJob 單一域名的簽發任務
+type IssueJob struct {
+	DomainID    string      `json:"domain_id"`
+	DomainName  string      `json:"domain_name"`
+	AccountName string      `json:"account_name,omitempty"`
+	Status      string      `json:"status"`
+	Steps       []IssueStep `json:"steps"`
+	Error       string      `json:"error,omitempty"`
+	NotAfter    time.Time   `json:"not_after,omitempty"`
+	StartedAt   time.Time   `json:"started_at"`
+	FinishedAt  time.Time   `json:"finished_at,omitempty"`
+
+	// 簽發成功後保存在 certificates 的 ID
+	CertificateID string `json:"certificate_id,omitempty"`
+}
+
+// AcmeService 負責 ACME 帳號管理與憑證簽發 (DNS-01 透過 Cloudflare，HTTP-01 由本服務回應)
+type AcmeService struct {
+	Repo      repository.DomainRepository
+	Accounts  repository.AcmeAccountRepository
+	CFService *CloudflareService
+	HTTP01    *HTTP01Provider
+	Notifier  *NotifierService
+	Store     *CertStoreService
+	Deployer  *DeployService
+
+	mu   sync.Mutex
+	jobs map[string]*IssueJob // key: SSLCertificate ID
+}
+
+func NewAcmeService(repo repository.DomainRepository, accounts repository.AcmeAccountRepository, cf *CloudflareService, http01 *HTTP01Provider, notifier *NotifierService, store *CertStoreService, deployer *DeployService) *AcmeService {
+	return &AcmeService{
+		Repo:      repo,
+		Accounts:  accounts,
+		CFService: cf,
+		HTTP01:    http01,
+		Notifier:  notifier,
+		Store:     store,
+		Deployer:  deployer,
+		jobs:      make(map[string]*IssueJob),
+	}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// StartIssue 在背景啟動簽發任務，同一域名同時間只允許一個任務
+func (s *AcmeService) StartIssue(cert domain.SSLCertificate, account *domain.AcmeAccount) (*IssueJob, error) {
+	id := cert.ID.Hex()
+
+	s.mu.Lock()
+	if job, ok := s.jobs[id]; ok && job.Status == IssueStatusRunning {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%s 已有簽發任務進行中", cert.DomainName)
+	}
+	job := &IssueJob{
+		DomainID:    id,
+		DomainName:  cert.DomainName,
+		AccountName: account.Name,
+		Status:      IssueStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	s.jobs[id] = job
+	s.mu.Unlock()
+
+	go func() {
+		ctx := context.Background()
+		mc, err := s.issueAndStore(ctx, cert, account, "", func(msg string) { s.addStep(id, msg) })
+		s.finishJob(id, mc, err)
+	}()
+
+	return s.GetJob(id), nil
+}
+
+// GetJob 回傳簽發任務的快照 (不存在則回傳 nil)
+func (s *AcmeService) GetJob(domainID string) *IssueJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[domainID]
+	if !ok {
+		return nil
+	}
+	snapshot := *job
+	snapshot.Steps = append([]IssueStep(nil), job.Steps...)
+	return &snapshot
+}
+
+// ObtainCertificate 向指定帳號的 CA 為域名簽發憑證
+// 有 Cloudflare Zone 的域名走 DNS-01，其餘走 HTTP-01 (萬用字元只能用 DNS-01)
+// replacesCertID 為被取代憑證的 ARI CertID (續簽時帶入，可為空)；progress 可為 nil，用於回報目前進度
+func (s *AcmeService) ObtainCertificate(ctx context.Context, cert domain.SSLCertificate, account *domain.AcmeAccount, replacesCertID string, progress func(string)) (*certificate.Resource, error) {
+	report := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		logrus.Infof("🔐 [ACME] %s: %s", cert.DomainName, msg)
+		if progress != nil {
+			progress(msg)
+		}
+	}
+
+	report("載入 ACME 帳號: %s (%s)", account.Name, account.DirectoryURL)
+	client, err := s.newClient(ctx, account, report)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeType := challengeTypeFor(cert)
+	report("設定 %s 驗證", challengeType)
+	if err := s.setupChallenge(ctx, client, cert, challengeType); err != nil {
+		return nil, err
+	}
+
+	report("送出簽發請求，等待 %s 驗證 (可能需要數分鐘)", challengeType)
+	res, err := client.Certificate.Obtain(certificate.ObtainRequest{
+		Domains:        []string{cert.DomainName},
+		Bundle:         true,
+		ReplacesCertID: replacesCertID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("簽發失敗: %w", err)
+	}
+
+	report("簽發成功")
+	return res, nil
+}
+
+// Renew 重新簽發憑證：新憑證、鏈與私鑰 (加密) 先存入憑證庫，成功後才將新憑證的資訊寫回域名紀錄
+// 回傳更新後的域名資料 (呼叫方可用舊資料比對到期日)
+func (s *AcmeService) Renew(ctx context.Context, cert domain.SSLCertificate) (domain.SSLCertificate, error) {
+	// 沒有憑證庫就無法保存私鑰，不要向 CA 申請一張存不下來的憑證
+	if s.Store == nil {
+		return cert, fmt.Errorf("憑證庫未設定，無法保存續簽的憑證")
+	}
+
+	// 沿用上一次簽發的帳號 (CA)，沒有紀錄則使用預設帳號
+	var accountID primitive.ObjectID
+	var replacesCertID string
+	if latest, err := s.Store.Repo.GetLatestByDomainID(ctx, cert.ID); err == nil && latest.Source == domain.CertSourceAcme {
+		accountID = latest.AcmeAccountID
+		// 告知 CA 這次是取代哪一張憑證 (ARI replaces)
+		if leaf, err := parseCertificateChain([]byte(latest.CertPEM)); err == nil {
+			replacesCertID, _ = certificate.MakeARICertID(leaf[0])
+		}
+	}
+	account, err := s.ResolveAccount(ctx, accountID)
+	if err != nil {
+		return cert, err
+	}
+
+	mc, err := s.issueAndStore(ctx, cert, account, replacesCertID, nil)
+	if err != nil {
+		return cert, err
+	}
+
+	renewed := cert
+	renewed.Issuer = mc.Issuer
+	renewed.NotBefore = mc.NotBefore
+	renewed.NotAfter = mc.NotAfter
+	renewed.DaysRemaining = int(time.Until(mc.NotAfter).Hours() / 24)
+	renewed.SANs = mc.SANs
+
+	if err := s.Repo.UpdateCertInfo(ctx, renewed); err != nil {
+		return renewed, fmt.Errorf("寫入新憑證資訊失敗: %w", err)
+	}
+	return renewed, nil
+}
+
+// RenewalDue 判斷域名是否該續簽
+// 最新憑證由支援 ARI 的 CA 簽發時，依 CA 建議的時段決定；否則退回剩餘天數 < renewBeforeDays
+// 剩餘天數以憑證庫中最新憑證與目前線上憑證較晚的到期日計算：新憑證尚未部署時，掃描到的仍是舊憑證，
+// 只看線上憑證會讓每次續簽排程都再簽一張
+func (s *AcmeService) RenewalDue(ctx context.Context, cert domain.SSLCertificate, renewBeforeDays int) (bool, string) {
+	latest, err := s.Store.Repo.GetLatestByDomainID(ctx, cert.ID)
+	if err != nil {
+		latest = nil
+	}
+
+	byDays := func() (bool, string) {
+		notAfter := cert.NotAfter
+		if latest != nil && latest.RevokedAt.IsZero() && latest.NotAfter.After(notAfter) {
+			notAfter = latest.NotAfter
+		}
+		if notAfter.IsZero() {
+			return true, "尚無憑證"
+		}
+		days := int(time.Until(notAfter).Hours() / 24)
+		if days < renewBeforeDays {
+			return true, fmt.Sprintf("剩餘 %d 天 (< %d 天)", days, renewBeforeDays)
+		}
+		return false, ""
+	}
+
+	if latest == nil || latest.Source != domain.CertSourceAcme || !latest.RevokedAt.IsZero() {
+		return byDays()
+	}
+
+	info := latest.RenewalInfo
+	if info == nil || !time.Now().Before(info.NextCheckAt) {
+		if info, err = s.RefreshRenewalInfo(ctx, latest); err != nil {
+			logrus.Debugf("[ARI] %s 無法取得續簽建議，改用天數判斷: %v", cert.DomainName, err)
+			return byDays()
+		}
+	}
+
+	if time.Now().Before(info.RenewAt) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("進入 CA 建議的續簽時段 (%s ~ %s)", info.WindowStart.Format("2006-01-02 15:04"), info.WindowEnd.Format("2006-01-02 15:04"))
+}
+
+// RefreshRenewalInfo 向 CA 查詢憑證的 ARI 續簽時段並保存
+func (s *AcmeService) RefreshRenewalInfo(ctx context.Context, mc *domain.ManagedCertificate) (*domain.RenewalInfo, error) {
+	leaf, err := parseCertificateChain([]byte(mc.CertPEM))
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.ResolveAccount(ctx, mc.AcmeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.newClient(ctx, account, func(string, ...interface{}) {})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Certificate.GetRenewalInfo(certificate.RenewalInfoRequest{Cert: leaf[0]})
+	if err != nil {
+		return nil, err
+	}
+
+	retryAfter := res.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = 6 * time.Hour
+	}
+	now := time.Now()
+	info := domain.RenewalInfo{
+		WindowStart:    res.SuggestedWindow.Start,
+		WindowEnd:      res.SuggestedWindow.End,
+		RenewAt:        res.SuggestedWindow.Start,
+		ExplanationURL: res.ExplanationURL,
+		CheckedAt:      now,
+		NextCheckAt:    now.Add(retryAfter),
+	}
+	// 依 RFC 9773 在建議時段內隨機挑一個時間點，避免所有客戶端同時續簽
+	if window := info.WindowEnd.Sub(info.WindowStart); window > 0 {
+		info.RenewAt = info.WindowStart.Add(time.Duration(mrand.Int63n(int64(window))))
+	}
+	if err := s.Store.Repo.UpdateRenewalInfo(ctx, mc.ID, info); err != nil {
+		return nil, err
+	}
+	mc.RenewalInfo = &info
+	return &info, nil
+}
+
+// Revoke 向 CA 撤銷憑證 (reason 為 RFC 5280 CRLReason)
+func (s *AcmeService) Revoke(ctx context.Context, mc *domain.ManagedCertificate, reason uint) error {
+	if mc.Source != domain.CertSourceAcme {
+		return fmt.Errorf("只能撤銷由 ACME 簽發的憑證")
+	}
+	if !mc.RevokedAt.IsZero() {
+		return fmt.Errorf("憑證已於 %s 撤銷", mc.RevokedAt.Format("2006-01-02 15:04"))
+	}
+	if _, ok := domain.RevocationReasons[reason]; !ok {
+		return fmt.Errorf("不支援的撤銷原因代碼: %d", reason)
+	}
+
+	account, err := s.ResolveAccount(ctx, mc.AcmeAccountID)
+	if err != nil {
+		return err
+	}
+	client, err := s.newClient(ctx, account, func(string, ...interface{}) {})
+	if err != nil {
+		return err
+	}
+	if err := client.Certificate.RevokeWithReason([]byte(mc.CertPEM), &reason); err != nil {
+		return fmt.Errorf("撤銷失敗: %w", err)
+	}
+
+	if err := s.Store.Repo.MarkRevoked(ctx, mc.ID, reason); err != nil {
+		return fmt.Errorf("撤銷成功但寫入紀錄失敗: %w", err)
+	}
+
+	details := fmt.Sprintf("🚫 <b>憑證已撤銷</b>\n🔢 序號: <code>%s</code>\n📝 原因: %s", mc.SerialNumber, domain.RevocationReasons[reason])
+	s.Notifier.NotifyOperation(ctx, EventUpdate, mc.DomainName, details)
+	return nil
+}
+
+// ResolveAccount 取得指定的 ACME 帳號，id 為零值時回傳預設帳號
+// 尚未建立任何帳號時，會將舊版設定 (acme_email / acme_private_key) 遷移成預設帳號
+func (s *AcmeService) ResolveAccount(ctx context.Context, id primitive.ObjectID) (*domain.AcmeAccount, error) {
+	if !id.IsZero() {
+		account, err := s.Accounts.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("找不到 ACME 帳號: %w", err)
+		}
+		return account, nil
+	}
+
+	account, err := s.Accounts.GetDefault(ctx)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	accounts, err := s.Accounts.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) > 0 {
+		return nil, fmt.Errorf("尚未指定預設 ACME 帳號")
+	}
+	return s.migrateLegacyAccount(ctx)
+}
+
+// CreateAccount 新增 ACME 帳號 (第一個帳號自動成為預設)
+func (s *AcmeService) CreateAccount(ctx context.Context, account *domain.AcmeAccount) error {
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+
+	accounts, err := s.Accounts.List(ctx)
+	if err != nil {
+		return err
+	}
+	account.IsDefault = len(accounts) == 0
+	account.PrivateKey = ""
+	account.RegData = ""
+	return s.Accounts.Create(ctx, account)
+}
+
+// UpdateAccount 更新 ACME 帳號設定
+// EAB HMAC 只能在新增時設定，更新時沿用原本的值 (因此 EAB Key ID 也不可變更)
+// CA 或 Email 變更時會清除註冊資訊，下次簽發時重新註冊
+func (s *AcmeService) UpdateAccount(ctx context.Context, account domain.AcmeAccount) error {
+	current, err := s.Accounts.GetByID(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	if current.EABKeyID != account.EABKeyID {
+		return fmt.Errorf("EAB Key ID 不可變更，請新增帳號")
+	}
+	account.EABHMACKey = current.EABHMACKey
+	if err := validateAccount(&account); err != nil {
+		return err
+	}
+
+	account.RegData = current.RegData
+	if current.DirectoryURL != account.DirectoryURL || current.Email != account.Email {
+		account.RegData = ""
+	}
+	return s.Accounts.Update(ctx, account)
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// issueAndStore 簽發憑證並存入憑證庫
+func (s *AcmeService) issueAndStore(ctx context.Context, cert domain.SSLCertificate, account *domain.AcmeAccount, replacesCertID string, progress func(string)) (*domain.ManagedCertificate, error) {
+	res, err := s.ObtainCertificate(ctx, cert, account, replacesCertID, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	mc, err := s.Store.SaveAcme(ctx, cert, account.ID, res.Certificate, res.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("保存憑證失敗: %w", err)
+	}
+	if progress != nil {
+		progress("憑證已保存")
+	}
+
+	// 部署到設定的目標 (個別失敗不影響簽發結果，會另外通知)
+	if s.Deployer != nil {
+		for _, r := range s.Deployer.DeployCertificate(ctx, mc) {
+			if progress != nil {
+				progress(fmt.Sprintf("部署 %s (%s): %s", r.Name, r.Type, r.Status))
+			}
+		}
+	}
+	return mc, nil
+}
+
+// newClient 建立 lego Client，並確保帳號已在該 CA 註冊
+func (s *AcmeService) newClient(ctx context.Context, account *domain.AcmeAccount, report func(string, ...interface{})) (*lego.Client, error) {
+	user, err := s.loadUser(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	config := lego.NewConfig(user)
+	config.CADirURL = account.DirectoryURL
+	config.Certificate.KeyType = certcrypto.EC256
+
+	// 內部 CA (step-ca / Pebble) 的 Directory 通常使用自簽憑證
+	if account.RootCAPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(account.RootCAPEM)) {
+			return nil, fmt.Errorf("自訂根憑證格式錯誤")
+		}
+		config.HTTPClient = &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+
+	client, err := lego.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("ACME Client 初始化失敗: %w", err)
+	}
+
+	// 沒有註冊資訊時才向 CA 註冊 (或找回既有帳號)
+	if user.Registration == nil {
+		report("註冊 ACME 帳號 (%s)", user.Email)
+
+		var reg *registration.Resource
+		switch {
+		case account.EABKeyID != "":
+			reg, err = client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
+				TermsOfServiceAgreed: true,
+				Kid:                  account.EABKeyID,
+				HmacEncoded:          account.EABHMACKey,
+			})
+		case client.GetExternalAccountRequired():
+			return nil, fmt.Errorf("此 CA 需要 External Account Binding (EAB Key ID / HMAC)")
+		default:
+			reg, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ACME 帳號註冊失敗: %w", err)
+		}
+		user.Registration = reg
+
+		regData, err := json.Marshal(reg)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Accounts.UpdateKeyData(ctx, account.ID, "", string(regData)); err != nil {
+			logrus.Errorf("❌ [ACME] 儲存註冊資訊失敗: %v", err)
+		}
+	}
+
+	return client, nil
+}
+
+// loadUser 依帳號資料建立 lego User，沒有私鑰時自動產生並保存
+func (s *AcmeService) loadUser(ctx context.Context, account *domain.AcmeAccount) (*domain.AcmeUser, error) {
+	user := &domain.AcmeUser{Email: account.Email}
+	regData := account.RegData
+
+	if account.PrivateKey != "" {
+		key, err := domain.ParsePrivateKey([]byte(account.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("ACME 私鑰解析失敗: %w", err)
+		}
+		user.PrivateKey = key
+	} else {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		user.PrivateKey = key
+
+		// 私鑰換了，舊的註冊資訊就不能用了 (資料庫中也一併清除，避免註冊失敗時留下新私鑰配舊註冊)
+		regData = ""
+		if err := s.Accounts.ReplaceKey(ctx, account.ID, string(certcrypto.PEMEncode(key))); err != nil {
+			return nil, fmt.Errorf("儲存 ACME 私鑰失敗: %w", err)
+		}
+	}
+
+	if regData != "" {
+		var reg registration.Resource
+		if err := json.Unmarshal([]byte(regData), &reg); err != nil {
+			logrus.Warnf("⚠️ [ACME] 註冊資訊解析失敗，將重新註冊: %v", err)
+		} else {
+			user.Registration = &reg
+		}
+	}
+
+	return user, nil
+}
+
+// migrateLegacyAccount 將舊版單一帳號設定 (NotificationSettings) 轉成預設帳號
+func (s *AcmeService) migrateLegacyAccount(ctx context.Context) (*domain.AcmeAccount, error) {
+	settings, err := s.Repo.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.AcmeEmail == "" {
+		return nil, fmt.Errorf("尚未設定 ACME 帳號")
+	}
+
+	account := &domain.AcmeAccount{
+		Name:         "Let's Encrypt",
+		Email:        settings.AcmeEmail,
+		DirectoryURL: domain.AcmeDirectoryLEProduction,
+		IsDefault:    true,
+		PrivateKey:   settings.AcmePrivateKey,
+		RegData:      settings.AcmeRegData,
+	}
+	if err := s.Accounts.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	logrus.Infof("🔁 [ACME] 已將舊版設定遷移為預設帳號 (%s)", account.Email)
+	return account, nil
+}
+
+// validateAccount 檢查帳號欄位，未填 Directory 時預設為 Let's Encrypt 正式環境
+func validateAccount(account *domain.AcmeAccount) error {
+	if account.Email == "" {
+		return fmt.Errorf("Email 不可為空")
+	}
+	if account.DirectoryURL == "" {
+		account.DirectoryURL = domain.AcmeDirectoryLEProduction
+	}
+	if u, err := url.Parse(account.DirectoryURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("Directory URL 必須是 https 網址")
+	}
+	if (account.EABKeyID == "") != (account.EABHMACKey == "") {
+		return fmt.Errorf("EAB Key ID 與 HMAC 必須同時填寫")
+	}
+	if account.RootCAPEM != "" {
+		if _, err := parseCertificateChain([]byte(account.RootCAPEM)); err != nil {
+			return fmt.Errorf("自訂根憑證格式錯誤: %w", err)
+		}
+	}
+	if account.Name == "" {
+		account.Name = account.DirectoryURL
+	}
+	return nil
+}
+
+// challengeTypeFor 決定驗證方式：由 Cloudflare 同步的域名走 DNS-01，其餘域名走 HTTP-01
+func challengeTypeFor(cert domain.SSLCertificate) string {
+	if cert.Provider == domain.ProviderCloudflare && cert.ZoneID != "" {
+		return domain.AcmeChallengeDNS01
+	}
+	return domain.AcmeChallengeHTTP01
+}
+
+// setupChallenge 依驗證方式設定 lego 的 Solver
+func (s *AcmeService) setupChallenge(ctx context.Context, client *lego.Client, cert domain.SSLCertificate, challengeType string) error {
+	if challengeType == domain.AcmeChallengeHTTP01 {
+		if strings.HasPrefix(cert.DomainName, "*.") {
+			return fmt.Errorf("萬用字元憑證只能使用 DNS-01 驗證 (需由 Cloudflare 管理)")
+		}
+		if s.HTTP01 == nil {
+			return fmt.Errorf("未啟用 HTTP-01 驗證")
+		}
+		if err := client.Challenge.SetHTTP01Provider(s.HTTP01); err != nil {
+			return fmt.Errorf("設定 HTTP-01 失敗: %w", err)
+		}
+		return nil
+	}
+
+	provider, err := s.newCloudflareProvider(ctx, cert)
+	if err != nil {
+		return err
+	}
+	if err := client.Challenge.SetDNS01Provider(provider, dns01.AddRecursiveNameservers([]string{"1.1.1.1:53", "8.8.8.8:53"})); err != nil {
+		return fmt.Errorf("設定 DNS-01 失敗: %w", err)
+	}
+	return nil
+}
+
+// newCloudflareProvider 使用域名所屬 Cloudflare 帳號的 Token 建立 DNS-01 Provider
+// 注意：Token 需具備 Zone.DNS Edit 權限
+func (s *AcmeService) newCloudflareProvider(ctx context.Context, cert domain.SSLCertificate) (*cloudflare.DNSProvider, error) {
+	if s.CFService == nil {
+		return nil, fmt.Errorf("未設定 Cloudflare API Token")
+	}
+	token, err := s.CFService.TokenFor(ctx, cert.CFAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := cloudflare.NewDefaultConfig()
+	cfg.AuthToken = token
+	cfg.PropagationTimeout = 5 * time.Minute
+
+	provider, err := cloudflare.NewDNSProviderConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Cloudflare DNS Provider 初始化失敗: %w", err)
+	}
+	return provider, nil
+}
+
+func (s *AcmeService) addStep(domainID, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job, ok := s.jobs[domainID]; ok {
+		job.Steps = append(job.Steps, IssueStep{Time: time.Now(), Message: msg})
+	}
+}
+
+func (s *AcmeService) finishJob(domainID string, mc *domain.ManagedCertificate, err error) {
+	s.mu.Lock()
+	job, ok := s.jobs[domainID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	job.FinishedAt = time.Now()
+
+	if err != nil {
+		job.Status = IssueStatusFailed
+		job.Error = err.Error()
+		job.Steps = append(job.Steps, IssueStep{Time: time.Now(), Message: "❌ " + err.Error()})
+		s.mu.Unlock()
+		logrus.Errorf("❌ [ACME] %s 簽發失敗: %v", job.DomainName, err)
+		return
+	}
+
+	job.Status = IssueStatusSuccess
+	job.NotAfter = mc.NotAfter
+	job.CertificateID = mc.ID.Hex()
+	domainName, accountName, notAfter := job.DomainName, job.AccountName, job.NotAfter
+	s.mu.Unlock()
+
+	details := fmt.Sprintf("📜 來源: ACME (%s)\n📅 <b>新到期日</b>: <code>%s</code>", accountName, notAfter.Format("2006-01-02"))
+	s.Notifier.NotifyOperation(context.Background(), EventRenew, domainName, details)
+}
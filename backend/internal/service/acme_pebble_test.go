@@ -0,0 +1,328 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 這些整合測試需要一個執行中的 Pebble (https://github.com/letsencrypt/pebble)，未設定 PEBBLE_DIRECTORY 時略過
+//
+//	PEBBLE_DIRECTORY   Directory URL，例如 https://localhost:14000/dir
+//	PEBBLE_ROOT_CA     Pebble API 的 TLS 根憑證 (test/certs/pebble.minica.pem)
+//	PEBBLE_MANAGEMENT  管理介面，例如 https://localhost:15000 (選填，用來驗證簽出的憑證鏈)
+//	PEBBLE_DOMAIN      要簽發的域名 (預設 pebble.cert-manager.test)，需由 pebble-challtestsrv 解析到本機，
+//	                   或以 PEBBLE_VA_ALWAYS_VALID=1 啟動 Pebble
+//	PEBBLE_HTTP01_ADDR HTTP-01 Listener 位址 (預設 :5002，對應 Pebble 的 httpPort)
+//	PEBBLE_EAB_KID / PEBBLE_EAB_HMAC  Pebble 設定的 externalAccountMACKeys 其中一組 (選填，EAB 測試用)
+
+type pebbleEnv struct {
+	directory  string
+	rootCA     string
+	management string
+	domain     string
+}
+
+func pebbleSetup(t *testing.T) pebbleEnv {
+	t.Helper()
+
+	env := pebbleEnv{
+		directory:  os.Getenv("PEBBLE_DIRECTORY"),
+		management: os.Getenv("PEBBLE_MANAGEMENT"),
+		domain:     os.Getenv("PEBBLE_DOMAIN"),
+	}
+	if env.directory == "" {
+		t.Skip("PEBBLE_DIRECTORY not set; skipping Pebble integration test")
+	}
+	if env.domain == "" {
+		env.domain = "pebble.cert-manager.test"
+	}
+
+	caFile := os.Getenv("PEBBLE_ROOT_CA")
+	if caFile == "" {
+		t.Fatal("PEBBLE_ROOT_CA must point to Pebble's TLS root (pebble.minica.pem)")
+	}
+	pemData, err := os.ReadFile(caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.rootCA = string(pemData)
+	return env
+}
+
+// newPebbleService 建立只依賴記憶體 Repository 的 AcmeService，並啟動 HTTP-01 Listener
+func newPebbleService(t *testing.T) (*AcmeService, *memAcmeAccounts) {
+	t.Helper()
+
+	accounts := &memAcmeAccounts{}
+	http01 := NewHTTP01Provider(&memAcmeChallenges{})
+
+	addr := os.Getenv("PEBBLE_HTTP01_ADDR")
+	if addr == "" {
+		addr = ":5002"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("HTTP-01 listener on %s: %v", addr, err)
+	}
+	srv := &http.Server{Handler: http01, ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	return NewAcmeService(nil, accounts, nil, http01, nil, nil, nil), accounts
+}
+
+func pebbleAccount(env pebbleEnv) *domain.AcmeAccount {
+	return &domain.AcmeAccount{
+		ID:           primitive.NewObjectID(),
+		Name:         "Pebble",
+		Email:        "admin@cert-manager.test",
+		DirectoryURL: env.directory,
+		RootCAPEM:    env.rootCA,
+	}
+}
+
+func TestPebbleIssueWithCustomDirectoryAndRootCA(t *testing.T) {
+	env := pebbleSetup(t)
+	svc, accounts := newPebbleService(t)
+	ctx := context.Background()
+
+	account := pebbleAccount(env)
+	cert := domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: env.domain}
+
+	res, err := svc.ObtainCertificate(ctx, cert, account, "", func(msg string) { t.Log(msg) })
+	if err != nil {
+		t.Fatalf("ObtainCertificate: %v", err)
+	}
+	chain := checkIssued(t, res.Certificate, res.PrivateKey, env.domain)
+	if env.management != "" {
+		verifyPebbleChain(t, env, chain)
+	}
+
+	// 私鑰與註冊資訊必須寫回帳號，下次簽發直接沿用
+	saved := accounts.keyData(account.ID)
+	if saved.PrivateKey == "" || saved.RegData == "" {
+		t.Fatalf("account key/registration not persisted: %+v", saved)
+	}
+	account.PrivateKey, account.RegData = saved.PrivateKey, saved.RegData
+	writes := accounts.writeCount()
+	if _, err := svc.ObtainCertificate(ctx, cert, account, "", nil); err != nil {
+		t.Fatalf("ObtainCertificate with stored registration: %v", err)
+	}
+	if accounts.writeCount() != writes {
+		t.Error("stored key/registration was not reused")
+	}
+}
+
+func TestPebbleRejectsDirectoryWithoutRootCA(t *testing.T) {
+	env := pebbleSetup(t)
+	svc, _ := newPebbleService(t)
+
+	account := pebbleAccount(env)
+	account.RootCAPEM = ""
+	cert := domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: env.domain}
+
+	// Pebble 的 minica 不在系統信任庫，未設定自訂根憑證時必須連不上
+	if _, err := svc.ObtainCertificate(context.Background(), cert, account, "", nil); err == nil {
+		t.Fatal("issued against Pebble without trusting its root CA")
+	}
+}
+
+func TestPebbleIssueWithEAB(t *testing.T) {
+	env := pebbleSetup(t)
+	kid, hmac := os.Getenv("PEBBLE_EAB_KID"), os.Getenv("PEBBLE_EAB_HMAC")
+	if kid == "" || hmac == "" {
+		t.Skip("PEBBLE_EAB_KID / PEBBLE_EAB_HMAC not set; skipping EAB test")
+	}
+	svc, accounts := newPebbleService(t)
+	ctx := context.Background()
+	cert := domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: env.domain}
+
+	// Pebble 設定了 EAB 金鑰時，沒帶 EAB 的帳號應在註冊前就被擋下
+	plain := pebbleAccount(env)
+	if _, err := svc.ObtainCertificate(ctx, cert, plain, "", nil); err == nil || !strings.Contains(err.Error(), "External Account Binding") {
+		t.Errorf("registration without EAB: err = %v, want External Account Binding error", err)
+	}
+
+	account := pebbleAccount(env)
+	account.EABKeyID, account.EABHMACKey = kid, hmac
+	res, err := svc.ObtainCertificate(ctx, cert, account, "", nil)
+	if err != nil {
+		t.Fatalf("ObtainCertificate with EAB: %v", err)
+	}
+	checkIssued(t, res.Certificate, res.PrivateKey, env.domain)
+	if accounts.keyData(account.ID).RegData == "" {
+		t.Error("EAB registration not persisted")
+	}
+}
+
+// checkIssued 確認憑證鏈的第一張是給指定域名，且私鑰與其公鑰相符
+func checkIssued(t *testing.T, certPEM, keyPEM []byte, domainName string) []*x509.Certificate {
+	t.Helper()
+
+	var chain []*x509.Certificate
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain = append(chain, c)
+	}
+	if len(chain) < 2 {
+		t.Fatalf("bundle has %d certificates, want leaf + issuer", len(chain))
+	}
+	if err := chain[0].VerifyHostname(domainName); err != nil {
+		t.Errorf("leaf: %v", err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Errorf("private key does not match leaf: %v", err)
+	}
+	return chain
+}
+
+// verifyPebbleChain 從管理介面取得 Pebble 的簽發根憑證，驗證整條鏈
+func verifyPebbleChain(t *testing.T, env pebbleEnv, chain []*x509.Certificate) {
+	t.Helper()
+
+	tlsRoots := x509.NewCertPool()
+	tlsRoots.AppendCertsFromPEM([]byte(env.rootCA))
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: tlsRoots}},
+	}
+	resp, err := client.Get(strings.TrimRight(env.management, "/") + "/roots/0")
+	if err != nil {
+		t.Fatalf("fetch Pebble root: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(body) {
+		t.Fatalf("Pebble root is not PEM: %q", body)
+	}
+	inter := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		inter.AddCert(c)
+	}
+	if _, err := chain[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: inter, DNSName: env.domain}); err != nil {
+		t.Errorf("issued chain does not verify against Pebble root: %v", err)
+	}
+}
+
+// =============================================================================
+// In-memory Repositories
+// =============================================================================
+
+// memAcmeAccounts 只實作簽發流程會用到的 UpdateKeyData / ReplaceKey，行為與 Mongo 實作相同
+type memAcmeAccounts struct {
+	repository.AcmeAccountRepository
+
+	mu     sync.Mutex
+	data   map[primitive.ObjectID]domain.AcmeAccount
+	writes int
+}
+
+func (m *memAcmeAccounts) UpdateKeyData(_ context.Context, id primitive.ObjectID, privateKey, regData string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		m.data = make(map[primitive.ObjectID]domain.AcmeAccount)
+	}
+	if privateKey == "" && regData == "" {
+		return nil
+	}
+	a := m.data[id]
+	if privateKey != "" {
+		a.PrivateKey = privateKey
+	}
+	if regData != "" {
+		a.RegData = regData
+	}
+	m.data[id] = a
+	m.writes++
+	return nil
+}
+
+func (m *memAcmeAccounts) ReplaceKey(_ context.Context, id primitive.ObjectID, privateKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		m.data = make(map[primitive.ObjectID]domain.AcmeAccount)
+	}
+	a := m.data[id]
+	a.PrivateKey, a.RegData = privateKey, ""
+	m.data[id] = a
+	m.writes++
+	return nil
+}
+
+func (m *memAcmeAccounts) keyData(id primitive.ObjectID) domain.AcmeAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[id]
+}
+
+func (m *memAcmeAccounts) writeCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writes
+}
+
+type memAcmeChallenges struct {
+	mu     sync.Mutex
+	tokens map[string]domain.AcmeChallenge
+}
+
+func (m *memAcmeChallenges) Save(_ context.Context, challenge domain.AcmeChallenge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tokens == nil {
+		m.tokens = make(map[string]domain.AcmeChallenge)
+	}
+	m.tokens[challenge.Token] = challenge
+	return nil
+}
+
+func (m *memAcmeChallenges) GetByToken(_ context.Context, token string) (*domain.AcmeChallenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	challenge, ok := m.tokens[token]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &challenge, nil
+}
+
+func (m *memAcmeChallenges) Delete(_ context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, token)
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLoadUserNewKeyClearsStoredRegistration(t *testing.T) {
+	id := primitive.NewObjectID()
+	stale := `{"uri":"https://acme.example/acct/1"}`
+	accounts := &memAcmeAccounts{data: map[primitive.ObjectID]domain.AcmeAccount{
+		id: {ID: id, RegData: stale},
+	}}
+	svc := NewAcmeService(nil, accounts, nil, nil, nil, nil, nil)
+
+	// 私鑰不見了 (例如被手動清掉) 但註冊資訊還在
+	user, err := svc.loadUser(context.Background(), &domain.AcmeAccount{ID: id, Email: "admin@example.com", RegData: stale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PrivateKey == nil || user.Registration != nil {
+		t.Fatalf("user = %+v, want a new key without registration", user)
+	}
+
+	// 新私鑰不能配上舊的註冊資訊，否則註冊失敗時下次會拿舊註冊簽發
+	saved := accounts.keyData(id)
+	if saved.PrivateKey == "" {
+		t.Error("new private key not persisted")
+	}
+	if saved.RegData != "" {
+		t.Errorf("stale registration kept with the new key: %q", saved.RegData)
+	}
+}
+
+func TestRenewalDueUsesLatestStoredCertificate(t *testing.T) {
+	days := func(n int) time.Time { return time.Now().Add(time.Duration(n)*24*time.Hour + time.Hour) }
+	served := func(n int) domain.SSLCertificate {
+		return domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: "www.example.com", NotAfter: days(n), DaysRemaining: n}
+	}
+
+	tests := []struct {
+		name   string
+		cert   domain.SSLCertificate
+		latest *domain.ManagedCertificate
+		due    bool
+	}{
+		// 新憑證已簽發但尚未部署，線上仍是舊憑證：不能每次排程都再簽一張
+		{"renewed but not deployed", served(5), &domain.ManagedCertificate{Source: domain.CertSourceAcme, NotAfter: days(89)}, false},
+		{"no stored certificate", served(5), nil, true},
+		{"stored certificate expiring", served(5), &domain.ManagedCertificate{Source: domain.CertSourceAcme, NotAfter: days(5)}, true},
+		{"stored certificate revoked", served(5), &domain.ManagedCertificate{Source: domain.CertSourceAcme, NotAfter: days(89), RevokedAt: time.Now()}, true},
+		// 線上已換成更新的憑證 (例如在別處簽發)
+		{"served certificate newer", served(60), &domain.ManagedCertificate{Source: domain.CertSourceUpload, NotAfter: days(5)}, false},
+		{"never scanned", domain.SSLCertificate{ID: primitive.NewObjectID()}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &AcmeService{Store: &CertStoreService{Repo: &memLatestCert{latest: tt.latest}}}
+			if due, reason := svc.RenewalDue(context.Background(), tt.cert, 30); due != tt.due {
+				t.Errorf("RenewalDue = %v (%q), want %v", due, reason, tt.due)
+			}
+		})
+	}
+}
+
+// memLatestCert 只回傳固定的最新憑證 (CertPEM 為空，ARI 查詢會失敗而退回天數判斷)
+type memLatestCert struct {
+	repository.CertificateRepository
+	latest *domain.ManagedCertificate
+}
+
+func (m *memLatestCert) GetLatestByDomainID(context.Context, primitive.ObjectID) (*domain.ManagedCertificate, error) {
+	if m.latest == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return m.latest, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+const axfrTimeout = 30 * time.Second
+
+// AXFRService 以 Zone Transfer (AXFR, 可搭配 TSIG) 作為同步來源 (實作 DNSProviderSource)
+// 適用於 BIND / Knot / PowerDNS 等自建 DNS，AXFR 無法列出 Zone，需在設定檔指定
+type AXFRService struct {
+	Servers []conf.AXFRServerConfig
+}
+
+func NewAXFRService(cfg conf.AXFRConfig) *AXFRService {
+	return &AXFRService{Servers: cfg.Servers}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Providers 每台設定的 DNS Server 一個 Provider (實作 DNSProviderSource)
+func (s *AXFRService) Providers(ctx context.Context) ([]DNSProvider, error) {
+	var providers []DNSProvider
+	for _, server := range s.Servers {
+		if server.Address == "" || len(server.Zones) == 0 {
+			logrus.Warnf("⚠️ [AXFR] 略過設定不完整的 Server: %s", server.Name)
+			continue
+		}
+		providers = append(providers, &axfrDNSProvider{server: server})
+	}
+	return providers, nil
+}
+
+// RecordSyncResult AXFR 設定寫在設定檔，只記錄日誌 (實作 DNSProviderSource)
+func (s *AXFRService) RecordSyncResult(ctx context.Context, p DNSProvider, count int, err error) {
+	if err != nil {
+		logrus.Warnf("⚠️ [AXFR] %s 同步失敗: %v", p.Label(), err)
+		return
+	}
+	logrus.Infof("✅ [AXFR] %s 同步完成，共 %d 筆域名", p.Label(), count)
+}
+
+// =============================================================================
+// DNSProvider 實作
+// =============================================================================
+
+type axfrDNSProvider struct {
+	server conf.AXFRServerConfig
+}
+
+func (p *axfrDNSProvider) Name() string {
+	return domain.ProviderAXFR
+}
+
+func (p *axfrDNSProvider) Label() string {
+	name := p.server.Name
+	if name == "" {
+		name = p.server.Address
+	}
+	return domain.ProviderAXFR + "/" + name
+}
+
+// ListZones 直接回傳設定檔中的 Zone (Zone ID 即 Zone 名稱)
+func (p *axfrDNSProvider) ListZones(ctx context.Context) ([]DNSZone, error) {
+	zones := make([]DNSZone, 0, len(p.server.Zones))
+	for _, z := range p.server.Zones {
+		name := strings.TrimSuffix(strings.TrimSpace(z), ".")
+		zones = append(zones, DNSZone{ID: name, Name: name})
+	}
+	return zones, nil
+}
+
+func (p *axfrDNSProvider) ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error) {
+	rrs, err := p.transfer(ctx, zone.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	records := rrsToRecords(rrs)
+	logrus.Infof("   ✅ [AXFR] Zone %s 傳輸完成，共 %d 筆紀錄", zone.Name, len(records))
+	return records, nil
+}
+
+// GetRecord AXFR 無法查詢單筆紀錄，只能重新傳輸整個 Zone
+func (p *axfrDNSProvider) GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	records, err := p.ListRecords(ctx, DNSZone{ID: zoneID, Name: zoneID})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	for _, record := range records {
+		if record.ID == recordID {
+			return record, nil
+		}
+	}
+	return DNSRecord{}, fmt.Errorf("%w: AXFR 紀錄 %s", ErrRecordNotFound, recordID)
+}
+
+// Owns 資料庫沒有記錄來源 Server，以 Zone 是否由此 Server 提供來判斷
+func (p *axfrDNSProvider) Owns(d domain.SSLCertificate) bool {
+	if d.Provider != domain.ProviderAXFR {
+		return false
+	}
+	for _, z := range p.server.Zones {
+		if strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(z), "."), d.ZoneName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *axfrDNSProvider) Annotate(d *domain.SSLCertificate) {}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// transfer 執行 AXFR，有設定 TSIG 時簽署請求並驗證回應
+func (p *axfrDNSProvider) transfer(ctx context.Context, zoneName string) ([]dns.RR, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	address := p.server.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(zoneName))
+	t := &dns.Transfer{DialTimeout: axfrTimeout, ReadTimeout: axfrTimeout, WriteTimeout: axfrTimeout}
+	if p.server.TSIGName != "" {
+		keyName := dns.Fqdn(p.server.TSIGName)
+		msg.SetTsig(keyName, tsigAlgorithm(p.server.TSIGAlgorithm), 300, time.Now().Unix())
+		t.TsigSecret = map[string]string{keyName: p.server.TSIGSecret}
+	}
+
+	// Transfer 不支援 Context，以 Timeout 控制；通道必須讀完，否則傳輸 goroutine 會卡住
+	envelopes, err := t.In(msg, address)
+	if err != nil {
+		return nil, fmt.Errorf("AXFR 連線失敗 (%s): %w", address, err)
+	}
+
+	var rrs []dns.RR
+	for env := range envelopes {
+		if env.Error != nil {
+			return nil, fmt.Errorf("AXFR 傳輸失敗 (%s %s): %w", address, zoneName, env.Error)
+		}
+		rrs = append(rrs, env.RR...)
+	}
+	if len(rrs) == 0 {
+		return nil, fmt.Errorf("AXFR 沒有回傳任何紀錄 (%s %s)，請確認 Server 允許傳輸", address, zoneName)
+	}
+	return rrs, nil
+}
+
+// rrsToRecords 取出 A / AAAA / CNAME，同名同類型的紀錄合併成一筆 (與 RRSet 的概念一致)
+// AXFR 與 Zone File 匯入共用
+func rrsToRecords(rrs []dns.RR) []DNSRecord {
+	var records []DNSRecord
+	values := make(map[string][]string)
+	for _, rr := range rrs {
+		name := strings.TrimSuffix(rr.Header().Name, ".")
+		var rrType, value string
+		switch v := rr.(type) {
+		case *dns.A:
+			rrType, value = "A", v.A.String()
+		case *dns.AAAA:
+			rrType, value = "AAAA", v.AAAA.String()
+		case *dns.CNAME:
+			rrType, value = "CNAME", strings.TrimSuffix(v.Target, ".")
+		default:
+			continue
+		}
+
+		id := name + "|" + rrType
+		if _, ok := values[id]; !ok {
+			records = append(records, DNSRecord{ID: id, Name: name, Type: rrType})
+		}
+		values[id] = append(values[id], value)
+	}
+	// 排序後再合併，避免傳輸順序不同被誤判為 Origin 變更
+	for i := range records {
+		sort.Strings(values[records[i].ID])
+		records[i].Content = strings.Join(values[records[i].ID], ",")
+	}
+	return records
+}
+
+// tsigAlgorithm 將設定值轉成 miekg/dns 的演算法名稱，預設 hmac-sha256
+func tsigAlgorithm(name string) string {
+	switch strings.ToLower(strings.TrimSuffix(name, ".")) {
+	case "hmac-sha1":
+		return dns.HmacSHA1
+	case "hmac-sha512":
+		return dns.HmacSHA512
+	case "hmac-md5", "hmac-md5.sig-alg.reg.int":
+		return dns.HmacMD5
+	default:
+		return dns.HmacSHA256
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"bytes"
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/keystore"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 支援的下載格式
+const (
+	ExportLeaf      = "leaf"
+	ExportChain     = "chain"
+	ExportFullChain = "fullchain"
+	ExportKey       = "key"
+	ExportPKCS12    = "p12"
+	ExportJKS       = "jks"
+)
+
+// 自動產生的金鑰長度
+const encryptionKeySize = 32
+
+// ExportedFile 匯出結果
+type ExportedFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// CertStoreService 負責憑證的保存 (私鑰加密) 與匯出
+type CertStoreService struct {
+	Repo       repository.CertificateRepository
+	DomainRepo repository.DomainRepository
+	aead       cipher.AEAD
+}
+
+// NewCertStoreService encryptionKey 不可為空 (由 LoadEncryptionKey 取得)
+func NewCertStoreService(repo repository.CertificateRepository, domainRepo repository.DomainRepository, encryptionKey string) *CertStoreService {
+	if encryptionKey == "" {
+		logrus.Fatal("CertStore 初始化失敗: 未設定加密金鑰 (security.encryption_key / encryption_key_file)")
+	}
+
+	aead, err := newKeyAEAD(encryptionKey)
+	if err != nil {
+		logrus.Fatalf("CertStore 初始化失敗: %v", err)
+	}
+	return &CertStoreService{Repo: repo, DomainRepo: domainRepo, aead: aead}
+}
+
+// LoadEncryptionKey 取得加密私鑰用的金鑰：優先使用 encryption_key，否則讀取 encryption_key_file，
+// 檔案不存在時產生 32 bytes 隨機金鑰並以 0600 權限寫入。兩者都未設定時回傳錯誤，不會退回任何內建金鑰
+func LoadEncryptionKey(cfg conf.SecurityConfig) (string, error) {
+	if key := strings.TrimSpace(cfg.EncryptionKey); key != "" {
+		return key, nil
+	}
+	if cfg.EncryptionKeyFile == "" {
+		return "", errors.New("未設定 security.encryption_key 或 security.encryption_key_file，無法加密保存私鑰")
+	}
+
+	data, err := os.ReadFile(cfg.EncryptionKeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return generateEncryptionKeyFile(cfg.EncryptionKeyFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("讀取金鑰檔 %s 失敗: %w", cfg.EncryptionKeyFile, err)
+	}
+	if info, err := os.Stat(cfg.EncryptionKeyFile); err == nil && info.Mode().Perm()&0o077 != 0 {
+		logrus.Warnf("⚠️ [CertStore] 金鑰檔 %s 的權限為 %o，建議改為 0600", cfg.EncryptionKeyFile, info.Mode().Perm())
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("金鑰檔 %s 是空的", cfg.EncryptionKeyFile)
+	}
+	return key, nil
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Save 保存一張憑證 (certPEM 可包含完整鏈，第一張視為 Leaf)
+func (s *CertStoreService) Save(ctx context.Context, owner domain.SSLCertificate, source string, certPEM, keyPEM []byte) (*domain.ManagedCertificate, error) {
+	return s.save(ctx, owner, &domain.ManagedCertificate{Source: source}, certPEM, keyPEM)
+}
+
+// SaveAcme 保存 ACME 簽發的憑證，並記錄使用的帳號
+func (s *CertStoreService) SaveAcme(ctx context.Context, owner domain.SSLCertificate, accountID primitive.ObjectID, certPEM, keyPEM []byte) (*domain.ManagedCertificate, error) {
+	return s.save(ctx, owner, &domain.ManagedCertificate{Source: domain.CertSourceAcme, AcmeAccountID: accountID}, certPEM, keyPEM)
+}
+
+// SaveCloudflareOrigin 保存 Cloudflare Origin CA 簽發的憑證
+func (s *CertStoreService) SaveCloudflareOrigin(ctx context.Context, owner domain.SSLCertificate, cfCertID string, certPEM, keyPEM []byte) (*domain.ManagedCertificate, error) {
+	return s.save(ctx, owner, &domain.ManagedCertificate{Source: domain.CertSourceOriginCA, CFOriginCertID: cfCertID}, certPEM, keyPEM)
+}
+
+// PrivateKey 解密並解析憑證的私鑰
+func (s *CertStoreService) PrivateKey(mc *domain.ManagedCertificate) (crypto.PrivateKey, []byte, error) {
+	keyPEM, err := s.decrypt(mc.EncryptedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, keyPEM, nil
+}
+
+// Export 依格式匯出憑證 (p12/jks 需要密碼)
+func (s *CertStoreService) Export(mc *domain.ManagedCertificate, format, password string) (*ExportedFile, error) {
+	base := strings.ReplaceAll(mc.DomainName, "*", "wildcard")
+
+	switch format {
+	case ExportLeaf:
+		return &ExportedFile{Filename: base + ".crt", ContentType: "application/x-pem-file", Data: []byte(mc.CertPEM)}, nil
+	case ExportChain:
+		return &ExportedFile{Filename: base + ".chain.crt", ContentType: "application/x-pem-file", Data: []byte(mc.ChainPEM)}, nil
+	case ExportFullChain:
+		return &ExportedFile{Filename: base + ".fullchain.crt", ContentType: "application/x-pem-file", Data: []byte(mc.CertPEM + mc.ChainPEM)}, nil
+	case ExportKey:
+		_, keyPEM, err := s.PrivateKey(mc)
+		if err != nil {
+			return nil, err
+		}
+		return &ExportedFile{Filename: base + ".key", ContentType: "application/x-pem-file", Data: keyPEM}, nil
+	case ExportPKCS12, ExportJKS:
+		if password == "" {
+			return nil, errors.New("匯出 PKCS#12 / JKS 需要設定密碼")
+		}
+		key, _, err := s.PrivateKey(mc)
+		if err != nil {
+			return nil, err
+		}
+		certs, err := parseCertificateChain([]byte(mc.CertPEM + mc.ChainPEM))
+		if err != nil {
+			return nil, err
+		}
+
+		if format == ExportPKCS12 {
+			data, err := keystore.EncodePKCS12(key, certs, mc.DomainName, password)
+			if err != nil {
+				return nil, err
+			}
+			return &ExportedFile{Filename: base + ".p12", ContentType: "application/x-pkcs12", Data: data}, nil
+		}
+		data, err := keystore.EncodeJKS(mc.DomainName, key, certs, password)
+		if err != nil {
+			return nil, err
+		}
+		return &ExportedFile{Filename: base + ".jks", ContentType: "application/octet-stream", Data: data}, nil
+	default:
+		return nil, fmt.Errorf("不支援的格式: %s", format)
+	}
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// save 解析並驗證憑證與私鑰，補齊 mc 的其餘欄位後寫入
+func (s *CertStoreService) save(ctx context.Context, owner domain.SSLCertificate, mc *domain.ManagedCertificate, certPEM, keyPEM []byte) (*domain.ManagedCertificate, error) {
+	certs, err := parseCertificateChain(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	leaf := certs[0]
+
+	key, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyMatch(leaf, key); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := s.encrypt(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain bytes.Buffer
+	for _, c := range certs[1:] {
+		_ = pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+
+	fingerprint := sha256.Sum256(leaf.Raw)
+	mc.DomainID = owner.ID
+	mc.DomainName = owner.DomainName
+	mc.CertPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw}))
+	mc.ChainPEM = chain.String()
+	mc.EncryptedKey = encryptedKey
+	mc.Issuer = certIssuerName(leaf)
+	mc.SerialNumber = fmt.Sprintf("%X", leaf.SerialNumber)
+	mc.Fingerprint = hex.EncodeToString(fingerprint[:])
+	mc.SANs = leaf.DNSNames
+	mc.NotBefore = leaf.NotBefore
+	mc.NotAfter = leaf.NotAfter
+
+	if err := s.Repo.Create(ctx, mc); err != nil {
+		return nil, err
+	}
+	logrus.Infof("💾 [CertStore] 已保存 %s 的憑證 (來源: %s, 到期: %s)", owner.DomainName, mc.Source, leaf.NotAfter.Format("2006-01-02"))
+	return mc, nil
+}
+
+func (s *CertStoreService) encrypt(plain []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, plain, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *CertStoreService) decrypt(encoded string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, errors.New("私鑰資料損毀")
+	}
+	nonce, data := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("私鑰解密失敗 (encryption_key 是否變更?): %w", err)
+	}
+	return plain, nil
+}
+
+// newKeyAEAD 任意長度的字串都先雜湊成 32 bytes，作為 AES-256-GCM 金鑰
+func newKeyAEAD(encryptionKey string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// generateEncryptionKeyFile 產生隨機金鑰並寫入 (O_EXCL：多個實例同時啟動時只有一個會寫入)
+func generateEncryptionKeyFile(path string) (string, error) {
+	raw := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("建立金鑰目錄失敗: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return LoadEncryptionKey(conf.SecurityConfig{EncryptionKeyFile: path})
+	}
+	if err != nil {
+		return "", fmt.Errorf("建立金鑰檔 %s 失敗: %w", path, err)
+	}
+	if _, err := f.WriteString(key + "\n"); err != nil {
+		f.Close()
+		return "", fmt.Errorf("寫入金鑰檔 %s 失敗: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("寫入金鑰檔 %s 失敗: %w", path, err)
+	}
+	logrus.Warnf("🔑 [CertStore] 已產生新的加密金鑰 %s，請妥善備份 (遺失後無法解密已保存的私鑰)", path)
+	return key, nil
+}
+
+// parseCertificateChain 解析 PEM 中所有的 CERTIFICATE 區塊
+func parseCertificateChain(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("憑證格式錯誤: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("找不到 PEM 格式的憑證")
+	}
+	return certs, nil
+}
+
+// parsePrivateKeyPEM 支援 PKCS#8 / PKCS#1 (RSA) / SEC1 (EC)
+func parsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("找不到 PEM 格式的私鑰")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("無法解析私鑰 (支援 PKCS#8 / RSA / EC)")
+}
+
+// checkKeyMatch 確認私鑰與 Leaf 的公鑰相符
+func checkKeyMatch(leaf *x509.Certificate, key crypto.PrivateKey) error {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return errors.New("不支援的私鑰類型")
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(leaf.PublicKey) {
+		return errors.New("私鑰與憑證不符")
+	}
+	return nil
+}
+
+func certIssuerName(cert *x509.Certificate) string {
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	if len(cert.Issuer.Organization) > 0 {
+		return cert.Issuer.Organization[0]
+	}
+	return ""
+}
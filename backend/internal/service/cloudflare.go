@@ -4,199 +4,173 @@ import (
 	"cert-manager/internal/domain"
 	"cert-manager/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cloudflare/cloudflare-go"
-	"github.com/likexian/whois"
-	whoisparser "github.com/likexian/whois-parser"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // 常數定義：方便統一調整參數
 const (
-	cfPageSize       = 100
-	cfRateLimitSleep = 1000 * time.Millisecond // 避免觸發 API 限制
+	cfPageSize = 100
+
+	// 稽核紀錄查詢 (找出 DNS 紀錄變更者)
+	cfAuditLookback = 7 * 24 * time.Hour // 帳號尚未同步過時往回查詢的範圍
+	cfAuditMargin   = 5 * time.Minute    // 上次同步時間往前多抓一點，避免邊界上的變更漏掉
+	cfAuditPageSize = 100
+	cfAuditMaxPages = 5
 )
 
 type CloudflareService struct {
-	APIToken string
+	APIToken string // 設定檔中的 Token，只在尚未建立任何帳號時遷移成第一個帳號
 	Repo     repository.DomainRepository
+	Accounts repository.CloudflareAccountRepository
+	Store    *CertStoreService // 帳號 Token 與私鑰使用同一把金鑰加密保存
+
+	migrateMu sync.Mutex
 }
 
-func NewCloudflareService(token string, repo repository.DomainRepository) *CloudflareService {
-	return &CloudflareService{APIToken: token, Repo: repo}
+func NewCloudflareService(token string, repo repository.DomainRepository, accounts repository.CloudflareAccountRepository, store *CertStoreService) *CloudflareService {
+	return &CloudflareService{APIToken: token, Repo: repo, Accounts: accounts, Store: store}
 }
 
 // =============================================================================
 // Public Methods (業務入口)
 // =============================================================================
 
-// FetchDomains 從 Cloudflare 抓取所有 Zone 下的 A 紀錄和 CNAME，並結合 WHOIS 資訊
-// func (s *CloudflareService) FetchDomains(ctx context.Context, outputChan chan<- domain.SSLCertificate) error {
-// 	logrus.Info("🚀 [Cloudflare] 開始執行 FetchDomains (串流模式)...")
-
-// 	api, err := s.getAPIClient()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	// 1. 獲取所有 Zones
-// 	zones, err := s.listAllZones(ctx, api)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	logrus.Infof("✅ [Cloudflare] 取得 Zone 列表成功，共 %d 個 Zone", len(zones))
-
-// 	// var allDomains []domain.SSLCertificate
-
-// 	// 2. 遍歷每個 Zone 進行處理
-// 	for i, zone := range zones {
-// 		// 每次處理完一個 Zone，休息一下避免 Rate Limit
-// 		if i > 0 {
-// 			time.Sleep(cfRateLimitSleep)
-// 		}
-
-// 		logrus.Infof("🔍 [%d/%d] 正在掃描 Zone: %s (ID: %s)", i+1, len(zones), zone.Name, zone.ID)
-
-// 		// 處理單一 Zone 的所有邏輯 (Whois + DNS Records)
-// 		zoneDomains := s.processZone(ctx, api, zone)
-// 		// [關鍵] 將抓到的域名立即推送到通道
-// 		for _, d := range zoneDomains {
-// 			select {
-// 			case <-ctx.Done():
-// 				return ctx.Err()
-// 			case outputChan <- d: // <--- 這裡！一抓到就丟給 CronService 去掃描
-// 			}
-// 		}
-// 		// allDomains = append(allDomains, zoneDomains...)
-// 	}
-// 	logrus.Info("🏁 [Cloudflare] 所有 Zone 抓取完畢，關閉資料通道")
-// 	// logrus.Infof("🏁 [Cloudflare] 掃描完成，總計處理 %d 個子域名", len(allDomains))
-// 	return nil
-// }
-
-func (s *CloudflareService) FetchDomains(ctx context.Context, outputChan chan<- domain.SSLCertificate) error {
-	logrus.Info("🚀 [Cloudflare] 開始執行 FetchDomains (串流模式)...")
-
-	api, err := s.getAPIClient()
+// ListAccounts 列出所有 Cloudflare 帳號 (Token 仍為加密狀態)
+// 尚未建立任何帳號但設定檔有 api_token 時，自動遷移成第一個帳號；舊版明文保存的 Token 在此改為加密保存
+func (s *CloudflareService) ListAccounts(ctx context.Context) ([]domain.CloudflareAccount, error) {
+	s.migrateMu.Lock()
+	defer s.migrateMu.Unlock()
+
+	accounts, err := s.Accounts.List(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	zoneID := "da4987ebbc2c7fd3b1e4a15f0d04320d"
-	// 1. 獲取所有 Zones
-	zone, err := api.ZoneDetails(ctx, zoneID)
-	if err != nil {
-		return fmt.Errorf("獲取 Zone %s 失敗: %w", zoneID, err)
+	for i := range accounts {
+		if err := s.migrateLegacyToken(ctx, &accounts[i]); err != nil {
+			return nil, err
+		}
+	}
+	if len(accounts) > 0 || s.APIToken == "" {
+		return accounts, nil
 	}
 
-	// var allDomains []domain.SSLCertificate
-
-	// 2. 遍歷每個 Zone 進行處理
-	zoneDomains := s.processZone(ctx, api, zone)
-	for _, d := range zoneDomains {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case outputChan <- d: // <--- 這裡！一抓到就丟給 CronService 去掃描
-		}
+	account := domain.CloudflareAccount{
+		Name:      "Default",
+		APIToken:  s.APIToken,
+		TokenHint: tokenHint(s.APIToken),
 	}
-	logrus.Info("🏁 [Cloudflare] 所有 Zone 抓取完畢，關閉資料通道")
-	// logrus.Infof("🏁 [Cloudflare] 掃描完成，總計處理 %d 個子域名", len(allDomains))
-	return nil
+	if err := s.sealToken(&account); err != nil {
+		return nil, err
+	}
+	if err := s.Accounts.Create(ctx, &account); err != nil {
+		return nil, fmt.Errorf("遷移設定檔的 Cloudflare Token 失敗: %w", err)
+	}
+	logrus.Info("🔁 [Cloudflare] 已將設定檔的 api_token 遷移為帳號 \"Default\"")
+	return []domain.CloudflareAccount{account}, nil
 }
 
-// GetSingleRecord 從 Cloudflare 獲取單一域名的最新設定 (用於比對更新)
-func (s *CloudflareService) GetSingleRecord(ctx context.Context, zoneID, recordID string) (domain.SSLCertificate, error) {
-	api, err := s.getAPIClient()
-	if err != nil {
-		return domain.SSLCertificate{}, err
+// CreateAccount 驗證 Token 後新增帳號
+func (s *CloudflareService) CreateAccount(ctx context.Context, account *domain.CloudflareAccount) error {
+	if account.Name == "" {
+		return fmt.Errorf("請填寫帳號名稱")
 	}
-
-	record, err := api.GetDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
-	if err != nil {
-		return domain.SSLCertificate{}, err
+	if err := s.verifyToken(ctx, account.APIToken); err != nil {
+		return err
 	}
-
-	// 回傳簡化的物件，僅包含需要比對的欄位
-	return domain.SSLCertificate{
-		CFOriginValue: record.Content,
-		CFRecordType:  record.Type,
-		IsProxied:     *record.Proxied,
-	}, nil
+	// 先確保設定檔的 Token 已遷移，避免新帳號建立後舊 Token 被忽略
+	if _, err := s.ListAccounts(ctx); err != nil {
+		return err
+	}
+	account.TokenHint = tokenHint(account.APIToken)
+	if err := s.sealToken(account); err != nil {
+		return err
+	}
+	return s.Accounts.Create(ctx, account)
 }
 
-// =============================================================================
-// Private Methods (核心邏輯封裝)
-// =============================================================================
-
-// processZone 處理單一 Zone 的完整流程：查詢 WHOIS -> 抓取 Records -> 轉換資料
-func (s *CloudflareService) processZone(ctx context.Context, api *cloudflare.API, zone cloudflare.Zone) []domain.SSLCertificate {
-	var results []domain.SSLCertificate
+// UpdateAccount 更新帳號名稱；有帶 Token 時驗證後一併更新
+func (s *CloudflareService) UpdateAccount(ctx context.Context, account domain.CloudflareAccount) error {
+	if account.Name == "" {
+		return fmt.Errorf("請填寫帳號名稱")
+	}
+	if account.APIToken != "" {
+		if err := s.verifyToken(ctx, account.APIToken); err != nil {
+			return err
+		}
+		account.TokenHint = tokenHint(account.APIToken)
+		if err := s.sealToken(&account); err != nil {
+			return err
+		}
+	}
+	return s.Accounts.Update(ctx, account)
+}
 
-	// A. 查詢 Zone (根域名) 的 WHOIS
-	expiryDate, daysLeft, err := s.fetchZoneWhois(zone.Name)
+// TokenFor 取得域名所屬帳號的 Token (舊資料沒有帳號時使用第一個帳號)
+func (s *CloudflareService) TokenFor(ctx context.Context, accountID primitive.ObjectID) (string, error) {
+	account, err := s.resolveAccount(ctx, accountID)
 	if err != nil {
-		logrus.Warnf("   ⚠️ Zone WHOIS 查詢失敗 %s: %v (子域名將無到期日資料)", zone.Name, err)
-	} else {
-		logrus.Infof("   📅 Zone 到期日: %s (剩餘 %d 天)", expiryDate.Format("2006-01-02"), daysLeft)
+		return "", err
 	}
+	return account.APIToken, nil
+}
 
-	// B. 分頁獲取所有 DNS 紀錄
-	records, err := s.fetchAllZoneRecords(ctx, api, zone)
+// Providers 每個 Cloudflare 帳號對應一個 DNSProvider (實作 DNSProviderSource)
+func (s *CloudflareService) Providers(ctx context.Context) ([]DNSProvider, error) {
+	accounts, err := s.ListAccounts(ctx)
 	if err != nil {
-		logrus.Errorf("❌ 無法獲取 Zone %s 的紀錄: %v", zone.Name, err)
-		return nil
+		return nil, err
 	}
-	logrus.Debugf("   -> Zone %s 找到 %d 筆紀錄", zone.Name, len(records))
 
-	// C. 過濾並轉換為 Domain Model
-	for _, record := range records {
-		if !isValidRecordType(record.Type) {
-			continue
-		}
-
-		// =================================================================
-		// [關鍵修正] 在寫入 DB 之前，先檢查是否應該略過
-		// =================================================================
-		if shouldSkipDomain(record.Name) {
-			logrus.Debugf("      🚫 [Skip] 略過不需要的域名: %s", record.Name)
-			continue
+	providers := make([]DNSProvider, 0, len(accounts))
+	for _, account := range accounts {
+		if err := s.openToken(&account); err != nil {
+			return nil, err
 		}
-		// =================================================================
-
-		logrus.Infof("      -> 發現子域名: [%s] %s (Target: %s)", record.Type, record.Name, record.Content)
-
-		cert := s.mapRecordToDomain(zone, record, expiryDate, daysLeft)
-
-		// 2. [新增] 立即寫入資料庫 (Pending)
-		// 使用 Upsert: 如果已存在則更新 (例如更新 Proxy 狀態)，不存在則新增
-		// 注意：這裡只會寫入 Cloudflare 的基本資訊，Status 預設為 "pending"
-		// 我們需要小心不要覆蓋掉已經是 "active" 的狀態
-
-		// 先查一下舊資料，避免把已經掃描好的 active 覆蓋回 pending
-		// (雖然這是 Sync 流程，覆蓋回 pending 等待重掃也是合理的，但為了體驗，我們可以做個檢查)
-		// 為了效能，這裡直接用 Upsert，但在 Upsert 實作層面，建議只更新 "非 SSL 相關" 欄位，或者我們接受它短暫變回 pending
-
-		// 簡單策略：直接 Upsert，讓它變成 Pending。這樣使用者知道「正在重新同步中」。
-		// 或者，您可以在這裡只做 "Insert if not exists"。
-
-		// 為了達到您的需求「一發現就進入 Pending」，我們執行 Upsert
-		if err := s.Repo.Upsert(ctx, cert); err != nil {
-			logrus.Errorf("      ❌ 寫入 Pending 失敗: %v", err)
-		} else {
-			logrus.Debugf("      ✅ 已寫入 Pending: %s", cert.DomainName)
+		api, err := newAPIClient(account.APIToken)
+		if err != nil {
+			return nil, fmt.Errorf("Cloudflare 帳號 %s: %w", account.Name, err)
 		}
-		results = append(results, cert)
+		// 尚未記錄帳號的舊資料無法判斷歸屬，由所有帳號共同擁有 (任一帳號失敗就不會被刪除)
+		providers = append(providers, &cloudflareDNSProvider{svc: s, api: api, account: account})
 	}
+	return providers, nil
+}
 
-	if zone.Status != "active" {
-		logrus.Warnf("發現非 Active 域名: %s (Status: %s)", zone.Name, zone.Status)
+// ProviderFor 取得域名所屬帳號的 DNSProvider (ID 為空時使用第一個帳號)
+func (s *CloudflareService) ProviderFor(ctx context.Context, accountID primitive.ObjectID) (DNSProvider, error) {
+	account, err := s.resolveAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
 	}
+	api, err := newAPIClient(account.APIToken)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudflareDNSProvider{svc: s, api: api, account: *account}, nil
+}
 
-	return results
+// RecordSyncResult 保存帳號的同步結果 (實作 DNSProviderSource)
+func (s *CloudflareService) RecordSyncResult(ctx context.Context, p DNSProvider, count int, syncErr error) {
+	cp, ok := p.(*cloudflareDNSProvider)
+	if !ok {
+		return
+	}
+	if err := s.Accounts.UpdateSyncResult(ctx, cp.account.ID, count, syncErr); err != nil {
+		logrus.Errorf("❌ [Cloudflare] 保存帳號 %s 同步結果失敗: %v", cp.account.Name, err)
+	}
 }
 
+// =============================================================================
+// Private Methods (核心邏輯封裝)
+// =============================================================================
+
 // fetchAllZoneRecords 處理 Cloudflare 分頁邏輯，抓取該 Zone 下所有紀錄
 func (s *CloudflareService) fetchAllZoneRecords(ctx context.Context, api *cloudflare.API, zone cloudflare.Zone) ([]cloudflare.DNSRecord, error) {
 	var allRecords []cloudflare.DNSRecord
@@ -246,82 +220,403 @@ func (s *CloudflareService) listAllZones(ctx context.Context, api *cloudflare.AP
 	return zones, nil
 }
 
-// fetchZoneWhois 查詢並解析 WHOIS 時間
-func (s *CloudflareService) fetchZoneWhois(domainName string) (time.Time, int, error) {
-	raw, err := whois.Whois(domainName)
+// =============================================================================
+// DNSProvider 實作 (一個 Cloudflare 帳號)
+// =============================================================================
+
+type cloudflareDNSProvider struct {
+	svc     *CloudflareService
+	api     *cloudflare.API
+	account domain.CloudflareAccount
+
+	mu         sync.Mutex
+	cfAccounts map[string]string // Zone ID -> Cloudflare Account ID (ListZones 時記錄，查稽核紀錄用)
+}
+
+func (p *cloudflareDNSProvider) Name() string {
+	return domain.ProviderCloudflare
+}
+
+func (p *cloudflareDNSProvider) Label() string {
+	return domain.ProviderCloudflare + "/" + p.account.Name
+}
+
+func (p *cloudflareDNSProvider) AccountKey() string {
+	return p.account.ID.Hex()
+}
+
+func (p *cloudflareDNSProvider) ListZones(ctx context.Context) ([]DNSZone, error) {
+	zones, err := p.svc.listAllZones(ctx, p.api)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]DNSZone, 0, len(zones))
+	p.mu.Lock()
+	if p.cfAccounts == nil {
+		p.cfAccounts = make(map[string]string, len(zones))
+	}
+	for _, z := range zones {
+		results = append(results, DNSZone{ID: z.ID, Name: z.Name, Status: z.Status})
+		p.cfAccounts[z.ID] = z.Account.ID
+	}
+	p.mu.Unlock()
+	return results, nil
+}
+
+func (p *cloudflareDNSProvider) ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error) {
+	records, err := p.svc.fetchAllZoneRecords(ctx, p.api, cloudflare.Zone{ID: zone.ID, Name: zone.Name})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]DNSRecord, 0, len(records))
+	for _, r := range records {
+		results = append(results, toDNSRecord(r))
+	}
+	return results, nil
+}
+
+func (p *cloudflareDNSProvider) GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	record, err := p.api.GetDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
+	if err != nil {
+		var notFound *cloudflare.NotFoundError
+		if errors.As(err, &notFound) {
+			return DNSRecord{}, fmt.Errorf("%w: %s (%v)", ErrRecordNotFound, recordID, err)
+		}
+		return DNSRecord{}, err
+	}
+	return toDNSRecord(record), nil
+}
+
+func (p *cloudflareDNSProvider) Owns(d domain.SSLCertificate) bool {
+	if d.Provider != domain.ProviderCloudflare {
+		return false
+	}
+	return d.CFAccountID == p.account.ID || d.CFAccountID.IsZero()
+}
+
+func (p *cloudflareDNSProvider) Annotate(d *domain.SSLCertificate) {
+	d.CFAccountID = p.account.ID
+}
+
+// CreateRecord 建立紀錄，TTL 使用 Auto (實作 DNSRecordWriter，Token 需具備 DNS:Edit)
+func (p *cloudflareDNSProvider) CreateRecord(ctx context.Context, zone DNSZone, record DNSRecord) (DNSRecord, error) {
+	proxied := record.Proxied
+	created, err := p.api.CreateDNSRecord(ctx, cloudflare.ZoneIdentifier(zone.ID), cloudflare.CreateDNSRecordParams{
+		Type:    record.Type,
+		Name:    record.Name,
+		Content: record.Content,
+		Comment: record.Comment,
+		Proxied: &proxied,
+		TTL:     1,
+	})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	return toDNSRecord(created), nil
+}
+
+// UpdateRecord 修改 Content / Comment / Proxy (實作 DNSRecordWriter)
+// 以目前的紀錄為基底送出，未修改的欄位 (TTL、Tags) 維持原值
+func (p *cloudflareDNSProvider) UpdateRecord(ctx context.Context, zoneID, recordID string, change DNSRecordChange) error {
+	current, err := p.api.GetDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
 	if err != nil {
-		return time.Time{}, 0, err
+		return err
+	}
+
+	params := cloudflare.UpdateDNSRecordParams{
+		ID:      recordID,
+		Type:    current.Type,
+		Name:    current.Name,
+		Content: current.Content,
+		TTL:     current.TTL,
+		Proxied: current.Proxied,
+		Comment: &current.Comment,
+		Tags:    current.Tags,
+	}
+	if change.Content != nil {
+		params.Content = *change.Content
+	}
+	if change.Comment != nil {
+		params.Comment = change.Comment
+	}
+	if change.Proxied != nil {
+		params.Proxied = change.Proxied
+	}
+
+	_, err = p.api.UpdateDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), params)
+	return err
+}
+
+// DeleteRecord 刪除紀錄 (實作 DNSRecordWriter)
+func (p *cloudflareDNSProvider) DeleteRecord(ctx context.Context, zoneID, recordID string) error {
+	return p.api.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zoneID), recordID)
+}
+
+// ZoneSecurity 讀取 SSL 模式、最低 TLS 版本與 Edge 憑證包 (實作 ZoneSecurityReader)
+// Token 需另有 Zone Settings:Read 與 SSL and Certificates:Read 權限
+func (p *cloudflareDNSProvider) ZoneSecurity(ctx context.Context, zone DNSZone) (*domain.ZoneSecurity, error) {
+	sec := &domain.ZoneSecurity{
+		ZoneID:      zone.ID,
+		ZoneName:    zone.Name,
+		Provider:    domain.ProviderCloudflare,
+		CFAccountID: p.account.ID,
+	}
+	var errs []error
+
+	if ssl, err := p.api.ZoneSSLSettings(ctx, zone.ID); err != nil {
+		errs = append(errs, fmt.Errorf("SSL 模式: %w", err))
+	} else {
+		sec.SSLMode = ssl.Value
+	}
+
+	minTLS, err := p.api.GetZoneSetting(ctx, cloudflare.ZoneIdentifier(zone.ID), cloudflare.GetZoneSettingParams{Name: "min_tls_version"})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("最低 TLS 版本: %w", err))
+	} else {
+		sec.MinTLSVersion = fmt.Sprint(minTLS.Value)
+	}
+
+	packs, err := p.api.ListCertificatePacks(ctx, zone.ID)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("憑證包: %w", err))
+	} else {
+		sec.CertificatePacks = make([]domain.CertificatePack, 0, len(packs))
+		for _, pack := range packs {
+			sec.CertificatePacks = append(sec.CertificatePacks, toCertificatePack(pack))
+		}
 	}
 
-	result, err := whoisparser.Parse(raw)
+	return sec, errors.Join(errs...)
+}
+
+func toDNSRecord(r cloudflare.DNSRecord) DNSRecord {
+	proxied := false
+	if r.Proxied != nil {
+		proxied = *r.Proxied
+	}
+	return DNSRecord{
+		ID: r.ID, Name: r.Name, Type: r.Type, Content: r.Content, Comment: r.Comment, Proxied: proxied,
+		ModifiedOn: r.ModifiedOn.Truncate(time.Millisecond), // MongoDB 只保存到毫秒，截斷後才能直接比對
+	}
+}
+
+// RecordChangeActor 從帳號稽核紀錄找出紀錄最近一次的變更者 (實作 RecordAuditReader)
+// 查詢區間為帳號上次同步至今 (尚未同步過時往回 cfAuditLookback)，Token 需另有 Account Audit Logs:Read 權限
+func (p *cloudflareDNSProvider) RecordChangeActor(ctx context.Context, zone DNSZone, recordID string) (*domain.DNSChangeActor, error) {
+	accountID, err := p.cfAccountID(ctx, zone.ID)
 	if err != nil {
-		return time.Time{}, 0, err
+		return nil, err
 	}
 
-	if result.Domain.ExpirationDate == "" {
-		return time.Time{}, 0, fmt.Errorf("no expiration date found")
+	until := time.Now()
+	since := until.Add(-cfAuditLookback)
+	if last := p.account.LastSyncAt.Add(-cfAuditMargin); last.After(since) {
+		since = last
 	}
 
-	return s.parseWhoisTime(result.Domain.ExpirationDate)
+	filter := cloudflare.AuditLogFilter{
+		ZoneName:  zone.Name,
+		Since:     since.UTC().Format(time.RFC3339),
+		Before:    until.UTC().Format(time.RFC3339),
+		Direction: "desc", // 最新的在前，第一筆符合的就是最近一次變更
+		PerPage:   cfAuditPageSize,
+	}
+	for page := 1; page <= cfAuditMaxPages; page++ {
+		filter.Page = page
+		res, err := p.api.GetOrganizationAuditLogs(ctx, accountID, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range res.Result {
+			if entry.Resource.ID != recordID {
+				continue
+			}
+			return &domain.DNSChangeActor{
+				Email:     entry.Actor.Email,
+				IP:        entry.Actor.IP,
+				Type:      entry.Actor.Type,
+				Action:    entry.Action.Type,
+				ChangedAt: entry.When,
+			}, nil
+		}
+		if len(res.Result) < cfAuditPageSize {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// cfAccountID Zone 所屬的 Cloudflare Account ID (ListZones 沒記錄到時查詢 Zone 詳情)
+func (p *cloudflareDNSProvider) cfAccountID(ctx context.Context, zoneID string) (string, error) {
+	p.mu.Lock()
+	id, ok := p.cfAccounts[zoneID]
+	p.mu.Unlock()
+	if ok && id != "" {
+		return id, nil
+	}
+
+	z, err := p.api.ZoneDetails(ctx, zoneID)
+	if err != nil {
+		return "", fmt.Errorf("查詢 Zone %s 所屬帳號失敗: %w", zoneID, err)
+	}
+	p.mu.Lock()
+	if p.cfAccounts == nil {
+		p.cfAccounts = make(map[string]string)
+	}
+	p.cfAccounts[zoneID] = z.Account.ID
+	p.mu.Unlock()
+	return z.Account.ID, nil
 }
 
-// mapRecordToDomain 將 Cloudflare 原始資料映射為內部資料結構
-func (s *CloudflareService) mapRecordToDomain(zone cloudflare.Zone, record cloudflare.DNSRecord, expiryDate time.Time, daysLeft int) domain.SSLCertificate {
-	return domain.SSLCertificate{
-		DomainName:       record.Name,
-		CFZoneID:         zone.ID,
-		ZoneName:         zone.Name,
-		CFRecordID:       record.ID,
-		IsProxied:        *record.Proxied,
-		DomainExpiryDate: expiryDate,
-		DomainDaysLeft:   daysLeft,
-		CFOriginValue:    record.Content,
-		CFRecordType:     record.Type,
-		CFComment:        record.Comment,
-		IsIgnored:        false,
-		Status:           "pending",
+// toCertificatePack 轉換 Edge 憑證包，到期日取包內最早到期的憑證
+func toCertificatePack(pack cloudflare.CertificatePack) domain.CertificatePack {
+	result := domain.CertificatePack{
+		ID:                   pack.ID,
+		Type:                 pack.Type,
+		Hosts:                pack.Hosts,
+		Status:               pack.Status,
+		CertificateAuthority: pack.CertificateAuthority,
+		ValidationMethod:     pack.ValidationMethod,
+	}
+	for _, e := range pack.ValidationErrors {
+		result.ValidationErrors = append(result.ValidationErrors, e.Message)
+	}
+	for _, c := range pack.Certificates {
+		if c.ExpiresOn.IsZero() {
+			continue
+		}
+		if result.ExpiresOn.IsZero() || c.ExpiresOn.Before(result.ExpiresOn) {
+			result.ExpiresOn = c.ExpiresOn
+		}
 	}
+	if !result.ExpiresOn.IsZero() {
+		result.DaysRemaining = int(time.Until(result.ExpiresOn).Hours() / 24)
+	}
+	return result
 }
 
 // =============================================================================
 // Helper Functions (工具與底層邏輯)
 // =============================================================================
 
-func (s *CloudflareService) getAPIClient() (*cloudflare.API, error) {
-	api, err := cloudflare.NewWithAPIToken(s.APIToken)
+// clientFor 建立域名所屬帳號的 API Client
+func (s *CloudflareService) clientFor(ctx context.Context, accountID primitive.ObjectID) (*cloudflare.API, error) {
+	account, err := s.resolveAccount(ctx, accountID)
 	if err != nil {
-		logrus.Errorf("❌ [Cloudflare] API Client 初始化失敗: %v", err)
 		return nil, err
 	}
-	return api, nil
+	return newAPIClient(account.APIToken)
 }
 
-// parseWhoisTime 嘗試多種格式解析時間
-func (s *CloudflareService) parseWhoisTime(dateStr string) (time.Time, int, error) {
-	var expiryTime time.Time
+// resolveAccount 依 ID 取得帳號；ID 為空時 (同步前的舊資料) 使用第一個帳號
+func (s *CloudflareService) resolveAccount(ctx context.Context, accountID primitive.ObjectID) (*domain.CloudflareAccount, error) {
+	var account *domain.CloudflareAccount
+	if !accountID.IsZero() {
+		found, err := s.Accounts.GetByID(ctx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("找不到 Cloudflare 帳號 %s: %w", accountID.Hex(), err)
+		}
+		account = found
+	} else {
+		accounts, err := s.ListAccounts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("尚未設定任何 Cloudflare 帳號")
+		}
+		account = &accounts[0]
+	}
 
-	formats := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05Z",
-		"2006-01-02T15:04:05.00Z",
-		"2006-01-02",
+	if err := s.openToken(account); err != nil {
+		return nil, err
 	}
+	return account, nil
+}
 
-	for _, f := range formats {
-		if t, e := time.Parse(f, dateStr); e == nil {
-			expiryTime = t
-			break
+// sealToken 加密 account.APIToken 存入 EncryptedToken
+func (s *CloudflareService) sealToken(account *domain.CloudflareAccount) error {
+	if s.Store == nil {
+		return fmt.Errorf("憑證庫未設定，無法加密保存 Cloudflare Token")
+	}
+	sealed, err := s.Store.encrypt([]byte(account.APIToken))
+	if err != nil {
+		return fmt.Errorf("加密 Cloudflare Token 失敗: %w", err)
+	}
+	account.EncryptedToken = sealed
+	account.LegacyToken = ""
+	return nil
+}
+
+// openToken 解密 EncryptedToken 到 account.APIToken (尚未遷移的舊資料直接使用明文 Token)
+func (s *CloudflareService) openToken(account *domain.CloudflareAccount) error {
+	if account.EncryptedToken == "" {
+		if account.LegacyToken == "" {
+			return fmt.Errorf("Cloudflare 帳號 %s 沒有 Token", account.Name)
 		}
+		account.APIToken = account.LegacyToken
+		return nil
+	}
+	if s.Store == nil {
+		return fmt.Errorf("憑證庫未設定，無法解密 Cloudflare 帳號 %s 的 Token", account.Name)
+	}
+	plain, err := s.Store.decrypt(account.EncryptedToken)
+	if err != nil {
+		return fmt.Errorf("Cloudflare 帳號 %s 的 Token 解密失敗 (encryption_key 是否變更?): %w", account.Name, err)
 	}
+	account.APIToken = string(plain)
+	return nil
+}
 
-	if expiryTime.IsZero() {
-		return time.Time{}, 0, fmt.Errorf("date parse fail: %s", dateStr)
+// migrateLegacyToken 將舊版明文保存的 Token 加密後寫回，並移除明文欄位
+func (s *CloudflareService) migrateLegacyToken(ctx context.Context, account *domain.CloudflareAccount) error {
+	if account.LegacyToken == "" || account.EncryptedToken != "" {
+		return nil
+	}
+	account.APIToken = account.LegacyToken
+	if err := s.sealToken(account); err != nil {
+		return err
+	}
+	if err := s.Accounts.Update(ctx, *account); err != nil {
+		return fmt.Errorf("加密保存 Cloudflare 帳號 %s 的 Token 失敗: %w", account.Name, err)
 	}
+	account.APIToken = ""
+	logrus.Infof("🔐 [Cloudflare] 帳號 %s 的 Token 已改為加密保存", account.Name)
+	return nil
+}
 
-	daysLeft := int(time.Until(expiryTime).Hours() / 24)
-	return expiryTime, daysLeft, nil
+// verifyToken 呼叫 Cloudflare 確認 Token 有效
+func (s *CloudflareService) verifyToken(ctx context.Context, token string) error {
+	if token == "" {
+		return fmt.Errorf("請填寫 API Token")
+	}
+	api, err := newAPIClient(token)
+	if err != nil {
+		return err
+	}
+	res, err := api.VerifyAPIToken(ctx)
+	if err != nil {
+		return fmt.Errorf("Token 驗證失敗: %w", err)
+	}
+	if res.Status != "active" {
+		return fmt.Errorf("Token 狀態為 %s", res.Status)
+	}
+	return nil
+}
+
+func newAPIClient(token string) (*cloudflare.API, error) {
+	api, err := cloudflare.NewWithAPIToken(token)
+	if err != nil {
+		logrus.Errorf("❌ [Cloudflare] API Client 初始化失敗: %v", err)
+		return nil, err
+	}
+	return api, nil
 }
 
-func isValidRecordType(recordType string) bool {
-	return recordType == "A" || recordType == "CNAME"
+// tokenHint 只保留 Token 末 4 碼供辨識
+func tokenHint(token string) string {
+	if len(token) <= 4 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
 }
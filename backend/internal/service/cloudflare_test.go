@@ -0,0 +1,191 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const testCFToken = "cf-token-0123456789abcdef"
+
+func TestCloudflareTokenEncryptedAtRest(t *testing.T) {
+	repo := &memCFAccounts{}
+	store := NewCertStoreService(nil, nil, "test-key")
+	svc := NewCloudflareService(testCFToken, nil, repo, store)
+	ctx := context.Background()
+
+	// 設定檔的 Token 遷移成第一個帳號時就加密
+	accounts, err := svc.ListAccounts(ctx)
+	if err != nil || len(accounts) != 1 {
+		t.Fatalf("ListAccounts = %v, %v", accounts, err)
+	}
+	raw := repo.raw(t, accounts[0].ID)
+	if strings.Contains(raw, testCFToken) {
+		t.Fatalf("token stored in plaintext: %s", raw)
+	}
+
+	account, err := svc.resolveAccount(ctx, accounts[0].ID)
+	if err != nil || account.APIToken != testCFToken {
+		t.Fatalf("resolveAccount token = %q, %v", account.APIToken, err)
+	}
+	providers, err := svc.Providers(ctx)
+	if err != nil || len(providers) != 1 || providers[0].(*cloudflareDNSProvider).account.APIToken != testCFToken {
+		t.Fatalf("Providers = %v, %v", providers, err)
+	}
+
+	// 不同金鑰解不開
+	other := NewCloudflareService("", nil, repo, NewCertStoreService(nil, nil, "another-key"))
+	if _, err := other.resolveAccount(ctx, accounts[0].ID); err == nil {
+		t.Error("token decrypted with a different key")
+	}
+}
+
+func TestCloudflareMigratesPlaintextToken(t *testing.T) {
+	repo := &memCFAccounts{}
+	id := primitive.NewObjectID()
+	repo.put(t, domain.CloudflareAccount{ID: id, Name: "Prod", LegacyToken: testCFToken, TokenHint: "cdef"})
+	svc := NewCloudflareService("", nil, repo, NewCertStoreService(nil, nil, "test-key"))
+	ctx := context.Background()
+
+	// 尚未遷移前也能直接使用
+	account, err := svc.resolveAccount(ctx, id)
+	if err != nil || account.APIToken != testCFToken {
+		t.Fatalf("resolveAccount before migration = %q, %v", account.APIToken, err)
+	}
+
+	if _, err := svc.ListAccounts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	raw := repo.raw(t, id)
+	if strings.Contains(raw, testCFToken) || strings.Contains(raw, "api_token") || !strings.Contains(raw, "encrypted_token") {
+		t.Fatalf("plaintext token not migrated: %s", raw)
+	}
+	account, err = svc.resolveAccount(ctx, id)
+	if err != nil || account.APIToken != testCFToken || account.Name != "Prod" {
+		t.Fatalf("resolveAccount after migration = %+v, %v", account, err)
+	}
+}
+
+// memCFAccounts 以 BSON 保存，確認實際寫入的欄位
+type memCFAccounts struct {
+	repository.CloudflareAccountRepository
+
+	mu   sync.Mutex
+	docs []bson.M
+}
+
+func (m *memCFAccounts) put(t *testing.T, account domain.CloudflareAccount) {
+	t.Helper()
+	if err := m.Create(context.Background(), &account); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (m *memCFAccounts) raw(t *testing.T, id primitive.ObjectID) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range m.docs {
+		if doc["_id"] == id {
+			return stringifyDoc(doc)
+		}
+	}
+	t.Fatalf("account %s not stored", id.Hex())
+	return ""
+}
+
+func (m *memCFAccounts) Create(_ context.Context, account *domain.CloudflareAccount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if account.ID.IsZero() {
+		account.ID = primitive.NewObjectID()
+	}
+	data, err := bson.Marshal(account)
+	if err != nil {
+		return err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	m.docs = append(m.docs, doc)
+	return nil
+}
+
+func (m *memCFAccounts) Update(_ context.Context, account domain.CloudflareAccount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range m.docs {
+		if doc["_id"] != account.ID {
+			continue
+		}
+		doc["name"] = account.Name
+		if account.EncryptedToken != "" {
+			doc["encrypted_token"] = account.EncryptedToken
+			doc["token_hint"] = account.TokenHint
+			delete(doc, "api_token")
+		}
+	}
+	return nil
+}
+
+func (m *memCFAccounts) GetByID(_ context.Context, id primitive.ObjectID) (*domain.CloudflareAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range m.docs {
+		if doc["_id"] == id {
+			return decodeCFAccount(doc)
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *memCFAccounts) List(context.Context) ([]domain.CloudflareAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []domain.CloudflareAccount
+	for _, doc := range m.docs {
+		account, err := decodeCFAccount(doc)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *account)
+	}
+	return list, nil
+}
+
+func decodeCFAccount(doc bson.M) (*domain.CloudflareAccount, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var account domain.CloudflareAccount
+	if err := bson.Unmarshal(data, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func stringifyDoc(doc bson.M) string {
+	var b strings.Builder
+	for k, v := range doc {
+		b.WriteString(k)
+		b.WriteString("=")
+		if s, ok := v.(string); ok {
+			b.WriteString(s)
+		}
+		b.WriteString(" ")
+	}
+	return b.String()
+}
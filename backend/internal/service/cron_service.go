@@ -4,7 +4,9 @@ import (
 	"cert-manager/internal/domain"
 	"cert-manager/internal/repository"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,26 +25,40 @@ type SyncStats struct {
 	Deleted      int
 	DeletedNames []string
 	Skipped      int
+	Unchanged    int // 紀錄未修改，沿用上次掃描結果 (完整掃描交給 SSL 掃描排程)
 	Duration     string
 }
 
 type CronService struct {
-	Cron      *cron.Cron
-	Repo      repository.DomainRepository
-	CFService *CloudflareService
-	Scanner   *ScannerService
-	Notifier  *NotifierService
-	EntryIDs  map[string]cron.EntryID
+	Cron       *cron.Cron
+	Repo       repository.DomainRepository
+	DNS        *DNSSyncService
+	Scanner    *ScannerService
+	Notifier   *NotifierService
+	Acme       *AcmeService
+	InternalCA *InternalCAService
+	FileCerts  *FileCertService
+	CT         *CTMonitorService
+	Discovery  *DiscoveryService
+	EntryIDs   map[string]cron.EntryID
 }
 
-func NewCronService(repo repository.DomainRepository, cf *CloudflareService, scan *ScannerService, notify *NotifierService) *CronService {
+// 預設續簽窗口 (剩餘天數低於此值即續簽)
+const defaultRenewBeforeDays = 30
+
+func NewCronService(repo repository.DomainRepository, dns *DNSSyncService, scan *ScannerService, notify *NotifierService, acme *AcmeService, internalCA *InternalCAService, fileCerts *FileCertService, ct *CTMonitorService, discovery *DiscoveryService) *CronService {
 	return &CronService{
-		Cron:      cron.New(),
-		Repo:      repo,
-		CFService: cf,
-		Scanner:   scan,
-		Notifier:  notify,
-		EntryIDs:  make(map[string]cron.EntryID),
+		Cron:       cron.New(),
+		Repo:       repo,
+		DNS:        dns,
+		Scanner:    scan,
+		Notifier:   notify,
+		Acme:       acme,
+		InternalCA: internalCA,
+		FileCerts:  fileCerts,
+		CT:         ct,
+		Discovery:  discovery,
+		EntryIDs:   make(map[string]cron.EntryID),
 	}
 }
 
@@ -82,6 +98,32 @@ func (s *CronService) ReloadJobs() {
 			s.PerformScan(context.Background())
 		})
 	}
+
+	// 3. 註冊 ACME 自動續簽
+	if settings.RenewEnabled && settings.RenewSchedule != "" {
+		renewBefore := settings.RenewBeforeDays
+		s.registerJob("renew", settings.RenewSchedule, func() {
+			s.PerformRenew(context.Background(), renewBefore)
+		})
+	}
+
+	// 4. 註冊 Certificate Transparency 監控
+	if settings.CTEnabled && settings.CTSchedule != "" {
+		s.registerJob("ct", settings.CTSchedule, func() {
+			ctx := context.Background()
+			if err := s.CT.Poll(ctx); err != nil {
+				logrus.Errorf("❌ [Cron] CT 監控失敗: %v", err)
+			}
+			s.runDiscovery(ctx)
+		})
+	}
+}
+
+// runDiscovery 從 SAN 與 CT 紀錄更新探索建議 (掃描與 CT 排程結束後執行)
+func (s *CronService) runDiscovery(ctx context.Context) {
+	if _, err := s.Discovery.Run(ctx); err != nil {
+		logrus.Errorf("❌ [Cron] 主機探索失敗: %v", err)
+	}
 }
 
 // registerJob 封裝註冊邏輯
@@ -126,12 +168,23 @@ func (s *CronService) PerformSync(ctx context.Context) (SyncStats, error) {
 	var allCFDomains []domain.SSLCertificate
 	var cfMutex sync.Mutex
 
-	// 3. 啟動 Cloudflare 抓取 (生產者)
-	errChan := make(chan error, 1)
+	// 3. 啟動 DNS Provider 抓取 (生產者)，依序處理每個 Provider
+	providers, sources, err := s.DNS.Providers(ctx)
+	if err != nil {
+		return stats, err
+	}
+	if len(providers) == 0 {
+		return stats, fmt.Errorf("尚未設定任何 DNS Provider")
+	}
+	results := make([]providerSyncResult, len(providers))
 	go func() {
 		defer close(domainStream)
-		if err := s.CFService.FetchDomains(ctx, domainStream); err != nil {
-			errChan <- err
+		for i, p := range providers {
+			report, err := s.DNS.FetchDomains(ctx, p, domainStream)
+			if err != nil {
+				logrus.Errorf("❌ [Cron] %s 同步失敗: %v", p.Label(), err)
+			}
+			results[i] = providerSyncResult{Provider: p, Source: sources[i], Report: report, Err: err} // 通道關閉前寫入，消費者結束後即可安全讀取
 		}
 	}()
 
@@ -143,18 +196,23 @@ func (s *CronService) PerformSync(ctx context.Context) (SyncStats, error) {
 	// newZones := make(map[string]bool)
 
 	// 將新發現的 Zone 邏輯整合進 processUpsertsStream (見下步) 或保持現狀但 newZones 為空
-	s.processUpsertsStream(ctx, domainStream, dbMap, &stats, existingZones, &allCFDomains, &cfMutex)
+	s.processUpsertsStream(ctx, domainStream, dbMap, &stats, existingZones, &allCFDomains, &cfMutex, providers)
 
 	// // 用來記錄新發現的 Zone，避免大量發送子域名新增通知
 	// newZones := s.detectZoneChanges(ctx, nil, dbDomains) // 這裡先傳 nil，後面在 stream 裡動態判斷
 
 	// s.processUpsertsStream(ctx, domainStream, dbMap, &stats, newZones, &allCFDomains, &cfMutex)
 
-	// 檢查抓取是否有錯
-	select {
-	case err := <-errChan:
-		return stats, err
-	default:
+	// 檢查抓取是否有錯 (全部 Provider 都失敗才中止，單一 Provider 失敗只保護它的資料)
+	var fetchErrs []error
+	for _, r := range results {
+		if r.Err != nil {
+			fetchErrs = append(fetchErrs, fmt.Errorf("%s: %w", r.Provider.Label(), r.Err))
+		}
+	}
+	if len(fetchErrs) == len(results) {
+		s.recordProviderResults(ctx, results, allCFDomains, dbDomains)
+		return stats, errors.Join(fetchErrs...)
 	}
 
 	// =================================================================
@@ -163,11 +221,11 @@ func (s *CronService) PerformSync(ctx context.Context) (SyncStats, error) {
 	// =================================================================
 	if len(allCFDomains) == 0 {
 		if len(dbDomains) > 0 {
-			logrus.Warnf("⚠️ [Safety] 本次同步未從 Cloudflare 獲取到任何域名 (但本地有 %d 筆)。", len(dbDomains))
+			logrus.Warnf("⚠️ [Safety] 本次同步未從任何 Provider 獲取到任何域名 (但本地有 %d 筆)。", len(dbDomains))
 			logrus.Warn("🛑 為防止誤刪資料，已強制略過刪除程序 (Deletion Skipped)。請檢查 API Token 權限或網路狀態。")
 
 			stats.Duration = time.Since(start).String()
-			return stats, fmt.Errorf("safety check triggered: 0 domains fetched from cloudflare")
+			return stats, fmt.Errorf("safety check triggered: 0 domains fetched from any provider")
 		}
 		// 如果本地原本也是空的，那就沒關係
 	}
@@ -175,16 +233,114 @@ func (s *CronService) PerformSync(ctx context.Context) (SyncStats, error) {
 	logrus.Info("🗑 [Cron] 開始檢查已刪除的域名...")
 
 	// [新增] 在這裡執行 Zone 的變更檢測，因為現在 allCFDomains 已經完整了
-	s.detectZoneChanges(ctx, allCFDomains, dbDomains)
+	// 刪除與 Zone 變更只在各自的 Provider 範圍內判斷；
+	// 被排除或抓取失敗的 Zone 沒有完整資料，不能當成已從 Provider 移除
+	scope := s.recordProviderResults(ctx, results, allCFDomains, dbDomains)
+	for _, r := range results {
+		if r.Report != nil && (len(r.Report.Excluded) > 0 || len(r.Report.Failed) > 0) {
+			logrus.Infof("🛡 [Cron] %s 有 Zone 本次未同步 (排除: %v / 失敗: %v)，保留其資料", r.Provider.Label(), r.Report.Excluded, r.Report.Failed)
+		}
+	}
+	s.detectZoneChanges(ctx, allCFDomains, dbDomains, scope)
 
-	s.processDeletions(ctx, allCFDomains, dbDomains, &stats)
+	s.processDeletions(ctx, allCFDomains, dbDomains, scope, &stats)
 
 	stats.Duration = time.Since(start).String()
-	logrus.Infof("🏁 [Cron] 同步完成 (耗時: %s)", stats.Duration)
+	logrus.Infof("🏁 [Cron] 同步完成 (新增 %d / 更新 %d / 未變更 %d，耗時: %s)", stats.Added, stats.Updated, stats.Unchanged, stats.Duration)
 
 	return stats, nil
 }
 
+// providerSyncResult 單一 DNS Provider 的抓取結果
+type providerSyncResult struct {
+	Provider DNSProvider
+	Source   DNSProviderSource
+	Report   *ZoneSyncReport
+	Err      error
+}
+
+// syncScope 本次同步可以據以判斷「已刪除」的範圍
+// 域名必須至少有一個 Provider 擁有，且擁有它的 Provider 都完整同步了它所在的 Zone
+type syncScope struct {
+	results   []providerSyncResult
+	failed    []bool
+	untouched []map[string]bool
+}
+
+func (sc *syncScope) covers(d domain.SSLCertificate) bool {
+	owned := false
+	for i, r := range sc.results {
+		if !r.Provider.Owns(d) {
+			continue
+		}
+		if sc.failed[i] || sc.untouched[i][d.ZoneName] {
+			return false
+		}
+		owned = true
+	}
+	return owned
+}
+
+// recordProviderResults 保存各 Provider 的同步結果，並回傳本次的刪除範圍
+// Provider 抓取失敗，或抓到 0 筆但本地仍有其域名 (單一 Provider 的安全閥) 時，整個 Provider 的資料都會被保留
+// 不影響其他 Provider 的同步與刪除
+func (s *CronService) recordProviderResults(ctx context.Context, results []providerSyncResult, fetchedDomains, dbDomains []domain.SSLCertificate) *syncScope {
+	scope := &syncScope{
+		results:   results,
+		failed:    make([]bool, len(results)),
+		untouched: make([]map[string]bool, len(results)),
+	}
+
+	for i, r := range results {
+		scope.untouched[i] = r.Report.Untouched()
+
+		fetched := countOwned(fetchedDomains, r.Provider)
+		syncErr := r.Err
+		if syncErr == nil && fetched == 0 && countOwned(dbDomains, r.Provider) > 0 {
+			logrus.Warnf("⚠️ [Safety] %s 本次未獲取到任何域名 (但本地有資料)，保留其域名", r.Provider.Label())
+			syncErr = fmt.Errorf("safety check triggered: 0 domains fetched")
+		}
+		scope.failed[i] = syncErr != nil
+
+		r.Source.RecordSyncResult(ctx, r.Provider, fetched, syncErr)
+	}
+	return scope
+}
+
+// sourceLabel 擁有這些域名的 Provider 名稱 (e.g. "route53", "cloudflare/Prod")，用於通知內容
+// 沒有 Provider 擁有時退回域名上記錄的 Provider
+func (sc *syncScope) sourceLabel(domains []domain.SSLCertificate) string {
+	var labels []string
+	for _, d := range domains {
+		owned := false
+		for _, r := range sc.results {
+			if r.Provider.Owns(d) {
+				owned = true
+				if !slices.Contains(labels, r.Provider.Label()) {
+					labels = append(labels, r.Provider.Label())
+				}
+			}
+		}
+		if !owned && d.Provider != "" && !slices.Contains(labels, d.Provider) {
+			labels = append(labels, d.Provider)
+		}
+	}
+	if len(labels) == 0 {
+		return "DNS Provider"
+	}
+	return strings.Join(labels, ", ")
+}
+
+func countOwned(domains []domain.SSLCertificate, p DNSProvider) int {
+	count := 0
+	for _, d := range domains {
+		if p.Owns(d) {
+			count++
+		}
+	}
+	return count
+}
+
 // service/cron_service.go
 // processUpsertsStream 是核心的流水線處理器
 // 它同時扮演消費者 (Consumer) 與 掃描調度者 (Dispatcher)
@@ -197,6 +353,7 @@ func (s *CronService) processUpsertsStream(
 	existingZones map[string]bool, // [修改] 參數改為 existingZones (DB裡已知的)
 	allCFDomains *[]domain.SSLCertificate, // [輸出] 收集所有抓到的域名
 	cfMutex *sync.Mutex, // [鎖] 保護 allCFDomains
+	providers []DNSProvider, // [對照] 查詢紀錄變更者 (稽核紀錄) 用
 ) {
 	// 設定併發數 (建議 10-20)
 	concurrency := 15
@@ -211,7 +368,7 @@ func (s *CronService) processUpsertsStream(
 	// for z := range newZones {
 	// 	zoneHasValidRecords[z] = false
 	// }
-	discoveredZones := make(map[string]bool)
+	discoveredZones := make(map[string]string) // Zone -> Provider
 
 	logrus.Info("⚡ [Pipeline] 掃描流水線啟動，正在處理資料流...")
 
@@ -223,7 +380,7 @@ func (s *CronService) processUpsertsStream(
 
 		// [新增] 只要 Cloudflare 有這個 Zone，就記錄下來 (不管後面是否 skip)
 		mu.Lock()
-		discoveredZones[cfD.ZoneName] = true
+		discoveredZones[cfD.ZoneName] = cfD.Provider
 		mu.Unlock()
 
 		// 2. 過濾略過的域名
@@ -273,18 +430,29 @@ func (s *CronService) processUpsertsStream(
 				targetCert = existing
 
 				// 只更新來自 Cloudflare 的變動屬性
-				targetCert.CFZoneID = sourceCF.CFZoneID
-				targetCert.CFRecordID = sourceCF.CFRecordID
+				targetCert.Provider = sourceCF.Provider
+				targetCert.ZoneID = sourceCF.ZoneID
+				targetCert.RecordID = sourceCF.RecordID
 				targetCert.CFRecordType = sourceCF.CFRecordType
 				targetCert.CFOriginValue = sourceCF.CFOriginValue
 				targetCert.IsProxied = sourceCF.IsProxied
 				targetCert.CFComment = sourceCF.CFComment
-				
+				targetCert.CFAccountID = sourceCF.CFAccountID
+				targetCert.RecordModifiedOn = sourceCF.RecordModifiedOn
+
 				// ZoneName 也更新一下，防止 CF 改名 (雖然罕見)
 				targetCert.ZoneName = sourceCF.ZoneName
 
 				// 注意：這裡完全不碰 ID, Port, IsIgnored, LastCheckTime
 				// 它們都安全地保存在 targetCert (即 existing) 中
+
+				// [增量同步] 紀錄沒有修改過就不重新掃描
+				if !recordChanged(existing, targetCert) {
+					mu.Lock()
+					stats.Unchanged++
+					mu.Unlock()
+					return
+				}
 			} else {
 				// // [新域名]：先 Upsert 一次 Pending 狀態
 				// // 這是為了讓前端列表能馬上看到它，即便 ScanOne 還在跑
@@ -325,20 +493,23 @@ func (s *CronService) processUpsertsStream(
 			// =========================================================
 
 			if exists {
-				if !existing.NotAfter.IsZero() && targetCert.NotAfter.After(existing.NotAfter.Add(24*time.Hour)) {
-
-					// 1. [關鍵修改] 確保 Cloudflare 的變更 (如 Proxy 開關) 被儲存
-					// 雖然 ScanOne 內部可能存了 SSL，但為了確保 CF 欄位同步，我們這裡再存一次。
-					// 重點：必須使用 finalCert (它是最新的完全體)，絕對不能用 targetCert (會覆蓋掉 SSL)
-					if err := s.Repo.Upsert(ctx, finalCert); err != nil { // <<<<< 關鍵修改：使用 finalCert
-						logrus.Errorf("❌ [DB Error] 更新失敗 %s: %v", finalCert.DomainName, err)
-					}
+				// 1. [關鍵修改] 確保 Cloudflare 的變更 (如 Proxy 開關、修改時間) 被儲存
+				// 雖然 ScanOne 內部可能存了 SSL，但為了確保 CF 欄位同步，我們這裡再存一次。
+				// 重點：必須使用 finalCert (它是最新的完全體)，絕對不能用 targetCert (會覆蓋掉 SSL)
+				if err := s.Repo.Upsert(ctx, finalCert); err != nil { // <<<<< 關鍵修改：使用 finalCert
+					logrus.Errorf("❌ [DB Error] 更新失敗 %s: %v", finalCert.DomainName, err)
 				}
 				// [舊域名]：檢查 Cloudflare 設定是否變更 (Proxy, Origin, Type)
 				// 注意：finalCert 是掃描後的新資料，existing 是資料庫裡的舊資料
-				cfChanges := s.checkCFDiff(existing, finalCert)
+				cfChanges := checkCFDiff(existing, finalCert)
 
 				if len(cfChanges) > 0 {
+					// 保存到變更紀錄，並附上稽核紀錄中的變更者
+					change := s.recordDNSChange(ctx, providers, existing, finalCert)
+					if change.Actor != nil {
+						cfChanges = append(cfChanges, formatChangeActor(change.Actor))
+					}
+
 					mu.Lock()
 					stats.Updated++
 					detailMsg := fmt.Sprintf("🔹 <b>%s</b>\n   ↳ %s",
@@ -431,7 +602,7 @@ func (s *CronService) cleanupPlaceholders(
 	ctx context.Context,
 	dbMap map[string]domain.SSLCertificate,
 	activeZonesWithRealData map[string]bool,
-	discoveredZones map[string]bool,
+	discoveredZones map[string]string,
 	stats *SyncStats,
 ) {
 	// 1. 清除過期的 Placeholder
@@ -451,7 +622,7 @@ func (s *CronService) cleanupPlaceholders(
 	// 邏輯：如果一個 Zone 這次有被掃描到 (discoveredZones)，
 	// 但它卻沒有任何有效的子域名被寫入 (即不在 activeZonesWithRealData 中)，
 	// 且資料庫裡也沒有它的紀錄 (dbMap check)，則建立一個 Placeholder。
-	for zoneName := range discoveredZones {
+	for zoneName, provider := range discoveredZones {
 		if !activeZonesWithRealData[zoneName] {
 			// 檢查 DB 是否已經有這個主域名本身的紀錄 (避免重複建立)
 			// 注意：這裡檢查 dbMap 是為了防止即使有 placeholder 了還重複建立
@@ -461,6 +632,7 @@ func (s *CronService) cleanupPlaceholders(
 
 				placeholder := domain.SSLCertificate{
 					DomainName:       zoneName, // 使用主域名作為名稱
+					Provider:         provider,
 					ZoneName:         zoneName,
 					Status:           "skipped_zone",
 					IsIgnored:        true,
@@ -871,7 +1043,7 @@ func (s *CronService) processUpserts(ctx context.Context, cfDomains []domain.SSL
 			// 寫入資料庫
 			// if exists {
 			if exists {
-				changes := s.checkCFDiff(existing, targetCert)
+				changes := checkCFDiff(existing, targetCert)
 
 				// 2. [新增] 檢查 SSL 續簽 (Renewal)
 				// 如果 新到期日 > 舊到期日 + 1天
@@ -1010,7 +1182,7 @@ func (s *CronService) processUpserts(ctx context.Context, cfDomains []domain.SSL
 }
 
 // processDeletions 處理刪除邏輯
-func (s *CronService) processDeletions(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate, stats *SyncStats) {
+func (s *CronService) processDeletions(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate, scope *syncScope, stats *SyncStats) {
 	cfMap := make(map[string]bool)
 	// 2. [新增] 建立 Cloudflare 存在的「Zone (主域名)」Map
 	activeZones := make(map[string]bool)
@@ -1023,6 +1195,11 @@ func (s *CronService) processDeletions(ctx context.Context, cfDomains []domain.S
 	}
 
 	for _, dbD := range dbDomains {
+		// 只處理本次完整同步的 Provider/Zone 底下的域名 (手動新增、排除或失敗的一律保留)
+		if !scope.covers(dbD) {
+			continue
+		}
+
 		// =================================================================
 		// [關鍵修正] 保護佔位符 (Placeholder) 不被誤刪
 		// =================================================================
@@ -1052,9 +1229,11 @@ func (s *CronService) processDeletions(ctx context.Context, cfDomains []domain.S
 				// =========================================================
 				// [新增] 立即發送單獨的刪除通知
 				// =========================================================
+				source := scope.sourceLabel([]domain.SSLCertificate{dbD})
 				details := fmt.Sprintf(
-					"來源: Cloudflare Sync\n" +
-						"說明: 該域名已從 Cloudflare 移除，系統已同步刪除。",
+					"來源: %s Sync\n"+
+						"說明: 該域名已從 %s 移除，系統已同步刪除。",
+					source, source,
 				)
 				s.Notifier.NotifyOperation(ctx, EventDelete, dbD.DomainName, details)
 			}
@@ -1089,6 +1268,19 @@ func (s *CronService) PerformScan(ctx context.Context) {
 		return
 	}
 
+	// Origin CA 憑證不會被掃描到 (只看得到邊緣憑證)，另外更新剩餘天數
+	if err := s.Repo.RefreshOriginDays(ctx); err != nil {
+		logrus.Errorf("❌ [Cron] 更新 Origin 憑證天數失敗: %v", err)
+	}
+
+	// 磁碟憑證平常由檔案監看觸發，排程時重新掃描以更新剩餘天數與到期告警
+	if err := s.FileCerts.ScanAll(ctx); err != nil {
+		logrus.Errorf("❌ [Cron] 掃描磁碟憑證失敗: %v", err)
+	}
+
+	// 掃描後 SAN 已更新，順便找出未監控的主機名稱
+	s.runDiscovery(ctx)
+
 	duration := time.Since(start).String()
 
 	// 發送完成統計通知
@@ -1104,6 +1296,77 @@ func (s *CronService) PerformScan(ctx context.Context) {
 	}
 }
 
+// PerformRenew 續簽所有開啟 AutoRenew 且到了續簽時間的域名
+// 優先依 CA 的 ARI 建議時段，CA 不支援時才看剩餘天數 (< renewBeforeDays)
+func (s *CronService) PerformRenew(ctx context.Context, renewBeforeDays int) {
+	if renewBeforeDays <= 0 {
+		renewBeforeDays = defaultRenewBeforeDays
+	}
+
+	domains, err := s.Repo.ListAutoRenew(ctx, 0)
+	if err != nil {
+		logrus.Errorf("❌ [Renew] 撈取續簽名單失敗: %v", err)
+		return
+	}
+	logrus.Infof("♻️ [Renew] 開始檢查自動續簽，共 %d 個域名 (無 ARI 時剩餘 < %d 天才續簽)", len(domains), renewBeforeDays)
+
+	// 依序處理：DNS-01 需要等待傳播，且要避免撞到 CA 的 Rate Limit
+	var renewed, failed, skipped int
+	for _, d := range domains {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// 內建 CA 簽發的憑證由 InternalCA 續簽
+		if managed, due := s.InternalCA.RenewalStatus(ctx, d, renewBeforeDays); managed {
+			if !due {
+				skipped++
+				continue
+			}
+			if _, err := s.InternalCA.Renew(ctx, d); err != nil {
+				failed++
+				logrus.Errorf("❌ [Renew] %s 內部憑證續簽失敗: %v", d.DomainName, err)
+				continue
+			}
+			renewed++
+			continue
+		}
+
+		due, reason := s.Acme.RenewalDue(ctx, d, renewBeforeDays)
+		if !due {
+			skipped++
+			continue
+		}
+		logrus.Infof("♻️ [Renew] %s 需要續簽: %s", d.DomainName, reason)
+
+		newCert, err := s.Acme.Renew(ctx, d)
+		if err != nil {
+			failed++
+			logrus.Errorf("❌ [Renew] %s 續簽失敗: %v", d.DomainName, err)
+			continue
+		}
+		renewed++
+
+		oldExpiry := "N/A"
+		if !d.NotAfter.IsZero() {
+			oldExpiry = d.NotAfter.Format("2006-01-02")
+		}
+		details := fmt.Sprintf(
+			"📅 <b>舊到期日</b>: %s\n"+
+				"📅 <b>新到期日</b>: <code>%s</code>\n"+
+				"⏳ <b>剩餘天數</b>: <b>%d 天</b>\n"+
+				"🔒 <b>發行商</b>: %s",
+			oldExpiry,
+			newCert.NotAfter.Format("2006-01-02"),
+			newCert.DaysRemaining,
+			newCert.Issuer,
+		)
+		s.Notifier.NotifyOperation(ctx, EventRenew, d.DomainName, details)
+	}
+
+	logrus.Infof("🏁 [Renew] 自動續簽完成 | 成功: %d, 失敗: %d, 未到期: %d", renewed, failed, skipped)
+}
+
 // notifySyncResult 發送同步結果通知
 func (s *CronService) notifySyncResult(stats SyncStats) {
 	ctx := context.Background()
@@ -1120,12 +1383,13 @@ func (s *CronService) notifySyncResult(stats SyncStats) {
 	// }
 
 	s.Notifier.NotifyTaskFinish(ctx, EventSyncFinish, TaskSummaryData{
-		Added:    stats.Added,
-		Updated:  stats.Updated,
-		Deleted:  stats.Deleted,
-		Skipped:  stats.Skipped,
-		Duration: stats.Duration,
-		Details:  detailsBuilder.String(),
+		Added:     stats.Added,
+		Updated:   stats.Updated,
+		Deleted:   stats.Deleted,
+		Skipped:   stats.Skipped,
+		Unchanged: stats.Unchanged,
+		Duration:  stats.Duration,
+		Details:   detailsBuilder.String(),
 	})
 
 	// --- 2. 發送「新增」詳情 (如果有) ---
@@ -1182,8 +1446,8 @@ func formatList(names []string, limit int) string {
 	return strings.Join(names[:limit], "\n- ") + fmt.Sprintf("\n...及其他 %d 個", remaining)
 }
 
-// checkCFDiff 比對 Cloudflare 設定差異
-func (s *CronService) checkCFDiff(old, new domain.SSLCertificate) []string {
+// checkCFDiff 比對 Cloudflare 設定差異 (同步與 API 寫回共用)
+func checkCFDiff(old, new domain.SSLCertificate) []string {
 	var changes []string
 
 	if old.CFOriginValue != new.CFOriginValue {
@@ -1217,6 +1481,79 @@ func (s *CronService) checkCFDiff(old, new domain.SSLCertificate) []string {
 	return changes
 }
 
+// recordChanged 判斷同步到的紀錄是否需要重新掃描 (增量同步)
+// Provider 提供修改時間時以它為準；沒有時退回比對 DNS 欄位。尚未掃描完成 (pending) 的域名一律重掃
+func recordChanged(existing, fetched domain.SSLCertificate) bool {
+	if existing.Status == "pending" {
+		return true
+	}
+	if !fetched.RecordModifiedOn.IsZero() && !fetched.RecordModifiedOn.Equal(existing.RecordModifiedOn) {
+		return true
+	}
+	if len(checkCFDiff(existing, fetched)) > 0 || existing.CFComment != fetched.CFComment {
+		return true
+	}
+	return existing.Provider != fetched.Provider || existing.ZoneID != fetched.ZoneID ||
+		existing.ZoneName != fetched.ZoneName || existing.RecordID != fetched.RecordID ||
+		existing.CFAccountID != fetched.CFAccountID
+}
+
+// recordDNSChange將紀錄變更寫入 ChangeHistory，Provider 支援時一併從稽核紀錄查出變更者
+func (s *CronService) recordDNSChange(ctx context.Context, providers []DNSProvider, old, new domain.SSLCertificate) domain.DNSChange {
+	change := newDNSChange(old, new, s.lookupChangeActor(ctx, providers, new))
+	if err := s.Repo.AppendChangeHistory(ctx, new.ID, change); err != nil {
+		logrus.Errorf("❌ [DB Error] 寫入變更紀錄失敗 %s: %v", new.DomainName, err)
+	}
+	return change
+}
+
+// lookupChangeActor 向擁有此紀錄的 Provider 查詢變更者，查不到 (或權限不足) 時回傳 nil
+func (s *CronService) lookupChangeActor(ctx context.Context, providers []DNSProvider, cert domain.SSLCertificate) *domain.DNSChangeActor {
+	if cert.RecordID == "" {
+		return nil
+	}
+	zone := DNSZone{ID: cert.ZoneID, Name: cert.ZoneName}
+	for _, p := range providers {
+		reader, ok := p.(RecordAuditReader)
+		if !ok || !p.Owns(cert) {
+			continue
+		}
+		actor, err := reader.RecordChangeActor(ctx, zone, cert.RecordID)
+		if err != nil {
+			logrus.Warnf("⚠️ [Audit] %s 查詢 %s 的稽核紀錄失敗: %v", p.Label(), cert.DomainName, err)
+			continue
+		}
+		if actor != nil {
+			return actor
+		}
+	}
+	return nil
+}
+
+// newDNSChange 由變更前後的域名資料建立變更紀錄
+func newDNSChange(old, new domain.SSLCertificate, actor *domain.DNSChangeActor) domain.DNSChange {
+	return domain.DNSChange{
+		DetectedAt: time.Now(),
+		OldType:    old.CFRecordType,
+		NewType:    new.CFRecordType,
+		OldContent: old.CFOriginValue,
+		NewContent: new.CFOriginValue,
+		OldProxied: old.IsProxied,
+		NewProxied: new.IsProxied,
+		Actor:      actor,
+	}
+}
+
+// formatChangeActor 變更通知中的變更者資訊
+func formatChangeActor(actor *domain.DNSChangeActor) string {
+	who := actor.Email
+	if who == "" {
+		who = actor.Type // API Token 等非使用者操作沒有 Email
+	}
+	return fmt.Sprintf("👤 <b>變更者</b>: %s (IP: <code>%s</code>)\n🕒 <b>變更時間</b>: %s",
+		who, actor.IP, actor.ChangedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
 func (s *CronService) sendBatchDetails(ctx context.Context, title string, items []string) {
 	const batchSize = 20 // 每則訊息最多顯示 20 筆，避免 Telegram/Slack 限制
 
@@ -1248,7 +1585,7 @@ func (s *CronService) sendBatchDetails(ctx context.Context, title string, items
 	}
 }
 
-func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate) {
+func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate, scope *syncScope) {
 	// newZonesMap := make(map[string]bool) // 用來儲存新 Zone
 
 	// 1. 提取 Cloudflare 目前所有的 Zone (New)
@@ -1259,11 +1596,15 @@ func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.
 		}
 	}
 
-	// 2. 提取 DB 目前所有的 Zone (Old)
+	// 2. 提取 DB 目前所有的 Zone (Old)，並記錄哪些 Zone 在本次同步範圍內
 	dbZoneMap := make(map[string]bool)
+	coveredZones := make(map[string]bool)
 	for _, d := range dbDomains {
 		if d.ZoneName != "" {
 			dbZoneMap[d.ZoneName] = true
+			if scope.covers(d) {
+				coveredZones[d.ZoneName] = true
+			}
 		}
 	}
 
@@ -1274,11 +1615,12 @@ func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.
 			// newZonesMap[zone] = true
 
 			subCount := countSubdomains(cfDomains, zone)
+			source := scope.sourceLabel(zoneDomains(cfDomains, zone))
 			details := fmt.Sprintf(
-				"來源: Cloudflare Sync\n"+
-					"偵測到新的主域名已加入 Cloudflare，將自動納入監控。\n"+
+				"來源: %s Sync\n"+
+					"偵測到新的主域名已加入 %s，將自動納入監控。\n"+
 					"包含子域名數量: %d 個\n"+
-					"(為避免打擾，該主域名下的子域名新增通知已自動靜音 🔕)", subCount)
+					"(為避免打擾，該主域名下的子域名新增通知已自動靜音 🔕)", source, source, subCount)
 
 			s.Notifier.NotifyOperation(ctx, EventZoneAdd, zone, details)
 			logrus.Infof("🌍 [Zone] 發現新主域名: %s (靜音子域名通知)", zone)
@@ -1287,11 +1629,18 @@ func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.
 
 	// 4. 檢查移除的 Zone
 	for zone := range dbZoneMap {
-		if !cfZoneMap[zone] {
+		if !cfZoneMap[zone] && coveredZones[zone] {
+			var removed []domain.SSLCertificate
+			for _, d := range zoneDomains(dbDomains, zone) {
+				if scope.covers(d) {
+					removed = append(removed, d)
+				}
+			}
+			source := scope.sourceLabel(removed)
 			details := fmt.Sprintf(
-				"來源: Cloudflare Sync\n"+
-					"該主域名已從 Cloudflare 移除，系統將自動清理相關子域名。\n"+
-					"影響子域名數量: %d 個", countSubdomains(dbDomains, zone))
+				"來源: %s Sync\n"+
+					"該主域名已從 %s 移除，系統將自動清理相關子域名。\n"+
+					"影響子域名數量: %d 個", source, source, countSubdomains(dbDomains, zone))
 
 			s.Notifier.NotifyOperation(ctx, EventZoneDelete, zone, details)
 			logrus.Infof("💥 [Zone] 主域名已移除: %s", zone)
@@ -1300,6 +1649,16 @@ func (s *CronService) detectZoneChanges(ctx context.Context, cfDomains []domain.
 	// return newZonesMap // 回傳新 Zone 列表
 }
 
+func zoneDomains(domains []domain.SSLCertificate, zoneName string) []domain.SSLCertificate {
+	var list []domain.SSLCertificate
+	for _, d := range domains {
+		if d.ZoneName == zoneName {
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
 func countSubdomains(domains []domain.SSLCertificate, zoneName string) int {
 	count := 0
 	for _, d := range domains {
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSyncNotificationsNameTheProvider(t *testing.T) {
+	record := func(name, zone string) domain.SSLCertificate {
+		return domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: name, ZoneName: zone, Provider: domain.ProviderRoute53}
+	}
+	repo := &memDomainRepo{
+		domains: []domain.SSLCertificate{
+			record("www.example.com", "example.com"),
+			record("old.example.com", "example.com"),
+			record("www.retired.com", "retired.com"),
+		},
+		settings: domain.NotificationSettings{
+			WebhookEnabled:     true,
+			WebhookURL:         "http://hooks.invalid/sync",
+			NotifyOnDelete:     true,
+			NotifyOnZoneAdd:    true,
+			NotifyOnZoneDelete: true,
+		},
+	}
+	notifier := &NotifierService{Repo: repo, tgQueue: make(chan telegramJob, 10), webhookQueue: make(chan webhookJob, 10)}
+	cron := &CronService{Repo: repo, Notifier: notifier}
+	ctx := context.Background()
+
+	// Route 53 上 old.example.com 與 retired.com 已刪除，新增 new.net
+	fetched := []domain.SSLCertificate{record("www.example.com", "example.com"), record("www.new.net", "new.net")}
+	db := append([]domain.SSLCertificate(nil), repo.domains...)
+	results := []providerSyncResult{{Provider: &route53DNSProvider{}, Source: &Route53Service{}}}
+	scope := cron.recordProviderResults(ctx, results, fetched, db)
+
+	cron.detectZoneChanges(ctx, fetched, db, scope)
+	var stats SyncStats
+	cron.processDeletions(ctx, fetched, db, scope, &stats)
+
+	msgs := drainWebhooks(notifier, 4)
+	if len(msgs) != 4 {
+		t.Fatalf("got %d notifications, want 2 zone changes + 2 deletions: %q", len(msgs), msgs)
+	}
+	for _, msg := range msgs {
+		if strings.Contains(msg, "Cloudflare") || !strings.Contains(msg, "route53") {
+			t.Errorf("notification does not name the provider: %q", msg)
+		}
+	}
+}
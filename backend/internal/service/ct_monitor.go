@@ -0,0 +1,364 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCTBatchSize  = 256
+	defaultCTMaxEntries = 100000
+)
+
+// CTMonitorService 輪詢 Certificate Transparency Log (RFC 6962 get-sth / get-entries)
+// 找出涵蓋我們 Zone 的憑證，Issuer 不在允許清單時發送 EventUnexpectedIssuance
+type CTMonitorService struct {
+	Config   conf.CTConfig
+	Repo     repository.CTRepository
+	Domains  repository.DomainRepository
+	Notifier *NotifierService
+
+	client  *http.Client
+	running sync.Mutex // 避免排程與手動觸發同時讀取同一個 Log
+}
+
+func NewCTMonitorService(cfg conf.CTConfig, repo repository.CTRepository, domains repository.DomainRepository, notify *NotifierService) *CTMonitorService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultCTBatchSize
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultCTMaxEntries
+	}
+	return &CTMonitorService{
+		Config:   cfg,
+		Repo:     repo,
+		Domains:  domains,
+		Notifier: notify,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ctEntry get-entries 回傳的單筆資料 (JSON 中為 Base64，[]byte 會自動解碼)
+type ctEntry struct {
+	LeafInput []byte `json:"leaf_input"`
+	ExtraData []byte `json:"extra_data"`
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Poll 從每個 Log 上次的位置讀到目前的 Tree Size (單次最多 MaxEntries 筆，剩下的下次排程繼續)
+// 第一次讀取某個 Log 時只記錄目前的 Tree Size，不回溯歷史資料
+func (s *CTMonitorService) Poll(ctx context.Context) error {
+	if len(s.Config.Logs) == 0 {
+		return errors.New("尚未設定 CT Log (ct.logs)")
+	}
+	if !s.running.TryLock() {
+		return errors.New("CT 監控正在執行中")
+	}
+	defer s.running.Unlock()
+
+	settings, err := s.Domains.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	zones, err := s.Domains.GetUniqueZones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneSet := make(map[string]bool, len(zones))
+	for _, z := range zones {
+		if z = strings.ToLower(strings.TrimSuffix(z, ".")); z != "" {
+			zoneSet[z] = true
+		}
+	}
+	if len(zoneSet) == 0 {
+		logrus.Info("ℹ️ [CT] 沒有任何 Zone，略過")
+		return nil
+	}
+
+	var errs []error
+	for _, l := range s.Config.Logs {
+		if err := s.pollLog(ctx, l, zoneSet, settings.CTAllowedIssuers); err != nil {
+			logrus.Errorf("❌ [CT] %s 讀取失敗: %v", l.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", l.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// pollLog 讀取單一 Log；每批處理完即保存進度，失敗時下次從中斷處繼續
+func (s *CTMonitorService) pollLog(ctx context.Context, l conf.CTLogConfig, zones map[string]bool, allowed []string) error {
+	baseURL := strings.TrimSuffix(l.URL, "/")
+
+	state, err := s.Repo.GetLogState(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+
+	treeSize, err := s.getSTH(ctx, baseURL)
+	if err != nil {
+		if state != nil {
+			state.LastPollAt = time.Now()
+			state.LastError = err.Error()
+			_ = s.Repo.SaveLogState(ctx, *state)
+		}
+		return err
+	}
+
+	if state == nil {
+		logrus.Infof("🆕 [CT] %s 首次讀取，從 Tree Size %d 開始監控", l.Name, treeSize)
+		return s.Repo.SaveLogState(ctx, domain.CTLogState{
+			URL: baseURL, Name: l.Name, NextIndex: treeSize, TreeSize: treeSize, LastPollAt: time.Now(),
+		})
+	}
+	state.Name = l.Name
+	state.TreeSize = treeSize
+	state.LastError = ""
+
+	end := treeSize
+	if end-state.NextIndex > s.Config.MaxEntries {
+		end = state.NextIndex + s.Config.MaxEntries
+	}
+
+	var read, matched, unexpected int
+	for state.NextIndex < end {
+		last := state.NextIndex + int64(s.Config.BatchSize) - 1
+		if last >= end {
+			last = end - 1
+		}
+
+		entries, err := s.getEntries(ctx, baseURL, state.NextIndex, last)
+		if err == nil && len(entries) == 0 {
+			err = errors.New("get-entries 沒有回傳任何資料")
+		}
+		if err != nil {
+			state.LastError = err.Error()
+			break
+		}
+
+		for i, e := range entries {
+			m, u := s.checkEntry(ctx, l.Name, state.NextIndex+int64(i), e, zones, allowed)
+			if m {
+				matched++
+			}
+			if u {
+				unexpected++
+			}
+		}
+		// Log 可能回傳少於要求的筆數，依實際筆數前進
+		state.NextIndex += int64(len(entries))
+		read += len(entries)
+
+		state.LastPollAt = time.Now()
+		if err := s.Repo.SaveLogState(ctx, *state); err != nil {
+			return err
+		}
+	}
+
+	state.LastPollAt = time.Now()
+	if err := s.Repo.SaveLogState(ctx, *state); err != nil {
+		return err
+	}
+
+	logrus.Infof("🔎 [CT] %s: 讀取 %d 筆，符合 Zone %d 張 (非預期 %d 張)，進度 %d / %d", l.Name, read, matched, unexpected, state.NextIndex, treeSize)
+	if state.LastError != "" {
+		return errors.New(state.LastError)
+	}
+	if state.NextIndex < treeSize {
+		logrus.Warnf("⚠️ [CT] %s 尚有 %d 筆未讀取，下次排程繼續", l.Name, treeSize-state.NextIndex)
+	}
+	return nil
+}
+
+// checkEntry 比對單筆 Entry，回傳 (是否涵蓋我們的 Zone, 是否為新發現的非預期簽發)
+func (s *CTMonitorService) checkEntry(ctx context.Context, logName string, index int64, e ctEntry, zones map[string]bool, allowed []string) (bool, bool) {
+	cert, isPrecert, err := parseCTEntry(e)
+	if err != nil {
+		logrus.Debugf("[CT] %s #%d 無法解析: %v", logName, index, err)
+		return false, false
+	}
+
+	names := cert.DNSNames
+	if len(names) == 0 && cert.Subject.CommonName != "" {
+		names = []string{cert.Subject.CommonName}
+	}
+	matchedZones := matchZones(names, zones)
+	if len(matchedZones) == 0 {
+		return false, false
+	}
+
+	issuance := domain.CTIssuance{
+		Issuer:       cert.Issuer.String(),
+		IssuerName:   certIssuerName(cert),
+		SerialNumber: fmt.Sprintf("%X", cert.SerialNumber),
+		DNSNames:     names,
+		MatchedZones: matchedZones,
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		IsPrecert:    isPrecert,
+		LogName:      logName,
+		LogIndex:     index,
+		Allowed:      issuerAllowed(cert.Issuer.String(), allowed),
+	}
+	inserted, err := s.Repo.InsertIssuance(ctx, issuance)
+	if err != nil {
+		logrus.Errorf("❌ [CT] 寫入失敗 %s: %v", issuance.SerialNumber, err)
+		return true, false
+	}
+	// 同一張憑證的 Precert 與正式憑證 (或出現在多個 Log) 只通知一次
+	if !inserted || issuance.Allowed {
+		return true, false
+	}
+
+	logrus.Warnf("🚨 [CT] 非預期的憑證簽發: %v (Issuer: %s, Serial: %s)", names, issuance.IssuerName, issuance.SerialNumber)
+	details := fmt.Sprintf("Issuer: %s\nSerial: %s\nSANs: %s\n有效期: %s ~ %s\nCT Log: %s #%d",
+		issuance.Issuer, issuance.SerialNumber, strings.Join(names, ", "),
+		cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"), logName, index)
+	s.Notifier.NotifyOperation(ctx, EventUnexpectedIssuance, names[0], details)
+	return true, true
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+func (s *CTMonitorService) getSTH(ctx context.Context, baseURL string) (int64, error) {
+	var sth struct {
+		TreeSize int64 `json:"tree_size"`
+	}
+	if err := s.getJSON(ctx, baseURL+"/ct/v1/get-sth", &sth); err != nil {
+		return 0, fmt.Errorf("get-sth: %w", err)
+	}
+	return sth.TreeSize, nil
+}
+
+// getEntries start、end 皆為包含 (inclusive)
+func (s *CTMonitorService) getEntries(ctx context.Context, baseURL string, start, end int64) ([]ctEntry, error) {
+	var resp struct {
+		Entries []ctEntry `json:"entries"`
+	}
+	url := fmt.Sprintf("%s/ct/v1/get-entries?start=%d&end=%d", baseURL, start, end)
+	if err := s.getJSON(ctx, url, &resp); err != nil {
+		return nil, fmt.Errorf("get-entries: %w", err)
+	}
+	return resp.Entries, nil
+}
+
+func (s *CTMonitorService) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// parseCTEntry 解析 MerkleTreeLeaf (RFC 6962 3.4)
+// x509_entry 直接取 leaf_input 中的憑證；precert_entry 的 leaf 只有 TBSCertificate，改用 extra_data 中完整的 Precertificate
+func parseCTEntry(e ctEntry) (*x509.Certificate, bool, error) {
+	leaf := e.LeafInput
+	// version(1) + leaf_type(1) + timestamp(8) + entry_type(2)
+	if len(leaf) < 12 {
+		return nil, false, errors.New("leaf_input 長度不足")
+	}
+	if leaf[0] != 0 || leaf[1] != 0 {
+		return nil, false, fmt.Errorf("不支援的 leaf 版本/類型: %d/%d", leaf[0], leaf[1])
+	}
+
+	var (
+		der       []byte
+		isPrecert bool
+		err       error
+	)
+	switch entryType := binary.BigEndian.Uint16(leaf[10:12]); entryType {
+	case 0: // x509_entry
+		der, err = readUint24Prefixed(leaf[12:])
+	case 1: // precert_entry：PrecertChainEntry.pre_certificate
+		der, err = readUint24Prefixed(e.ExtraData)
+		isPrecert = true
+	default:
+		return nil, false, fmt.Errorf("不支援的 entry 類型: %d", entryType)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Precertificate 帶有 Critical 的 Poison Extension，Go 只會記在 UnhandledCriticalExtensions，不影響解析
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, false, err
+	}
+	return cert, isPrecert, nil
+}
+
+// readUint24Prefixed TLS 編碼的 opaque<1..2^24-1>
+func readUint24Prefixed(b []byte) ([]byte, error) {
+	if len(b) < 3 {
+		return nil, errors.New("資料長度不足")
+	}
+	n := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+	if len(b)-3 < n {
+		return nil, errors.New("長度超出資料範圍")
+	}
+	return b[3 : 3+n], nil
+}
+
+// matchZones 找出名稱所屬的 Zone (逐層往上比對，wildcard 去掉 "*.")
+func matchZones(names []string, zones map[string]bool) []string {
+	var matched []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(name, "*."), "."))
+		for candidate := name; candidate != ""; {
+			if zones[candidate] {
+				matched = appendUnique(matched, candidate)
+				break
+			}
+			i := strings.IndexByte(candidate, '.')
+			if i < 0 {
+				break
+			}
+			candidate = candidate[i+1:]
+		}
+	}
+	return matched
+}
+
+// issuerAllowed 允許清單為不分大小寫的部分字串比對 (e.g. "Let's Encrypt" 比對 "O=Let's Encrypt")
+func issuerAllowed(issuer string, allowed []string) bool {
+	issuer = strings.ToLower(issuer)
+	for _, a := range allowed {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" && strings.Contains(issuer, a) {
+			return true
+		}
+	}
+	return false
+}
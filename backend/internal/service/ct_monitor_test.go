@@ -0,0 +1,483 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RFC 6962 的 Precertificate Poison Extension
+var oidCTPoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+
+// fakeCTLog 模擬 get-sth / get-entries，每次最多回傳 pageLimit 筆 (真實 Log 也會截斷)
+type fakeCTLog struct {
+	mu        sync.Mutex
+	entries   []ctEntry
+	pageLimit int
+	failAt    int64 // >= 0 時，請求範圍包含此 index 的 get-entries 回 500
+	requests  []string
+}
+
+func newFakeCTLog(t *testing.T) (*fakeCTLog, string) {
+	f := &fakeCTLog{pageLimit: 2, failAt: -1}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv.URL + "/"
+}
+
+func (f *fakeCTLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL.Path+"?"+r.URL.RawQuery)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/ct/v1/get-sth":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"tree_size": len(f.entries), "timestamp": time.Now().UnixMilli()})
+
+	case "/ct/v1/get-entries":
+		start, err1 := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		end, err2 := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+		if err1 != nil || err2 != nil || start < 0 || end < start || start >= int64(len(f.entries)) {
+			http.Error(w, `{"error_message":"bad range"}`, http.StatusBadRequest)
+			return
+		}
+		if f.failAt >= start && f.failAt <= end {
+			http.Error(w, `{"error_message":"backend unavailable"}`, http.StatusInternalServerError)
+			return
+		}
+		if end >= int64(len(f.entries)) {
+			end = int64(len(f.entries)) - 1
+		}
+		if end-start+1 > int64(f.pageLimit) {
+			end = start + int64(f.pageLimit) - 1
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"entries": f.entries[start : end+1]})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeCTLog) add(entries ...ctEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entries...)
+}
+
+// takeRequests 回傳並清空目前為止收到的 get-entries 請求
+func (f *fakeCTLog) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var entries []string
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, "/ct/v1/get-entries") {
+			entries = append(entries, r)
+		}
+	}
+	f.requests = nil
+	return entries
+}
+
+func TestParseCTEntry(t *testing.T) {
+	ca := newCTIssuer(t, "Let's Encrypt", "R10")
+
+	cert := ca.issue(t, 1, false, "www.example.com", "example.com")
+	got, isPrecert, err := parseCTEntry(x509Entry(cert))
+	if err != nil {
+		t.Fatalf("x509_entry: %v", err)
+	}
+	if isPrecert || got.SerialNumber.Int64() != 1 || strings.Join(got.DNSNames, ",") != "www.example.com,example.com" {
+		t.Errorf("x509_entry = precert %v, serial %v, names %v", isPrecert, got.SerialNumber, got.DNSNames)
+	}
+
+	precert := ca.issue(t, 2, true, "*.example.com")
+	got, isPrecert, err = parseCTEntry(precertEntry(precert, ca.cert))
+	if err != nil {
+		t.Fatalf("precert_entry: %v", err)
+	}
+	if !isPrecert || got.SerialNumber.Int64() != 2 || strings.Join(got.DNSNames, ",") != "*.example.com" {
+		t.Errorf("precert_entry = precert %v, serial %v, names %v", isPrecert, got.SerialNumber, got.DNSNames)
+	}
+	if got.Issuer.String() != ca.cert.Subject.String() {
+		t.Errorf("precert issuer = %s, want %s", got.Issuer, ca.cert.Subject)
+	}
+
+	// 截斷、未知類型與錯誤版本都要回傳錯誤而不是 panic
+	bad := []ctEntry{
+		{LeafInput: []byte{0, 0, 1}},
+		{LeafInput: append(x509Entry(cert).LeafInput[:10:10], 0, 2)},
+		{LeafInput: append([]byte{1}, x509Entry(cert).LeafInput[1:]...)},
+		{LeafInput: x509Entry(cert).LeafInput[:20]},
+		{LeafInput: precertEntry(precert, ca.cert).LeafInput, ExtraData: []byte{0, 0xFF}},
+	}
+	for i, e := range bad {
+		if _, _, err := parseCTEntry(e); err == nil {
+			t.Errorf("bad entry %d parsed without error", i)
+		}
+	}
+}
+
+func TestCTPollTracksCursorAndNotifiesUnexpectedIssuers(t *testing.T) {
+	letsEncrypt := newCTIssuer(t, "Let's Encrypt", "R10")
+	rogue := newCTIssuer(t, "Rogue Trust", "Rogue CA")
+
+	domains := &memDomainRepo{
+		domains: []domain.SSLCertificate{{ID: primitive.NewObjectID(), DomainName: "www.example.com", ZoneName: "example.com"}},
+		settings: domain.NotificationSettings{
+			WebhookEnabled:             true,
+			WebhookURL:                 "http://hooks.invalid/ct",
+			NotifyOnUnexpectedIssuance: true,
+			CTAllowedIssuers:           []string{"let's encrypt"},
+		},
+	}
+	notifier := &NotifierService{Repo: domains, tgQueue: make(chan telegramJob, 10), webhookQueue: make(chan webhookJob, 10)}
+	repo := newMemCTRepo()
+
+	log1, url1 := newFakeCTLog(t)
+	log2, url2 := newFakeCTLog(t)
+	// 開始監控前的歷史資料不回溯
+	log1.add(x509Entry(rogue.issue(t, 100, false, "old.example.com")))
+	log2.add(x509Entry(letsEncrypt.issue(t, 200, false, "other.net")), x509Entry(letsEncrypt.issue(t, 201, false, "other.net")))
+
+	svc := NewCTMonitorService(conf.CTConfig{
+		Logs:      []conf.CTLogConfig{{Name: "one", URL: url1}, {Name: "two", URL: url2}},
+		BatchSize: 3,
+	}, repo, domains, notifier)
+	ctx := context.Background()
+
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatalf("first poll: %v", err)
+	}
+	assertCTCursor(t, repo, url1, 1)
+	assertCTCursor(t, repo, url2, 2)
+	if reqs := append(log1.takeRequests(), log2.takeRequests()...); len(reqs) != 0 {
+		t.Errorf("first poll read history: %v", reqs)
+	}
+
+	// 新資料：允許的 Issuer、非預期 Issuer 的 Precert 與其正式憑證、不相關的域名、無法解析的資料
+	rogueCert := rogue.issue(t, 101, false, "*.example.com")
+	log1.add(
+		x509Entry(letsEncrypt.issue(t, 1, false, "www.example.com")),
+		precertEntry(rogue.issue(t, 101, true, "*.example.com"), rogue.cert),
+		x509Entry(rogueCert),
+		x509Entry(rogue.issue(t, 102, false, "unrelated.net")),
+		ctEntry{LeafInput: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 9}},
+	)
+
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatalf("second poll: %v", err)
+	}
+	assertCTCursor(t, repo, url1, 6)
+	assertCTCursor(t, repo, url2, 2)
+
+	// BatchSize 3 但 Log 每次只給 2 筆，依實際筆數前進
+	want := []string{
+		"/ct/v1/get-entries?start=1&end=3",
+		"/ct/v1/get-entries?start=3&end=5",
+		"/ct/v1/get-entries?start=5&end=5",
+	}
+	if got := log1.takeRequests(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("get-entries requests = %v, want %v", got, want)
+	}
+	if got := log2.takeRequests(); len(got) != 0 {
+		t.Errorf("log two re-read entries: %v", got)
+	}
+
+	issuances := repo.list()
+	if len(issuances) != 2 {
+		t.Fatalf("recorded %d issuances, want 2: %+v", len(issuances), issuances)
+	}
+	for _, iss := range issuances {
+		switch iss.SerialNumber {
+		case "1":
+			if !iss.Allowed || iss.IsPrecert || iss.LogIndex != 1 {
+				t.Errorf("allowed issuance = %+v", iss)
+			}
+		case "65": // 0x65 = 101，Precert 先出現
+			if iss.Allowed || !iss.IsPrecert || iss.LogIndex != 2 || strings.Join(iss.MatchedZones, ",") != "example.com" {
+				t.Errorf("unexpected issuance = %+v", iss)
+			}
+		default:
+			t.Errorf("unexpected record %+v", iss)
+		}
+	}
+
+	// 只有非預期 Issuer 通知一次 (Precert 與正式憑證不重複)
+	msgs := drainWebhooks(notifier, 1)
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d notifications, want 1: %q", len(msgs), msgs)
+	}
+	if !strings.Contains(msgs[0], "Rogue CA") || !strings.Contains(msgs[0], "*.example.com") || strings.Contains(msgs[0], "R10") {
+		t.Errorf("notification = %q", msgs[0])
+	}
+
+	// 沒有新資料時只讀 STH
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := log1.takeRequests(); len(got) != 0 {
+		t.Errorf("poll without new entries fetched %v", got)
+	}
+	if msgs := drainWebhooks(notifier, 0); len(msgs) != 0 {
+		t.Errorf("extra notifications: %q", msgs)
+	}
+}
+
+func TestCTPollResumesAfterFailedBatch(t *testing.T) {
+	ca := newCTIssuer(t, "Let's Encrypt", "R10")
+	domains := &memDomainRepo{domains: []domain.SSLCertificate{{ID: primitive.NewObjectID(), DomainName: "example.com", ZoneName: "example.com"}}}
+	repo := newMemCTRepo()
+	log, url := newFakeCTLog(t)
+
+	svc := NewCTMonitorService(conf.CTConfig{Logs: []conf.CTLogConfig{{Name: "one", URL: url}}, BatchSize: 2}, repo, domains, &NotifierService{Repo: domains})
+	ctx := context.Background()
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(0); i < 5; i++ {
+		log.add(x509Entry(ca.issue(t, 10+i, false, "example.com")))
+	}
+	log.mu.Lock()
+	log.failAt = 2
+	log.mu.Unlock()
+	if err := svc.Poll(ctx); err == nil || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Fatalf("poll with failing batch: err = %v", err)
+	}
+	// 第一批 (0-1) 已保存，失敗的批次下次重讀
+	state := assertCTCursor(t, repo, url, 2)
+	if state.LastError == "" || state.TreeSize != 5 {
+		t.Errorf("state after failure = %+v", state)
+	}
+
+	log.mu.Lock()
+	log.failAt = -1
+	log.mu.Unlock()
+	log.takeRequests()
+	if err := svc.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	state = assertCTCursor(t, repo, url, 5)
+	if state.LastError != "" {
+		t.Errorf("LastError not cleared: %q", state.LastError)
+	}
+	if got := log.takeRequests(); len(got) == 0 || got[0] != "/ct/v1/get-entries?start=2&end=3" {
+		t.Errorf("resume requests = %v", got)
+	}
+	if n := len(repo.list()); n != 5 {
+		t.Errorf("recorded %d issuances, want 5", n)
+	}
+}
+
+// =============================================================================
+// Helpers
+// =============================================================================
+
+type ctIssuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCTIssuer(t *testing.T, org, cn string) ctIssuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{org}, CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctIssuer{cert: cert, key: key}
+}
+
+// issue 簽發終端憑證；precert 為 true 時加上 Critical 的 Poison Extension
+func (ca ctIssuer) issue(t *testing.T, serial int64, precert bool, names ...string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	if precert {
+		tmpl.ExtraExtensions = []pkix.Extension{{Id: oidCTPoison, Critical: true, Value: asn1.NullBytes}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// x509Entry MerkleTreeLeaf：version(0) leaf_type(0) timestamp entry_type(0) ASN.1Cert
+func x509Entry(cert *x509.Certificate) ctEntry {
+	leaf := ctLeafHeader(0)
+	leaf = append(leaf, uint24Prefixed(cert.Raw)...)
+	leaf = append(leaf, 0, 0) // extensions
+	return ctEntry{LeafInput: leaf, ExtraData: uint24Prefixed(nil)}
+}
+
+// precertEntry leaf 為 issuer_key_hash + TBSCertificate，完整的 Precertificate 放在 extra_data
+func precertEntry(precert, issuer *x509.Certificate) ctEntry {
+	keyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	leaf := ctLeafHeader(1)
+	leaf = append(leaf, keyHash[:]...)
+	leaf = append(leaf, uint24Prefixed(precert.RawTBSCertificate)...)
+	leaf = append(leaf, 0, 0)
+
+	extra := uint24Prefixed(precert.Raw)
+	extra = append(extra, uint24Prefixed(uint24Prefixed(issuer.Raw))...)
+	return ctEntry{LeafInput: leaf, ExtraData: extra}
+}
+
+func ctLeafHeader(entryType uint16) []byte {
+	leaf := []byte{0, 0}
+	leaf = binary.BigEndian.AppendUint64(leaf, uint64(time.Now().UnixMilli()))
+	return binary.BigEndian.AppendUint16(leaf, entryType)
+}
+
+func uint24Prefixed(b []byte) []byte {
+	return append([]byte{byte(len(b) >> 16), byte(len(b) >> 8), byte(len(b))}, b...)
+}
+
+func assertCTCursor(t *testing.T, repo *memCTRepo, url string, next int64) domain.CTLogState {
+	t.Helper()
+	state, _ := repo.GetLogState(context.Background(), strings.TrimSuffix(url, "/"))
+	if state == nil {
+		t.Fatalf("no state for %s", url)
+	}
+	if state.NextIndex != next {
+		t.Errorf("%s NextIndex = %d, want %d", url, state.NextIndex, next)
+	}
+	return *state
+}
+
+// drainWebhooks 通知是非同步入列，等到收到 want 筆 (或逾時) 後再多等一下確認沒有多餘的
+func drainWebhooks(n *NotifierService, want int) []string {
+	var msgs []string
+	timeout := time.After(2 * time.Second)
+	for {
+		wait := 200 * time.Millisecond
+		if len(msgs) < want {
+			wait = 2 * time.Second
+		}
+		select {
+		case job := <-n.webhookQueue:
+			msgs = append(msgs, job.Message)
+		case <-time.After(wait):
+			return msgs
+		case <-timeout:
+			return msgs
+		}
+	}
+}
+
+type memCTRepo struct {
+	mu        sync.Mutex
+	states    map[string]domain.CTLogState
+	issuances map[string]domain.CTIssuance
+}
+
+func newMemCTRepo() *memCTRepo {
+	return &memCTRepo{states: make(map[string]domain.CTLogState), issuances: make(map[string]domain.CTIssuance)}
+}
+
+func (m *memCTRepo) GetLogState(_ context.Context, url string) (*domain.CTLogState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[url]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (m *memCTRepo) SaveLogState(_ context.Context, state domain.CTLogState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[state.URL] = state
+	return nil
+}
+
+func (m *memCTRepo) ListLogStates(context.Context) ([]domain.CTLogState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var states []domain.CTLogState
+	for _, s := range m.states {
+		states = append(states, s)
+	}
+	return states, nil
+}
+
+func (m *memCTRepo) InsertIssuance(_ context.Context, issuance domain.CTIssuance) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := issuance.Issuer + "|" + issuance.SerialNumber
+	if _, ok := m.issuances[key]; ok {
+		return false, nil
+	}
+	m.issuances[key] = issuance
+	return true, nil
+}
+
+func (m *memCTRepo) ListIssuances(context.Context, int64) ([]domain.CTIssuance, error) {
+	return m.list(), nil
+}
+
+func (m *memCTRepo) list() []domain.CTIssuance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []domain.CTIssuance
+	for _, iss := range m.issuances {
+		list = append(list, iss)
+	}
+	return list
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"bytes"
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultHookTimeout = 5 * time.Minute
+
+// Kubernetes 的 Namespace / Secret 名稱 (DNS-1123 label)，直接寫入 Manifest 前必須符合
+var dns1123Label = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// 檔案部署的檔名 (與 certbot 的 live 目錄慣例相同)
+const (
+	deployCertFile      = "cert.pem"
+	deployChainFile     = "chain.pem"
+	deployFullChainFile = "fullchain.pem"
+	deployKeyFile       = "privkey.pem"
+)
+
+// DeployResult 單一目標的部署結果
+type DeployResult struct {
+	TargetID string `json:"target_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// deployBundle 部署時需要的憑證內容
+type deployBundle struct {
+	Cert      []byte
+	Chain     []byte
+	FullChain []byte
+	Key       []byte
+}
+
+// DeployService 將保管的憑證部署到各種目標 (檔案 / Hook / Cloudflare / Kubernetes)
+type DeployService struct {
+	Repo        repository.DeployTargetRepository
+	DomainRepo  repository.DomainRepository
+	Store       *CertStoreService
+	CFService   *CloudflareService
+	Notifier    *NotifierService
+	Hooks       map[string]string // 名稱 -> 指令 (config.yaml deploy.hooks)
+	Directories map[string]string // 名稱 -> 絕對路徑 (config.yaml deploy.directories)
+}
+
+func NewDeployService(repo repository.DeployTargetRepository, domainRepo repository.DomainRepository, store *CertStoreService, cf *CloudflareService, notifier *NotifierService, cfg conf.DeployConfig) *DeployService {
+	s := &DeployService{
+		Repo:        repo,
+		DomainRepo:  domainRepo,
+		Store:       store,
+		CFService:   cf,
+		Notifier:    notifier,
+		Hooks:       make(map[string]string),
+		Directories: make(map[string]string),
+	}
+	for _, h := range cfg.Hooks {
+		if h.Name == "" || strings.TrimSpace(h.Command) == "" {
+			logrus.Warnf("⚠️ [Deploy] 略過設定不完整的 Hook: %q", h.Name)
+			continue
+		}
+		s.Hooks[h.Name] = h.Command
+	}
+	for _, d := range cfg.Directories {
+		if d.Name == "" || !filepath.IsAbs(d.Path) {
+			logrus.Warnf("⚠️ [Deploy] 略過設定不完整的目錄 (需為絕對路徑): %q", d.Name)
+			continue
+		}
+		s.Directories[d.Name] = filepath.Clean(d.Path)
+	}
+	return s
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// DeployCertificate 將憑證部署到該域名所有啟用中的目標
+func (s *DeployService) DeployCertificate(ctx context.Context, mc *domain.ManagedCertificate) []DeployResult {
+	targets, err := s.Repo.ListByDomainID(ctx, mc.DomainID)
+	if err != nil {
+		logrus.Errorf("❌ [Deploy] 讀取 %s 的部署目標失敗: %v", mc.DomainName, err)
+		return nil
+	}
+
+	var results []DeployResult
+	for i := range targets {
+		if !targets[i].Enabled {
+			continue
+		}
+		results = append(results, s.DeployTo(ctx, &targets[i], mc))
+	}
+	return results
+}
+
+// DeployTo 部署到單一目標，並記錄結果與發送通知
+func (s *DeployService) DeployTo(ctx context.Context, target *domain.DeployTarget, mc *domain.ManagedCertificate) DeployResult {
+	result := DeployResult{TargetID: target.ID.Hex(), Name: target.Name, Type: target.Type, Status: domain.DeployStatusSuccess}
+
+	cfCertID, err := s.deploy(ctx, target, mc)
+	if err != nil {
+		result.Status = domain.DeployStatusFailed
+		result.Error = err.Error()
+		logrus.Errorf("❌ [Deploy] %s -> %s (%s) 失敗: %v", mc.DomainName, target.Name, target.Type, err)
+	} else {
+		logrus.Infof("🚚 [Deploy] %s -> %s (%s) 完成", mc.DomainName, target.Name, target.Type)
+	}
+
+	if err := s.Repo.UpdateResult(ctx, target.ID, result.Status, result.Error, mc.SerialNumber, cfCertID); err != nil {
+		logrus.Errorf("❌ [Deploy] 寫入部署結果失敗: %v", err)
+	}
+
+	details := fmt.Sprintf("🎯 目標: %s (%s)\n🔢 序號: <code>%s</code>\n✅ 結果: 成功", target.Name, target.Type, mc.SerialNumber)
+	if result.Error != "" {
+		details = fmt.Sprintf("🎯 目標: %s (%s)\n🔢 序號: <code>%s</code>\n❌ 結果: 失敗\n📝 錯誤: %s", target.Name, target.Type, mc.SerialNumber, result.Error)
+	}
+	s.Notifier.NotifyOperation(ctx, EventDeploy, mc.DomainName, details)
+
+	return result
+}
+
+// ValidateTarget 檢查部署目標的必要欄位，指令與目錄只能引用設定檔中的名稱
+func (s *DeployService) ValidateTarget(target *domain.DeployTarget) error {
+	if target.Name == "" {
+		target.Name = target.Type
+	}
+
+	switch target.Type {
+	case domain.DeployTypeFile:
+		if _, err := s.directory(target.Directory); err != nil {
+			return err
+		}
+		if target.FileMode != "" {
+			if _, err := parseFileMode(target.FileMode); err != nil {
+				return err
+			}
+		}
+	case domain.DeployTypeHook:
+		if _, err := s.hookCommand(target.Hook); err != nil {
+			return err
+		}
+		if target.Directory != "" {
+			if _, err := s.directory(target.Directory); err != nil {
+				return err
+			}
+		}
+	case domain.DeployTypeK8sSecret:
+		if _, err := s.directory(target.Directory); err != nil {
+			return err
+		}
+		if target.Namespace == "" {
+			target.Namespace = "default"
+		}
+		if err := checkDNS1123Label("namespace", target.Namespace); err != nil {
+			return err
+		}
+		if target.SecretName != "" {
+			if err := checkDNS1123Label("secret_name", target.SecretName); err != nil {
+				return err
+			}
+		}
+	case domain.DeployTypeCloudflare:
+	default:
+		return fmt.Errorf("不支援的部署類型: %s", target.Type)
+	}
+	return nil
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// deploy 依類型執行部署，Cloudflare 目標會回傳 Custom Certificate ID
+func (s *DeployService) deploy(ctx context.Context, target *domain.DeployTarget, mc *domain.ManagedCertificate) (string, error) {
+	if !mc.RevokedAt.IsZero() {
+		return "", fmt.Errorf("憑證已撤銷，不進行部署")
+	}
+
+	_, keyPEM, err := s.Store.PrivateKey(mc)
+	if err != nil {
+		return "", err
+	}
+	bundle := deployBundle{
+		Cert:      []byte(mc.CertPEM),
+		Chain:     []byte(mc.ChainPEM),
+		FullChain: []byte(mc.CertPEM + mc.ChainPEM),
+		Key:       keyPEM,
+	}
+
+	switch target.Type {
+	case domain.DeployTypeFile:
+		return "", s.deployFiles(target, bundle)
+	case domain.DeployTypeHook:
+		return "", s.deployHook(ctx, target, mc, bundle)
+	case domain.DeployTypeCloudflare:
+		return s.deployCloudflare(ctx, target, mc, bundle)
+	case domain.DeployTypeK8sSecret:
+		return "", s.deployK8sSecret(target, mc, bundle)
+	default:
+		return "", fmt.Errorf("不支援的部署類型: %s", target.Type)
+	}
+}
+
+// deployFiles 寫入 cert / chain / fullchain / privkey 四個 PEM 檔
+func (s *DeployService) deployFiles(target *domain.DeployTarget, bundle deployBundle) error {
+	dir, err := s.directory(target.Directory)
+	if err != nil {
+		return err
+	}
+	certMode := os.FileMode(0o644)
+	if target.FileMode != "" {
+		mode, err := parseFileMode(target.FileMode)
+		if err != nil {
+			return err
+		}
+		certMode = mode
+	}
+	return writeBundleFiles(dir, bundle, certMode)
+}
+
+// deployHook 執行設定檔中的指令，憑證路徑與資訊透過環境變數傳入
+// 沒有設定 Directory 時寫到暫存目錄，指令結束後刪除
+func (s *DeployService) deployHook(ctx context.Context, target *domain.DeployTarget, mc *domain.ManagedCertificate, bundle deployBundle) error {
+	command, err := s.hookCommand(target.Hook)
+	if err != nil {
+		return err
+	}
+
+	var dir string
+	if target.Directory != "" {
+		if dir, err = s.directory(target.Directory); err != nil {
+			return err
+		}
+	} else {
+		tmp, err := os.MkdirTemp("", "cert-deploy-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+	if err := writeBundleFiles(dir, bundle, 0o644); err != nil {
+		return err
+	}
+
+	timeout := defaultHookTimeout
+	if target.TimeoutSeconds > 0 {
+		timeout = time.Duration(target.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"CERT_DOMAIN="+mc.DomainName,
+		"CERT_SERIAL="+mc.SerialNumber,
+		"CERT_FINGERPRINT="+mc.Fingerprint,
+		"CERT_NOT_AFTER="+mc.NotAfter.Format(time.RFC3339),
+		"CERT_DIR="+dir,
+		"CERT_FILE="+filepath.Join(dir, deployCertFile),
+		"CHAIN_FILE="+filepath.Join(dir, deployChainFile),
+		"FULLCHAIN_FILE="+filepath.Join(dir, deployFullChainFile),
+		"KEY_FILE="+filepath.Join(dir, deployKeyFile),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		out := strings.TrimSpace(string(output))
+		if len(out) > 500 {
+			out = out[len(out)-500:]
+		}
+		return fmt.Errorf("指令執行失敗: %w (輸出: %s)", err, out)
+	}
+	return nil
+}
+
+// deployCloudflare 上傳為 Zone 的 Custom Certificate (已上傳過則更新)
+func (s *DeployService) deployCloudflare(ctx context.Context, target *domain.DeployTarget, mc *domain.ManagedCertificate, bundle deployBundle) (string, error) {
+	owner, err := s.DomainRepo.GetByID(ctx, mc.DomainID)
+	if err != nil {
+		return "", fmt.Errorf("找不到對應的域名: %w", err)
+	}
+	if owner.Provider != domain.ProviderCloudflare || owner.ZoneID == "" {
+		return "", fmt.Errorf("%s 不是 Cloudflare 管理的域名", owner.DomainName)
+	}
+
+	api, err := s.CFService.clientFor(ctx, owner.CFAccountID)
+	if err != nil {
+		return "", err
+	}
+
+	opts := cloudflare.ZoneCustomSSLOptions{
+		Certificate:  string(bundle.FullChain),
+		PrivateKey:   string(bundle.Key),
+		BundleMethod: "force", // 使用我們提供的中繼憑證
+		Type:         "sni_custom",
+	}
+
+	if target.CFCustomCertID != "" {
+		res, err := api.UpdateSSL(ctx, owner.ZoneID, target.CFCustomCertID, opts)
+		if err == nil {
+			return res.ID, nil
+		}
+		// 可能已在 Dashboard 被刪除，改為重新上傳
+		logrus.Warnf("⚠️ [Deploy] 更新 Custom Certificate %s 失敗，改為新增: %v", target.CFCustomCertID, err)
+	}
+
+	res, err := api.CreateSSL(ctx, owner.ZoneID, opts)
+	if err != nil {
+		return "", fmt.Errorf("上傳 Custom Certificate 失敗: %w", err)
+	}
+	return res.ID, nil
+}
+
+// deployK8sSecret 產生 kubernetes.io/tls Secret 的 Manifest (交給 kubectl apply / GitOps)
+func (s *DeployService) deployK8sSecret(target *domain.DeployTarget, mc *domain.ManagedCertificate, bundle deployBundle) error {
+	dir, err := s.directory(target.Directory)
+	if err != nil {
+		return err
+	}
+	name := target.SecretName
+	if name == "" {
+		name = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(mc.DomainName, "*", "wildcard"), ".", "-")) + "-tls"
+	}
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	// 名稱直接寫入 YAML 與檔名，部署時再檢查一次 (由域名產生的名稱或資料庫中的舊設定)
+	if err := checkDNS1123Label("secret_name", name); err != nil {
+		return err
+	}
+	if err := checkDNS1123Label("namespace", namespace); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("apiVersion: v1\n")
+	buf.WriteString("kind: Secret\n")
+	buf.WriteString("type: kubernetes.io/tls\n")
+	buf.WriteString("metadata:\n")
+	fmt.Fprintf(&buf, "  name: %s\n", name)
+	fmt.Fprintf(&buf, "  namespace: %s\n", namespace)
+	buf.WriteString("  annotations:\n")
+	fmt.Fprintf(&buf, "    cert-manager.local/domain: %q\n", mc.DomainName)
+	fmt.Fprintf(&buf, "    cert-manager.local/serial: %q\n", mc.SerialNumber)
+	fmt.Fprintf(&buf, "    cert-manager.local/not-after: %q\n", mc.NotAfter.Format(time.RFC3339))
+	buf.WriteString("data:\n")
+	fmt.Fprintf(&buf, "  tls.crt: %s\n", base64.StdEncoding.EncodeToString(bundle.FullChain))
+	fmt.Fprintf(&buf, "  tls.key: %s\n", base64.StdEncoding.EncodeToString(bundle.Key))
+
+	filename := fmt.Sprintf("%s-%s.yaml", namespace, name)
+	return writeFileAtomic(filepath.Join(dir, filename), buf.Bytes(), 0o600)
+}
+
+// hookCommand 依名稱取得設定檔中的 Hook 指令
+func (s *DeployService) hookCommand(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("hook 不可為空")
+	}
+	command, ok := s.Hooks[name]
+	if !ok {
+		return "", fmt.Errorf("hook %q 未在 config.yaml 的 deploy.hooks 設定", name)
+	}
+	return command, nil
+}
+
+// directory 依名稱取得設定檔中的輸出目錄
+func (s *DeployService) directory(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("directory 不可為空")
+	}
+	dir, ok := s.Directories[name]
+	if !ok {
+		return "", fmt.Errorf("directory %q 未在 config.yaml 的 deploy.directories 設定", name)
+	}
+	return dir, nil
+}
+
+func checkDNS1123Label(field, value string) error {
+	if len(value) > 63 || !dns1123Label.MatchString(value) {
+		return fmt.Errorf("%s 必須是 DNS-1123 label (小寫英數字與 -，最長 63 字元): %q", field, value)
+	}
+	return nil
+}
+
+func writeBundleFiles(dir string, bundle deployBundle, certMode os.FileMode) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{
+		{deployCertFile, bundle.Cert, certMode},
+		{deployChainFile, bundle.Chain, certMode},
+		{deployFullChainFile, bundle.FullChain, certMode},
+		{deployKeyFile, bundle.Key, 0o600},
+	}
+	for _, f := range files {
+		if err := writeFileAtomic(filepath.Join(dir, f.name), f.data, f.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic 先寫到同目錄的暫存檔再 rename，避免讀取端看到寫到一半的檔案
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // rename 成功後就不存在了
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("無效的檔案權限: %s", s)
+	}
+	return os.FileMode(mode), nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateTargetOnlyAcceptsConfiguredNames(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDeployService(nil, nil, nil, nil, nil, conf.DeployConfig{
+		Hooks:       []conf.DeployHookConfig{{Name: "reload-nginx", Command: "nginx -s reload"}},
+		Directories: []conf.DeployDirectoryConfig{{Name: "nginx-ssl", Path: dir}, {Name: "relative", Path: "certs"}},
+	})
+
+	tests := []struct {
+		name   string
+		target domain.DeployTarget
+		ok     bool
+	}{
+		{"file", domain.DeployTarget{Type: domain.DeployTypeFile, Directory: "nginx-ssl"}, true},
+		{"file raw path", domain.DeployTarget{Type: domain.DeployTypeFile, Directory: dir}, false},
+		{"file relative config", domain.DeployTarget{Type: domain.DeployTypeFile, Directory: "relative"}, false},
+		{"hook", domain.DeployTarget{Type: domain.DeployTypeHook, Hook: "reload-nginx"}, true},
+		{"hook unknown", domain.DeployTarget{Type: domain.DeployTypeHook, Hook: "rm -rf /"}, false},
+		{"hook raw directory", domain.DeployTarget{Type: domain.DeployTypeHook, Hook: "reload-nginx", Directory: "/etc"}, false},
+		{"k8s", domain.DeployTarget{Type: domain.DeployTypeK8sSecret, Directory: "nginx-ssl", SecretName: "example-com-tls"}, true},
+		{"k8s namespace injection", domain.DeployTarget{Type: domain.DeployTypeK8sSecret, Directory: "nginx-ssl", Namespace: "default\n  labels: {}"}, false},
+		{"k8s secret name path", domain.DeployTarget{Type: domain.DeployTypeK8sSecret, Directory: "nginx-ssl", SecretName: "../../etc/passwd"}, false},
+		{"k8s secret name uppercase", domain.DeployTarget{Type: domain.DeployTypeK8sSecret, Directory: "nginx-ssl", SecretName: "Example"}, false},
+	}
+	for _, tt := range tests {
+		err := s.ValidateTarget(&tt.target)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestDeployK8sSecretWritesToConfiguredDirectory(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDeployService(nil, nil, nil, nil, nil, conf.DeployConfig{
+		Directories: []conf.DeployDirectoryConfig{{Name: "manifests", Path: dir}},
+	})
+	bundle := deployBundle{FullChain: []byte("cert"), Key: []byte("key")}
+
+	target := &domain.DeployTarget{Type: domain.DeployTypeK8sSecret, Directory: "manifests", Namespace: "web"}
+	if err := s.deployK8sSecret(target, &domain.ManagedCertificate{DomainName: "*.Example.com"}, bundle); err != nil {
+		t.Fatalf("deployK8sSecret: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "web-wildcard-example-com-tls.yaml"))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	if !strings.Contains(string(data), "  name: wildcard-example-com-tls\n  namespace: web\n") {
+		t.Errorf("unexpected metadata:\n%s", data)
+	}
+
+	// 資料庫中的舊設定在部署時同樣會被擋下
+	target.Namespace = "web\n  labels: {}"
+	if err := s.deployK8sSecret(target, &domain.ManagedCertificate{DomainName: "example.com"}, bundle); err == nil {
+		t.Error("invalid namespace was written into the manifest")
+	}
+	target.Namespace = "web"
+	target.Directory = "/tmp"
+	if err := s.deployK8sSecret(target, &domain.ManagedCertificate{DomainName: "example.com"}, bundle); err == nil {
+		t.Error("unconfigured directory was accepted")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiscoveryService 從 CT Log 與已掃描憑證的 SAN 中找出屬於我們 Zone、但尚未監控的主機名稱
+// 結果只是建議 (pending)，需經 Accept 才會建立監控域名
+type DiscoveryService struct {
+	Repo    repository.DiscoveryRepository
+	Domains repository.DomainRepository
+	CT      repository.CTRepository
+}
+
+func NewDiscoveryService(repo repository.DiscoveryRepository, domains repository.DomainRepository, ct repository.CTRepository) *DiscoveryService {
+	return &DiscoveryService{Repo: repo, Domains: domains, CT: ct}
+}
+
+// discoveryCandidate 一次 Run 中收集到的主機名稱
+type discoveryCandidate struct {
+	Zone   string
+	Source string
+	SeenIn string
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Run 收集候選主機名稱並寫入待確認清單，回傳新發現的數量
+func (s *DiscoveryService) Run(ctx context.Context) (int, error) {
+	zones, err := s.Domains.GetUniqueZones(ctx)
+	if err != nil {
+		return 0, err
+	}
+	zoneSet := make(map[string]bool, len(zones))
+	for _, z := range zones {
+		if z = strings.ToLower(strings.TrimSuffix(z, ".")); z != "" {
+			zoneSet[z] = true
+		}
+	}
+	if len(zoneSet) == 0 {
+		return 0, nil
+	}
+
+	dbDomains, _, err := s.Domains.List(ctx, 1, 100000, "", "", "", "", "all", "")
+	if err != nil {
+		return 0, fmt.Errorf("讀取現有域名失敗: %w", err)
+	}
+	monitored := make(map[string]bool, len(dbDomains))
+	for _, d := range dbDomains {
+		monitored[strings.ToLower(d.DomainName)] = true
+	}
+
+	candidates := make(map[string]discoveryCandidate)
+	add := func(name, source, seenIn string) {
+		host, zone, ok := discoveryHostname(name, zoneSet)
+		if !ok || monitored[host] {
+			return
+		}
+		if _, exists := candidates[host]; !exists {
+			candidates[host] = discoveryCandidate{Zone: zone, Source: source, SeenIn: seenIn}
+		}
+	}
+
+	// 1. 已掃描憑證的 SAN (同一張憑證涵蓋、但沒有獨立 DNS 紀錄的主機)
+	for _, d := range dbDomains {
+		for _, san := range d.SANs {
+			add(san, domain.DiscoverySourceSAN, "SAN: "+d.DomainName)
+		}
+	}
+
+	// 2. CT Log 中涵蓋我們 Zone 的憑證 (需啟用 CT 監控)
+	issuances, err := s.CT.ListIssuances(ctx, 0)
+	if err != nil {
+		return 0, fmt.Errorf("讀取 CT 紀錄失敗: %w", err)
+	}
+	for _, iss := range issuances {
+		for _, name := range iss.DNSNames {
+			add(name, domain.DiscoverySourceCT, fmt.Sprintf("CT: %s #%s", iss.IssuerName, iss.SerialNumber))
+		}
+	}
+
+	created := 0
+	for host, c := range candidates {
+		isNew, err := s.Repo.Record(ctx, host, c.Zone, c.Source, c.SeenIn)
+		if err != nil {
+			logrus.Errorf("❌ [Discovery] 寫入 %s 失敗: %v", host, err)
+			continue
+		}
+		if isNew {
+			created++
+			logrus.Infof("🔭 [Discovery] 發現未監控的主機: %s (%s)", host, c.SeenIn)
+		}
+	}
+
+	// 已經由同步或手動新增納入監控的待確認項目直接移除
+	pending, err := s.Repo.List(ctx, domain.DiscoveryStatusPending)
+	if err != nil {
+		return created, err
+	}
+	var covered []string
+	for _, p := range pending {
+		if monitored[p.Hostname] {
+			covered = append(covered, p.Hostname)
+		}
+	}
+	if err := s.Repo.DeletePending(ctx, covered); err != nil {
+		return created, err
+	}
+
+	logrus.Infof("🏁 [Discovery] 完成，候選 %d 筆 (新發現 %d / 已納入監控 %d)", len(candidates), created, len(covered))
+	return created, nil
+}
+
+// Accept 將探索結果建立為監控域名 (Provider = discovery，同步時不會被刪除)
+func (s *DiscoveryService) Accept(ctx context.Context, id primitive.ObjectID, port int) (*domain.SSLCertificate, error) {
+	host, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if host.Status == domain.DiscoveryStatusAccepted {
+		return nil, errors.New("此主機已加入監控")
+	}
+
+	dbDomains, _, err := s.Domains.List(ctx, 1, 100000, "", "", "", "", "all", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dbDomains {
+		if strings.EqualFold(d.DomainName, host.Hostname) {
+			_ = s.Repo.UpdateStatus(ctx, id, domain.DiscoveryStatusAccepted, d.ID)
+			return nil, errors.New("此主機已在監控清單中")
+		}
+	}
+
+	cert := domain.SSLCertificate{
+		ID:         primitive.NewObjectID(),
+		DomainName: host.Hostname,
+		Provider:   domain.ProviderDiscovery,
+		ZoneName:   host.ZoneName,
+		Port:       port,
+		CFComment:  host.FirstSeenIn,
+		Status:     domain.StatusPending,
+	}
+	if err := s.Domains.Create(ctx, cert); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.UpdateStatus(ctx, id, domain.DiscoveryStatusAccepted, cert.ID); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// Ignore 忽略探索結果，之後再被看到也維持忽略
+func (s *DiscoveryService) Ignore(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := s.Repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.Repo.UpdateStatus(ctx, id, domain.DiscoveryStatusIgnored, primitive.NilObjectID)
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// discoveryHostname 正規化名稱並找出所屬 Zone；Wildcard、IP 與不屬於我們 Zone 的名稱略過
+func discoveryHostname(name string, zones map[string]bool) (string, string, bool) {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if name == "" || strings.Contains(name, "*") || net.ParseIP(name) != nil || shouldSkipDomain(name) {
+		return "", "", false
+	}
+	matched := matchZones([]string{name}, zones)
+	if len(matched) == 0 {
+		return "", "", false
+	}
+	return name, matched[0], true
+}
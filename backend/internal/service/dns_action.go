@@ -0,0 +1,202 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 寫回 DNS 時記錄在 DNSChangeActor.Type 的操作來源
+const dnsActionActorType = "cert-manager"
+
+// DNSActionService 從 API 直接寫回 Cloudflare 紀錄 (切換 Proxy、修改 Content / Comment、新增、刪除)
+// 每個動作完成後都會重新讀取紀錄更新資料庫，並發送操作通知；重新掃描由呼叫端在背景執行
+type DNSActionService struct {
+	CF       *CloudflareService
+	Repo     repository.DomainRepository
+	Notifier *NotifierService
+}
+
+func NewDNSActionService(cf *CloudflareService, repo repository.DomainRepository, notifier *NotifierService) *DNSActionService {
+	return &DNSActionService{CF: cf, Repo: repo, Notifier: notifier}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// UpdateRecord 修改域名對應的紀錄，回傳更新後的域名
+func (s *DNSActionService) UpdateRecord(ctx context.Context, id primitive.ObjectID, change DNSRecordChange, clientIP string) (*domain.SSLCertificate, error) {
+	if change.Content == nil && change.Comment == nil && change.Proxied == nil {
+		return nil, errors.New("沒有要修改的欄位")
+	}
+	if change.Content != nil && strings.TrimSpace(*change.Content) == "" {
+		return nil, errors.New("Content 不可為空")
+	}
+
+	cert, p, writer, err := s.resolveRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.UpdateRecord(ctx, cert.ZoneID, cert.RecordID, change); err != nil {
+		return nil, fmt.Errorf("Cloudflare 更新紀錄失敗: %w", err)
+	}
+
+	// 重新讀取，以 Cloudflare 實際的結果為準
+	record, err := p.GetRecord(ctx, cert.ZoneID, cert.RecordID)
+	if err != nil {
+		return nil, fmt.Errorf("已更新，但重新讀取紀錄失敗: %w", err)
+	}
+	updated := *cert
+	applyRecord(&updated, record)
+	if err := s.Repo.Upsert(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	changes := checkCFDiff(*cert, updated)
+	if change.Comment != nil && cert.CFComment != updated.CFComment {
+		changes = append(changes, fmt.Sprintf("💬 <b>備註</b>: %s ➔ %s", cert.CFComment, updated.CFComment))
+	}
+	if len(changes) > 0 {
+		actor := &domain.DNSChangeActor{Type: dnsActionActorType, IP: clientIP, Action: "update", ChangedAt: time.Now()}
+		if err := s.Repo.AppendChangeHistory(ctx, updated.ID, newDNSChange(*cert, updated, actor)); err != nil {
+			return nil, err
+		}
+		s.Notifier.NotifyOperation(ctx, EventUpdate, updated.DomainName,
+			fmt.Sprintf("%s\n🛠 由 API 寫回 Cloudflare (IP: %s)", strings.Join(changes, "\n"), clientIP))
+	}
+	return &updated, nil
+}
+
+// CreateRecord 在監控中的 Cloudflare Zone 新增紀錄，並建立對應的監控域名
+func (s *DNSActionService) CreateRecord(ctx context.Context, zoneName string, record DNSRecord, clientIP string) (*domain.SSLCertificate, error) {
+	zoneName = strings.ToLower(strings.TrimSuffix(zoneName, "."))
+	record.Name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(record.Name), "."))
+	record.Type = strings.ToUpper(record.Type)
+	switch record.Type {
+	case "A", "AAAA", "CNAME":
+	default:
+		return nil, fmt.Errorf("不支援的紀錄類型: %s (僅支援 A / AAAA / CNAME)", record.Type)
+	}
+	if record.Content == "" {
+		return nil, errors.New("Content 不可為空")
+	}
+	if record.Name != zoneName && !strings.HasSuffix(record.Name, "."+zoneName) {
+		return nil, fmt.Errorf("%s 不屬於 Zone %s", record.Name, zoneName)
+	}
+
+	dbDomains, _, err := s.Repo.List(ctx, 1, 100000, "", "", "", "", "all", "")
+	if err != nil {
+		return nil, err
+	}
+	var ref *domain.SSLCertificate
+	for i, d := range dbDomains {
+		if strings.EqualFold(d.DomainName, record.Name) {
+			return nil, errors.New("此主機已在監控清單中")
+		}
+		if ref == nil && d.Provider == domain.ProviderCloudflare && d.ZoneID != "" && strings.EqualFold(d.ZoneName, zoneName) {
+			ref = &dbDomains[i]
+		}
+	}
+	if ref == nil {
+		return nil, fmt.Errorf("找不到已同步的 Cloudflare Zone: %s", zoneName)
+	}
+
+	p, err := s.CF.ProviderFor(ctx, ref.CFAccountID)
+	if err != nil {
+		return nil, err
+	}
+	writer, ok := p.(DNSRecordWriter)
+	if !ok {
+		return nil, errors.New("此 Provider 不支援寫回紀錄")
+	}
+	zone := DNSZone{ID: ref.ZoneID, Name: ref.ZoneName}
+	created, err := writer.CreateRecord(ctx, zone, record)
+	if err != nil {
+		return nil, fmt.Errorf("Cloudflare 新增紀錄失敗: %w", err)
+	}
+	fetched, err := p.GetRecord(ctx, zone.ID, created.ID)
+	if err != nil {
+		return nil, fmt.Errorf("已新增，但重新讀取紀錄失敗: %w", err)
+	}
+
+	cert := mapRecordToDomain(p, zone, fetched, ref.DomainExpiryDate, ref.DomainDaysLeft)
+	cert.ID = primitive.NewObjectID()
+	if err := s.Repo.Create(ctx, cert); err != nil {
+		return nil, err
+	}
+
+	s.Notifier.NotifyOperation(ctx, EventAdd, cert.DomainName,
+		fmt.Sprintf("由 API 新增 Cloudflare 紀錄 [%s] %s (Proxy: %v, IP: %s)", fetched.Type, fetched.Content, fetched.Proxied, clientIP))
+	return &cert, nil
+}
+
+// DeleteRecord 刪除域名對應的紀錄，確認 Cloudflare 上已不存在後移除監控域名
+func (s *DNSActionService) DeleteRecord(ctx context.Context, id primitive.ObjectID, clientIP string) (*domain.SSLCertificate, error) {
+	cert, p, writer, err := s.resolveRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.DeleteRecord(ctx, cert.ZoneID, cert.RecordID); err != nil {
+		return nil, fmt.Errorf("Cloudflare 刪除紀錄失敗: %w", err)
+	}
+	// 只有 Provider 明確回報不存在才算刪除成功，其他讀取錯誤無法確認結果，保留監控域名
+	_, err = p.GetRecord(ctx, cert.ZoneID, cert.RecordID)
+	if err == nil {
+		return nil, errors.New("已送出刪除，但 Cloudflare 上仍讀得到此紀錄")
+	}
+	if !errors.Is(err, ErrRecordNotFound) {
+		return nil, fmt.Errorf("已送出刪除，但無法確認紀錄是否已移除: %w", err)
+	}
+
+	if err := s.Repo.Delete(ctx, cert.ID); err != nil {
+		return nil, err
+	}
+	s.Notifier.NotifyOperation(ctx, EventDelete, cert.DomainName,
+		fmt.Sprintf("由 API 刪除 Cloudflare 紀錄 [%s] %s (IP: %s)", cert.CFRecordType, cert.CFOriginValue, clientIP))
+	return cert, nil
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// resolveRecord 取得域名與其所屬帳號的 Provider，只有同步自 Cloudflare 的域名可以寫回
+func (s *DNSActionService) resolveRecord(ctx context.Context, id primitive.ObjectID) (*domain.SSLCertificate, DNSProvider, DNSRecordWriter, error) {
+	cert, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cert.Provider != domain.ProviderCloudflare || cert.ZoneID == "" || cert.RecordID == "" {
+		return nil, nil, nil, errors.New("此域名不是同步自 Cloudflare 的紀錄")
+	}
+
+	p, err := s.CF.ProviderFor(ctx, cert.CFAccountID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	writer, ok := p.(DNSRecordWriter)
+	if !ok {
+		return nil, nil, nil, errors.New("此 Provider 不支援寫回紀錄")
+	}
+	return cert, p, writer, nil
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// applyRecord 以 Provider 上的紀錄覆蓋域名的 DNS 欄位
+func applyRecord(cert *domain.SSLCertificate, record DNSRecord) {
+	cert.CFRecordType = record.Type
+	cert.CFOriginValue = record.Content
+	cert.IsProxied = record.Proxied
+	cert.CFComment = record.Comment
+	cert.RecordModifiedOn = record.ModifiedOn
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestApplyRecordCopiesModifiedOn(t *testing.T) {
+	modified := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	cert := domain.SSLCertificate{DomainName: "www.example.com", CFRecordType: "A", CFOriginValue: "192.0.2.1"}
+
+	applyRecord(&cert, DNSRecord{Type: "CNAME", Content: "lb.example.net", Comment: "edge", Proxied: true, ModifiedOn: modified})
+	if cert.CFRecordType != "CNAME" || cert.CFOriginValue != "lb.example.net" || cert.CFComment != "edge" || !cert.IsProxied {
+		t.Errorf("record fields not applied: %+v", cert)
+	}
+	// 寫回後若沒有更新修改時間，下次同步會把自己的修改當成外部變更
+	if !cert.RecordModifiedOn.Equal(modified) {
+		t.Errorf("RecordModifiedOn = %v, want %v", cert.RecordModifiedOn, modified)
+	}
+}
+
+func TestCloudflareGetRecordDistinguishesNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/zones/z1/dns_records/live":
+			fmt.Fprint(w, `{"success":true,"errors":[],"messages":[],"result":
+				{"id":"live","type":"A","name":"www.example.com","content":"192.0.2.1","proxied":true,"modified_on":"2026-10-01T08:30:00.123456Z"}}`)
+		case "/zones/z1/dns_records/gone":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success":false,"errors":[{"code":81044,"message":"Record does not exist."}],"messages":[],"result":null}`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"messages":[],"result":null}`)
+		}
+	}))
+	defer srv.Close()
+
+	api, err := cloudflare.NewWithAPIToken("test-token", cloudflare.BaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &cloudflareDNSProvider{api: api}
+	ctx := context.Background()
+
+	record, err := p.GetRecord(ctx, "z1", "live")
+	if err != nil {
+		t.Fatalf("live record: %v", err)
+	}
+	if want := time.Date(2026, 10, 1, 8, 30, 0, 123000000, time.UTC); !record.ModifiedOn.Equal(want) || !record.Proxied {
+		t.Errorf("live record = %+v", record)
+	}
+
+	if _, err := p.GetRecord(ctx, "z1", "gone"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("deleted record: err = %v, want ErrRecordNotFound", err)
+	}
+
+	// 權限不足不能被當成紀錄已刪除
+	_, err = p.GetRecord(ctx, "z1", "forbidden")
+	if err == nil || errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("forbidden record: err = %v, want a non-not-found error", err)
+	}
+}
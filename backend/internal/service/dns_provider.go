@@ -0,0 +1,103 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrRecordNotFound GetRecord 在 Provider 明確回報紀錄不存在時回傳 (以 errors.Is 判斷)
+// 其他錯誤 (權限、限流、網路) 都不代表紀錄已被刪除
+var ErrRecordNotFound = errors.New("DNS 紀錄不存在")
+
+// DNSZone Provider 上的一個 Zone (根域名)
+type DNSZone struct {
+	ID     string
+	Name   string
+	Status string // e.g. "active"，僅供日誌參考
+}
+
+// DNSRecord Provider 上的一筆 DNS 紀錄
+type DNSRecord struct {
+	ID      string
+	Name    string
+	Type    string // A / CNAME ...
+	Content string
+	Comment string
+	Proxied bool // 僅 Cloudflare 有意義
+
+	// 最後修改時間 (僅 Cloudflare 提供，其他 Provider 為零值)，增量同步用來判斷紀錄是否變動
+	ModifiedOn time.Time
+}
+
+// DNSProvider 同步來源 (一個 Provider 帳號)
+// 同步流程只透過此介面讀取 Zone 與紀錄，再串流進 CronService 的 Pipeline
+type DNSProvider interface {
+	// Name 寫入 SSLCertificate.Provider 的名稱 (e.g. "cloudflare")
+	Name() string
+	// Label 日誌與同步結果用的顯示名稱 (e.g. "cloudflare/Production")
+	Label() string
+
+	ListZones(ctx context.Context) ([]DNSZone, error)
+	ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error)
+	// GetRecord 紀錄不存在時回傳包裝 ErrRecordNotFound 的錯誤
+	GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error)
+
+	// Owns 判斷資料庫中的域名是否可能由此 Provider 管理 (同一種 Provider 可能有多個帳號)
+	// 刪除與 Zone 變更偵測只在擁有者都成功同步時才會進行
+	Owns(d domain.SSLCertificate) bool
+	// Annotate 寫入前補上 Provider 專屬的欄位 (e.g. Cloudflare 帳號)
+	Annotate(d *domain.SSLCertificate)
+}
+
+// DNSProviderSource 提供一組 DNSProvider (e.g. 每個 Cloudflare 帳號一個)
+type DNSProviderSource interface {
+	Providers(ctx context.Context) ([]DNSProvider, error)
+	// RecordSyncResult 保存同步結果 (count 為抓到的域名數量，err 為 nil 代表成功)
+	RecordSyncResult(ctx context.Context, p DNSProvider, count int, err error)
+}
+
+// ZoneSecurityReader 可讀取 Zone 層級 SSL/TLS 設定與 Edge 憑證包的 Provider (目前只有 Cloudflare)
+// 部分項目讀取失敗時仍回傳已取得的資料 (未取得的欄位為零值，憑證包為 nil) 與錯誤
+type ZoneSecurityReader interface {
+	ZoneSecurity(ctx context.Context, zone DNSZone) (*domain.ZoneSecurity, error)
+}
+
+// RecordAuditReader 可從稽核紀錄查出紀錄變更者的 Provider (目前只有 Cloudflare)
+// 找不到對應的稽核紀錄時回傳 nil, nil
+type RecordAuditReader interface {
+	RecordChangeActor(ctx context.Context, zone DNSZone, recordID string) (*domain.DNSChangeActor, error)
+}
+
+// AccountKeyer 顯示名稱可改名的 Provider 提供不變的帳號識別 (目前只有 Cloudflare 的帳號 ID)
+type AccountKeyer interface {
+	AccountKey() string
+}
+
+// providerAccount 回傳用來區分同類型 Provider 的帳號識別，沒有帳號 ID 時用顯示名稱 ("Name/xxx" 的 xxx)
+// 只有單一帳號的 Provider (Label 與 Name 相同) 回傳空字串
+func providerAccount(p DNSProvider) string {
+	if k, ok := p.(AccountKeyer); ok {
+		return k.AccountKey()
+	}
+	if p.Label() == p.Name() {
+		return ""
+	}
+	return strings.TrimPrefix(p.Label(), p.Name()+"/")
+}
+
+// DNSRecordChange 要寫回的欄位 (nil 代表維持原值)
+type DNSRecordChange struct {
+	Content *string
+	Comment *string
+	Proxied *bool
+}
+
+// DNSRecordWriter 可直接寫回紀錄的 Provider (目前只有 Cloudflare)，供 API 操作 DNS
+type DNSRecordWriter interface {
+	CreateRecord(ctx context.Context, zone DNSZone, record DNSRecord) (DNSRecord, error)
+	UpdateRecord(ctx context.Context, zoneID, recordID string, change DNSRecordChange) error
+	DeleteRecord(ctx context.Context, zoneID, recordID string) error
+}
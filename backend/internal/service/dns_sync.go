@@ -0,0 +1,425 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/likexian/whois"
+	whoisparser "github.com/likexian/whois-parser"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	zoneSyncPause          = 1000 * time.Millisecond // 每個 Zone 之間休息一下，避免觸發 Provider API 限制
+	defaultZoneConcurrency = 3                       // 同時處理的 Zone 數量
+)
+
+// ZoneSyncReport 記錄一次同步中各 Zone 的處理結果
+// Excluded / Failed 的 Zone 沒有完整資料，同步時不可據此刪除其下的域名
+type ZoneSyncReport struct {
+	Synced   []string
+	Excluded []string
+	Failed   []string
+}
+
+// Untouched 回傳本次沒有完整同步的 Zone (排除或失敗)
+func (r *ZoneSyncReport) Untouched() map[string]bool {
+	zones := make(map[string]bool)
+	if r == nil {
+		return zones
+	}
+	for _, z := range r.Excluded {
+		zones[z] = true
+	}
+	for _, z := range r.Failed {
+		zones[z] = true
+	}
+	return zones
+}
+
+// DNSSyncService 從所有 DNS Provider 抓取域名 (不綁定特定 Provider)
+type DNSSyncService struct {
+	Repo         repository.DomainRepository
+	States       repository.ZoneSyncRepository // 每個 Provider 帳號下 Zone 的增量同步進度 (nil 時不記錄)
+	ZoneSecurity *ZoneSecurityService          // Zone 的 SSL/TLS 模式與 Edge 憑證包 (Provider 支援時才會讀取)
+	Sources      []DNSProviderSource
+}
+
+func NewDNSSyncService(repo repository.DomainRepository, states repository.ZoneSyncRepository, zoneSecurity *ZoneSecurityService, sources ...DNSProviderSource) *DNSSyncService {
+	return &DNSSyncService{Repo: repo, States: states, ZoneSecurity: zoneSecurity, Sources: sources}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Providers 列出所有來源的 Provider
+func (s *DNSSyncService) Providers(ctx context.Context) ([]DNSProvider, []DNSProviderSource, error) {
+	var providers []DNSProvider
+	var owners []DNSProviderSource
+	for _, source := range s.Sources {
+		list, err := source.Providers(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range list {
+			providers = append(providers, p)
+			owners = append(owners, source)
+		}
+	}
+	return providers, owners, nil
+}
+
+// ListSyncStates 各 Provider 帳號下 Zone 的同步進度 (未啟用記錄時回傳空清單)
+func (s *DNSSyncService) ListSyncStates(ctx context.Context) ([]domain.ZoneSyncState, error) {
+	if s.States == nil {
+		return nil, nil
+	}
+	return s.States.List(ctx)
+}
+
+// FetchDomains 列出 Provider 可見的所有 Zone (套用 Include/Exclude 設定)，
+// 以有限併發逐一處理並將域名串流到 outputChan
+func (s *DNSSyncService) FetchDomains(ctx context.Context, p DNSProvider, outputChan chan<- domain.SSLCertificate) (*ZoneSyncReport, error) {
+	logrus.Infof("🚀 [DNS] 開始執行 FetchDomains (串流模式，來源: %s)...", p.Label())
+
+	settings, err := s.Repo.GetSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("讀取同步設定失敗: %w", err)
+	}
+
+	// 1. 獲取所有 Zones 並過濾
+	zones, err := p.ListZones(ctx)
+	if err != nil {
+		logrus.Errorf("❌ [DNS] %s 列出 Zone 失敗: %v", p.Label(), err)
+		return nil, err
+	}
+	report := &ZoneSyncReport{}
+	var targets []DNSZone
+	for _, zone := range zones {
+		if zoneSelected(zone, settings.SyncIncludeZones, settings.SyncExcludeZones) {
+			targets = append(targets, zone)
+		} else {
+			report.Excluded = append(report.Excluded, zone.Name)
+		}
+	}
+	logrus.Infof("✅ [DNS] %s 取得 Zone 列表成功，共 %d 個 Zone (同步 %d / 排除 %d)", p.Label(), len(zones), len(targets), len(report.Excluded))
+
+	concurrency := settings.SyncZoneConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultZoneConcurrency
+	}
+
+	// 2. 以有限併發處理每個 Zone
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, zone := range targets {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return report, ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, zone DNSZone) {
+			defer func() {
+				time.Sleep(zoneSyncPause)
+				<-sem
+				wg.Done()
+			}()
+
+			logrus.Infof("🔍 [%d/%d] 正在掃描 Zone: %s (ID: %s)", i+1, len(targets), zone.Name, zone.ID)
+
+			// 處理單一 Zone 的所有邏輯 (Whois + DNS Records)
+			zoneDomains, err := s.processZone(ctx, p, zone)
+			mu.Lock()
+			if err != nil {
+				report.Failed = append(report.Failed, zone.Name)
+			} else {
+				report.Synced = append(report.Synced, zone.Name)
+			}
+			mu.Unlock()
+
+			// [關鍵] 將抓到的域名立即推送到通道
+			for _, d := range zoneDomains {
+				select {
+				case <-ctx.Done():
+					return
+				case outputChan <- d: // <--- 一抓到就丟給 CronService 去掃描
+				}
+			}
+		}(i, zone)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	logrus.Infof("🏁 [DNS] %s 的 Zone 抓取完畢 (成功 %d / 失敗 %d)", p.Label(), len(report.Synced), len(report.Failed))
+	return report, nil
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// processZone 處理單一 Zone 的完整流程：查詢 WHOIS -> 抓取 Records -> 轉換資料 -> 更新同步進度 -> SSL/TLS 設定
+// 仍會回傳 Zone 下所有紀錄 (刪除比對需要完整清單)，是否重新掃描由 CronService 依每筆紀錄的修改時間判斷
+func (s *DNSSyncService) processZone(ctx context.Context, p DNSProvider, zone DNSZone) ([]domain.SSLCertificate, error) {
+	var results []domain.SSLCertificate
+
+	// A. 查詢 Zone (根域名) 的 WHOIS
+	expiryDate, daysLeft, err := fetchZoneWhois(zone.Name)
+	if err != nil {
+		logrus.Warnf("   ⚠️ Zone WHOIS 查詢失敗 %s: %v (子域名將無到期日資料)", zone.Name, err)
+	} else {
+		logrus.Infof("   📅 Zone 到期日: %s (剩餘 %d 天)", expiryDate.Format("2006-01-02"), daysLeft)
+	}
+
+	// B. 獲取所有 DNS 紀錄 (分頁由 Provider 處理)
+	records, err := p.ListRecords(ctx, zone)
+	if err != nil {
+		logrus.Errorf("❌ 無法獲取 Zone %s 的紀錄: %v", zone.Name, err)
+		return nil, err
+	}
+	logrus.Debugf("   -> Zone %s 找到 %d 筆紀錄", zone.Name, len(records))
+
+	// 已監控的域名不再寫入 Pending，以免未變更的紀錄被清掉掃描結果
+	known, err := s.knownDomains(ctx, zone)
+	if err != nil {
+		logrus.Errorf("❌ 無法讀取 Zone %s 的既有域名: %v", zone.Name, err)
+		return nil, err
+	}
+	state, prevCursor := s.loadSyncState(ctx, p, zone)
+
+	// AAAA 只在同名主機沒有 A / CNAME / ALIAS 時才納入，避免同一主機重複建立
+	hasPrimary := primaryHosts(records)
+
+	// C. 過濾並轉換為 Domain Model
+	for _, record := range records {
+		if !isValidRecordType(record.Type) {
+			continue
+		}
+		if record.Type == "AAAA" && hasPrimary[strings.ToLower(record.Name)] {
+			continue
+		}
+		if shouldSkipDomain(record.Name) {
+			logrus.Debugf("      🚫 [Skip] 略過不需要的域名: %s", record.Name)
+			continue
+		}
+
+		logrus.Infof("      -> 發現子域名: [%s] %s (Target: %s)", record.Type, record.Name, record.Content)
+
+		cert := mapRecordToDomain(p, zone, record, expiryDate, daysLeft)
+		if record.ModifiedOn.After(prevCursor) {
+			state.ModifiedCount++
+		}
+		if record.ModifiedOn.After(state.Cursor) {
+			state.Cursor = record.ModifiedOn
+		}
+
+		// 新域名一發現就以 Pending 狀態寫入，讓前端馬上看得到 (Upsert 不會覆蓋使用者設定)
+		if !known[cert.DomainName] {
+			if err := s.Repo.Upsert(ctx, cert); err != nil {
+				logrus.Errorf("      ❌ 寫入 Pending 失敗: %v", err)
+			} else {
+				logrus.Debugf("      ✅ 已寫入 Pending: %s", cert.DomainName)
+			}
+		}
+		results = append(results, cert)
+	}
+
+	// 記錄同步進度 (Provider 不提供修改時間時 Cursor 維持零值)
+	if !state.Cursor.IsZero() {
+		if prevCursor.IsZero() {
+			logrus.Infof("   🔁 Zone %s 首次記錄同步進度 (%d 筆紀錄)", zone.Name, len(results))
+		} else {
+			logrus.Infof("   🔁 Zone %s 自 %s 後有 %d 筆紀錄修改 (共 %d 筆)", zone.Name, prevCursor.Format(time.RFC3339), state.ModifiedCount, len(results))
+		}
+	}
+	state.LastSyncAt = time.Now()
+	state.RecordCount = len(results)
+	s.saveSyncState(ctx, state)
+
+	if zone.Status != "" && zone.Status != "active" {
+		logrus.Warnf("發現非 Active 域名: %s (Status: %s)", zone.Name, zone.Status)
+	}
+
+	// D. Zone 層級的 SSL/TLS 設定與 Edge 憑證包 (失敗不影響 DNS 同步結果)
+	if s.ZoneSecurity != nil {
+		s.ZoneSecurity.Refresh(ctx, p, zone)
+	}
+
+	return results, nil
+}
+
+// knownDomains 資料庫中已存在的 Zone 域名
+func (s *DNSSyncService) knownDomains(ctx context.Context, zone DNSZone) (map[string]bool, error) {
+	domains, _, err := s.Repo.List(ctx, 1, 100000, "", "", "", "", "all", zone.Name)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		known[d.DomainName] = true
+	}
+	return known, nil
+}
+
+// loadSyncState 讀取 Zone 上次的同步進度，回傳本次要更新的狀態與上次的 Cursor (沒有紀錄或讀取失敗時為零值)
+func (s *DNSSyncService) loadSyncState(ctx context.Context, p DNSProvider, zone DNSZone) (domain.ZoneSyncState, time.Time) {
+	state := domain.ZoneSyncState{
+		ID:       zoneSyncKey(p, zone),
+		Provider: p.Name(),
+		Account:  providerAccount(p),
+		ZoneID:   zone.ID,
+		ZoneName: zone.Name,
+	}
+	if s.States == nil {
+		return state, time.Time{}
+	}
+	prev, err := s.States.Get(ctx, state.ID)
+	if err != nil {
+		logrus.Warnf("   ⚠️ 讀取 Zone %s 同步進度失敗: %v", zone.Name, err)
+		return state, time.Time{}
+	}
+	if prev == nil {
+		return state, time.Time{}
+	}
+	// Cursor 只往前推進：最新的紀錄被刪除時不退回
+	state.Cursor = prev.Cursor
+	return state, prev.Cursor
+}
+
+func (s *DNSSyncService) saveSyncState(ctx context.Context, state domain.ZoneSyncState) {
+	if s.States == nil {
+		return
+	}
+	if err := s.States.Save(ctx, state); err != nil {
+		logrus.Errorf("   ❌ 保存 Zone %s 同步進度失敗: %v", state.ZoneName, err)
+	}
+}
+
+// zoneSyncKey zone_sync_states 的 _id，同一個 Zone ID 在不同帳號下各自記錄
+func zoneSyncKey(p DNSProvider, zone DNSZone) string {
+	if account := providerAccount(p); account != "" {
+		return p.Name() + "/" + account + "/" + zone.ID
+	}
+	return p.Name() + "/" + zone.ID
+}
+
+// mapRecordToDomain 將 Provider 的紀錄映射為內部資料結構
+func mapRecordToDomain(p DNSProvider, zone DNSZone, record DNSRecord, expiryDate time.Time, daysLeft int) domain.SSLCertificate {
+	cert := domain.SSLCertificate{
+		DomainName:       record.Name,
+		Provider:         p.Name(),
+		ZoneID:           zone.ID,
+		ZoneName:         zone.Name,
+		RecordID:         record.ID,
+		RecordModifiedOn: record.ModifiedOn,
+		IsProxied:        record.Proxied,
+		DomainExpiryDate: expiryDate,
+		DomainDaysLeft:   daysLeft,
+		CFOriginValue:    record.Content,
+		CFRecordType:     record.Type,
+		CFComment:        record.Comment,
+		IsIgnored:        false,
+		Status:           "pending",
+	}
+	p.Annotate(&cert)
+	return cert
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// fetchZoneWhois 查詢並解析 WHOIS 時間
+func fetchZoneWhois(domainName string) (time.Time, int, error) {
+	raw, err := whois.Whois(domainName)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	result, err := whoisparser.Parse(raw)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	if result.Domain.ExpirationDate == "" {
+		return time.Time{}, 0, fmt.Errorf("no expiration date found")
+	}
+
+	return parseZoneWhoisTime(result.Domain.ExpirationDate)
+}
+
+// parseZoneWhoisTime 嘗試多種格式解析時間
+func parseZoneWhoisTime(dateStr string) (time.Time, int, error) {
+	var expiryTime time.Time
+
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05.00Z",
+		"2006-01-02",
+	}
+
+	for _, f := range formats {
+		if t, e := time.Parse(f, dateStr); e == nil {
+			expiryTime = t
+			break
+		}
+	}
+
+	if expiryTime.IsZero() {
+		return time.Time{}, 0, fmt.Errorf("date parse fail: %s", dateStr)
+	}
+
+	daysLeft := int(time.Until(expiryTime).Hours() / 24)
+	return expiryTime, daysLeft, nil
+}
+
+// zoneSelected 判斷 Zone 是否在同步範圍內 (比對 Zone 名稱或 ID，不分大小寫)
+func zoneSelected(zone DNSZone, include, exclude []string) bool {
+	matches := func(list []string) bool {
+		for _, item := range list {
+			item = strings.TrimSpace(item)
+			if strings.EqualFold(item, zone.Name) || item == zone.ID {
+				return true
+			}
+		}
+		return false
+	}
+	if len(include) > 0 && !matches(include) {
+		return false
+	}
+	return !matches(exclude)
+}
+
+// isValidRecordType 需要監控的紀錄類型 (ALIAS 為 Route 53 指向 ELB / CloudFront 等的別名紀錄；
+// INGRESS / HTTPROUTE 為 Kubernetes 探索到的主機)
+func isValidRecordType(recordType string) bool {
+	switch recordType {
+	case "A", "AAAA", "CNAME", "ALIAS", "INGRESS", "HTTPROUTE":
+		return true
+	}
+	return false
+}
+
+// primaryHosts 有 A / CNAME / ALIAS 紀錄的主機名稱 (小寫)，同名的 AAAA 不另外建立域名
+func primaryHosts(records []DNSRecord) map[string]bool {
+	hosts := make(map[string]bool)
+	for _, record := range records {
+		if isValidRecordType(record.Type) && record.Type != "AAAA" {
+			hosts[strings.ToLower(record.Name)] = true
+		}
+	}
+	return hosts
+}
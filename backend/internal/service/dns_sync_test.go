@@ -0,0 +1,88 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestZoneSyncKeyScopesByAccount(t *testing.T) {
+	zone := DNSZone{ID: "023e105f4ecef8ad9ca31a8372d0c353", Name: "example.com"}
+
+	// 兩個 Cloudflare 帳號看到同一個 Zone ID 時不能互相覆蓋
+	prod := &cloudflareDNSProvider{account: domain.CloudflareAccount{ID: primitive.NewObjectID(), Name: "Prod"}}
+	shared := &cloudflareDNSProvider{account: domain.CloudflareAccount{ID: primitive.NewObjectID(), Name: "Prod"}}
+	if zoneSyncKey(prod, zone) == zoneSyncKey(shared, zone) {
+		t.Errorf("accounts %s and %s share key %q", prod.account.ID.Hex(), shared.account.ID.Hex(), zoneSyncKey(prod, zone))
+	}
+
+	// 帳號改名不影響 Key
+	before := zoneSyncKey(prod, zone)
+	prod.account.Name = "Production"
+	if got := zoneSyncKey(prod, zone); got != before {
+		t.Errorf("key changed after rename: %q -> %q", before, got)
+	}
+	if want := "cloudflare/" + prod.account.ID.Hex() + "/" + zone.ID; before != want {
+		t.Errorf("cloudflare key = %q, want %q", before, want)
+	}
+
+	tests := []struct {
+		p    DNSProvider
+		want string
+	}{
+		{&kubernetesDNSProvider{name: "prod-east"}, "kubernetes/prod-east/example.com"},
+		{&axfrDNSProvider{server: conf.AXFRServerConfig{Address: "ns1.example.com:53"}}, "axfr/ns1.example.com:53/example.com"},
+		{&route53DNSProvider{}, "route53/example.com"},
+	}
+	for _, tt := range tests {
+		if got := zoneSyncKey(tt.p, DNSZone{ID: "example.com", Name: "example.com"}); got != tt.want {
+			t.Errorf("%s key = %q, want %q", tt.p.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestLoadSyncStateResumesCursor(t *testing.T) {
+	p := &kubernetesDNSProvider{name: "prod-east"}
+	zone := DNSZone{ID: "example.com", Name: "example.com"}
+	cursor := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	states := &memZoneSyncStates{states: map[string]domain.ZoneSyncState{}}
+	s := &DNSSyncService{States: states}
+
+	state, prev := s.loadSyncState(context.Background(), p, zone)
+	if !prev.IsZero() || state.ID != "kubernetes/prod-east/example.com" || state.Account != "prod-east" {
+		t.Fatalf("first sync: state = %+v, prev = %v", state, prev)
+	}
+
+	state.Cursor = cursor
+	s.saveSyncState(context.Background(), state)
+	state, prev = s.loadSyncState(context.Background(), p, zone)
+	if !prev.Equal(cursor) || !state.Cursor.Equal(cursor) {
+		t.Errorf("resumed cursor = %v / %v, want %v", prev, state.Cursor, cursor)
+	}
+	if state.ModifiedCount != 0 || state.RecordCount != 0 {
+		t.Errorf("counts carried over from previous sync: %+v", state)
+	}
+}
+
+type memZoneSyncStates struct {
+	repository.ZoneSyncRepository
+	states map[string]domain.ZoneSyncState
+}
+
+func (m *memZoneSyncStates) Get(_ context.Context, id string) (*domain.ZoneSyncState, error) {
+	state, ok := m.states[id]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (m *memZoneSyncStates) Save(_ context.Context, state domain.ZoneSyncState) error {
+	m.states[state.ID] = state
+	return nil
+}
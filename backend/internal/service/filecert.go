@@ -0,0 +1,352 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fileCertDebounce = 2 * time.Second // 同一檔案的連續事件 (寫入、rename) 合併處理
+	fileCertMaxSize  = 5 << 20         // 超過 5 MB 的檔案不視為憑證
+)
+
+// fileCertExtensions 會被當成憑證處理的副檔名
+var fileCertExtensions = map[string]bool{
+	".pem": true, ".crt": true, ".cer": true, ".cert": true, ".der": true,
+	".p12": true, ".pfx": true, ".jks": true, ".keystore": true, ".truststore": true,
+}
+
+// FileCertService 監看設定的目錄，解析磁碟上的憑證 (mTLS Client、內部服務憑證等)
+// 並與網路掃描的域名共用 CheckAndNotify 到期告警
+type FileCertService struct {
+	Repo     repository.FileCertificateRepository
+	Notifier *NotifierService
+	Config   conf.FileWatchConfig
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+}
+
+func NewFileCertService(repo repository.FileCertificateRepository, notify *NotifierService, cfg conf.FileWatchConfig) *FileCertService {
+	return &FileCertService{Repo: repo, Notifier: notify, Config: cfg, pending: make(map[string]*time.Timer)}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Start 完整掃描一次後開始監看目錄變更 (沒有設定目錄時不啟動)
+func (s *FileCertService) Start(ctx context.Context) {
+	if len(s.Config.Directories) == 0 {
+		return
+	}
+	go func() {
+		if err := s.ScanAll(ctx); err != nil {
+			logrus.Errorf("❌ [FileCert] 初始掃描失敗: %v", err)
+		}
+		s.watch(ctx)
+	}()
+}
+
+// ScanAll 重新掃描所有目錄 (排程每日執行，順便更新剩餘天數)，並清除已不存在的檔案
+func (s *FileCertService) ScanAll(ctx context.Context) error {
+	seen := make(map[string]bool)
+	for _, dir := range s.Config.Directories {
+		for _, path := range s.walk(dir) {
+			seen[path] = true
+			s.scanFile(ctx, path)
+		}
+	}
+
+	existing, err := s.Repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	removed := make(map[string]bool)
+	for _, f := range existing {
+		if !seen[f.Path] && !removed[f.Path] {
+			removed[f.Path] = true
+			if err := s.Repo.DeleteByPath(ctx, f.Path); err != nil {
+				logrus.Errorf("❌ [FileCert] 移除 %s 失敗: %v", f.Path, err)
+			}
+		}
+	}
+	logrus.Infof("📁 [FileCert] 掃描完成，共 %d 個檔案 (移除 %d 個)", len(seen), len(removed))
+	return nil
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// watch fsnotify 不會遞迴監看，子目錄需逐一加入 (新建立的目錄也一樣)
+func (s *FileCertService) watch(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logrus.Errorf("❌ [FileCert] 無法建立檔案監看: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	for _, dir := range s.Config.Directories {
+		s.addWatchRecursive(watcher, dir)
+	}
+	logrus.Infof("👀 [FileCert] 開始監看 %d 個目錄", len(s.Config.Directories))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.Warnf("⚠️ [FileCert] 監看錯誤: %v", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !isAtomicWriterDir(event.Name) {
+					s.addWatchRecursive(watcher, event.Name)
+				}
+			}
+			s.schedule(ctx, event.Name)
+		}
+	}
+}
+
+// schedule 延遲處理，同一路徑在 debounce 期間內的事件只處理一次
+func (s *FileCertService) schedule(ctx context.Context, path string) {
+	// Kubernetes Secret / ConfigMap 以 ..data 符號連結原子切換，變更時重新掃描整個目錄
+	if isAtomicWriterDir(path) {
+		path = filepath.Dir(path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.pending[path]; ok {
+		t.Reset(fileCertDebounce)
+		return
+	}
+	s.pending[path] = time.AfterFunc(fileCertDebounce, func() {
+		s.mu.Lock()
+		delete(s.pending, path)
+		s.mu.Unlock()
+		s.handlePath(ctx, path)
+	})
+}
+
+// handlePath 檔案被刪除則移除紀錄；目錄則整個重新掃描
+func (s *FileCertService) handlePath(ctx context.Context, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logrus.Infof("🗑 [FileCert] 檔案已移除: %s", path)
+			if err := s.Repo.DeleteByPath(ctx, path); err != nil {
+				logrus.Errorf("❌ [FileCert] 移除 %s 失敗: %v", path, err)
+			}
+		}
+		return
+	}
+
+	if info.IsDir() {
+		for _, p := range s.walk(path) {
+			s.scanFile(ctx, p)
+		}
+		return
+	}
+	if isCertFile(path) {
+		s.scanFile(ctx, path)
+	}
+}
+
+// scanFile 解析單一檔案、更新清單並檢查到期告警
+func (s *FileCertService) scanFile(ctx context.Context, path string) {
+	entries := s.parseFile(path)
+	if err := s.Repo.ReplaceFile(ctx, path, entries); err != nil {
+		logrus.Errorf("❌ [FileCert] 寫入 %s 失敗: %v", path, err)
+		return
+	}
+
+	saved, err := s.Repo.ListByPath(ctx, path)
+	if err != nil {
+		return
+	}
+	for _, f := range saved {
+		if f.Status == domain.FileCertStatusError {
+			logrus.Warnf("⚠️ [FileCert] %s: %s", f.Path, f.ErrorMsg)
+			continue
+		}
+		if s.Notifier.CheckAndNotify(ctx, fileCertToSSL(f)) {
+			if err := s.Repo.UpdateAlertTime(ctx, f.ID); err != nil {
+				logrus.Errorf("❌ [FileCert] 更新告警時間失敗 %s: %v", f.DisplayName(), err)
+			}
+		}
+	}
+}
+
+// parseFile 無法解析的檔案也保留一筆紀錄 (Status = parse_error)，讓清單看得到問題
+func (s *FileCertService) parseFile(path string) []domain.FileCertificate {
+	fail := func(err error) []domain.FileCertificate {
+		return []domain.FileCertificate{{Path: path, Status: domain.FileCertStatusError, ErrorMsg: err.Error()}}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fail(err)
+	}
+	if info.Size() > fileCertMaxSize {
+		return fail(fmt.Errorf("檔案過大 (%d bytes)", info.Size()))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fail(err)
+	}
+	certs, err := parseCertFile(path, data, s.Config.Passwords)
+	if err != nil {
+		return fail(err)
+	}
+
+	entries := make([]domain.FileCertificate, 0, len(certs))
+	used := make(map[string]int)
+	for _, pc := range certs {
+		// Path + Entry 為唯一鍵，重複的 Alias (PKCS#12 friendlyName 可能相同) 加上序號
+		entry := pc.Entry
+		if used[entry]++; used[entry] > 1 {
+			entry = fmt.Sprintf("%s#%d", pc.Entry, used[pc.Entry])
+		}
+		entries = append(entries, newFileCertificate(path, entry, pc.Format, pc.Cert))
+	}
+	return entries
+}
+
+// walk 列出目錄下所有憑證檔案 (跟隨符號連結，略過 Kubernetes 的 ..timestamp 目錄)
+func (s *FileCertService) walk(root string) []string {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logrus.Warnf("⚠️ [FileCert] 無法讀取 %s: %v", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && isAtomicWriterDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isCertFile(path) {
+			return nil
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ [FileCert] 掃描目錄失敗 %s: %v", root, err)
+	}
+	return files
+}
+
+func (s *FileCertService) addWatchRecursive(watcher *fsnotify.Watcher, root string) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && isAtomicWriterDir(path) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			logrus.Warnf("⚠️ [FileCert] 無法監看 %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+func newFileCertificate(path, entry, format string, cert *x509.Certificate) domain.FileCertificate {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	days := int(time.Until(cert.NotAfter).Hours() / 24)
+	status := domain.StatusActive
+	if days < 0 {
+		status = domain.StatusExpired
+	} else if days < 30 {
+		status = domain.StatusWarning
+	}
+
+	return domain.FileCertificate{
+		Path:          path,
+		Entry:         entry,
+		Format:        format,
+		Subject:       cert.Subject.String(),
+		Issuer:        certIssuerName(cert),
+		SANs:          sans,
+		SerialNumber:  fmt.Sprintf("%X", cert.SerialNumber),
+		Fingerprint:   hex.EncodeToString(fingerprint[:]),
+		IsCA:          cert.IsCA,
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+		DaysRemaining: days,
+		Status:        status,
+	}
+}
+
+// fileCertToSSL 轉成 CheckAndNotify 使用的結構 (檔案憑證沒有 Hostname 比對問題)
+func fileCertToSSL(f domain.FileCertificate) domain.SSLCertificate {
+	return domain.SSLCertificate{
+		ID:             f.ID,
+		DomainName:     f.DisplayName(),
+		IsIgnored:      f.IsIgnored,
+		Issuer:         f.Issuer,
+		NotBefore:      f.NotBefore,
+		NotAfter:       f.NotAfter,
+		DaysRemaining:  f.DaysRemaining,
+		Status:         f.Status,
+		LastAlertTime:  f.LastAlertTime,
+		SANs:           f.SANs,
+		IsMatch:        true,
+		ResolvedRecord: f.Subject,
+	}
+}
+
+func isCertFile(path string) bool {
+	return fileCertExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// isAtomicWriterDir Kubernetes Volume 的內部目錄 (..data、..2024_01_01_00_00_00.123)
+func isAtomicWriterDir(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "..")
+}
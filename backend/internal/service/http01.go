@@ -0,0 +1,80 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// HTTP01Provider 實作 lego 的 challenge.Provider
+// Token 寫入 Mongo，由 Gin 路由 (或獨立 Listener) 回應 CA 的驗證請求
+type HTTP01Provider struct {
+	Repo repository.AcmeChallengeRepository
+}
+
+func NewHTTP01Provider(repo repository.AcmeChallengeRepository) *HTTP01Provider {
+	return &HTTP01Provider{Repo: repo}
+}
+
+// Present 保存 Token (lego 在通知 CA 驗證前呼叫)
+func (p *HTTP01Provider) Present(domainName, token, keyAuth string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logrus.Infof("🔑 [HTTP-01] %s 等待驗證: %s", domainName, http01.ChallengePath(token))
+	return p.Repo.Save(ctx, domain.AcmeChallenge{Token: token, DomainName: domainName, KeyAuth: keyAuth})
+}
+
+// CleanUp 驗證結束後移除 Token
+func (p *HTTP01Provider) CleanUp(domainName, token, keyAuth string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return p.Repo.Delete(ctx, token)
+}
+
+// Lookup 依 Token 與 Host 取得 Key Authorization，找不到回傳 false
+func (p *HTTP01Provider) Lookup(ctx context.Context, host, token string) (string, bool) {
+	challenge, err := p.Repo.GetByToken(ctx, token)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logrus.Errorf("❌ [HTTP-01] 讀取 Token 失敗: %v", err)
+		}
+		return "", false
+	}
+
+	// Host 可能帶 Port
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.EqualFold(host, challenge.DomainName) {
+		logrus.Warnf("⚠️ [HTTP-01] Token Host 不符: %s (預期 %s)", host, challenge.DomainName)
+		return "", false
+	}
+	return challenge.KeyAuth, true
+}
+
+// ServeHTTP 給獨立 Listener 使用 (例如 :80 只回應驗證請求)
+func (p *HTTP01Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.URL.Path, http01.ChallengePath(""))
+	if !ok || token == "" || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	keyAuth, ok := p.Lookup(r.Context(), r.Host, token)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(keyAuth))
+}
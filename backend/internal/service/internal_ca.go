@@ -0,0 +1,399 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 內建 CA 的預設效期
+const (
+	internalRootValidity         = 10 * 365 * 24 * time.Hour
+	internalIntermediateValidity = 5 * 365 * 24 * time.Hour
+	defaultInternalLeafDays      = 90
+	maxInternalLeafDays          = 398 // 與公開 CA 的上限一致，避免用戶端拒絕
+)
+
+// InternalCAService 內建私有 CA：給內部 (無法公開驗證) 的主機名稱簽發憑證
+type InternalCAService struct {
+	Repo       repository.InternalCARepository
+	DomainRepo repository.DomainRepository
+	Store      *CertStoreService
+	Deployer   *DeployService
+	Notifier   *NotifierService
+}
+
+func NewInternalCAService(repo repository.InternalCARepository, domainRepo repository.DomainRepository, store *CertStoreService, deployer *DeployService, notifier *NotifierService) *InternalCAService {
+	return &InternalCAService{
+		Repo:       repo,
+		DomainRepo: domainRepo,
+		Store:      store,
+		Deployer:   deployer,
+		Notifier:   notifier,
+	}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Generate 產生新的 Root 與 Intermediate (取代現有 CA)
+func (s *InternalCAService) Generate(ctx context.Context, name string) (*domain.InternalCA, error) {
+	if name == "" {
+		name = "Cert Manager Internal CA"
+	}
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	rootTmpl, err := caTemplate(name+" Root", internalRootValidity, 1)
+	if err != nil {
+		return nil, err
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTmpl, rootTmpl, rootKey.Public(), rootKey)
+	if err != nil {
+		return nil, err
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &domain.InternalCA{Name: name}
+	if ca.RootEncryptedKey, err = s.encryptKey(rootKey); err != nil {
+		return nil, err
+	}
+	ca.HasRootKey = true
+	return s.saveWithIntermediate(ctx, ca, root, rootKey)
+}
+
+// Import 匯入既有的 CA
+// 提供 Intermediate 憑證與私鑰時直接使用；否則需要 Root 私鑰，由 Root 產生新的 Intermediate
+func (s *InternalCAService) Import(ctx context.Context, name, rootCertPEM, rootKeyPEM, intermediateCertPEM, intermediateKeyPEM string) (*domain.InternalCA, error) {
+	roots, err := parseCertificateChain([]byte(rootCertPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Root 憑證: %w", err)
+	}
+	root := roots[0]
+	if err := checkSigningCA(root); err != nil {
+		return nil, fmt.Errorf("Root 憑證: %w", err)
+	}
+	if name == "" {
+		name = root.Subject.CommonName
+	}
+
+	ca := &domain.InternalCA{Name: name}
+
+	var rootKey crypto.Signer
+	if rootKeyPEM != "" {
+		key, err := parsePrivateKeyPEM([]byte(rootKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("Root 私鑰: %w", err)
+		}
+		if err := checkKeyMatch(root, key); err != nil {
+			return nil, fmt.Errorf("Root 私鑰: %w", err)
+		}
+		rootKey = key.(crypto.Signer)
+		if ca.RootEncryptedKey, err = s.encryptKey(key); err != nil {
+			return nil, err
+		}
+		ca.HasRootKey = true
+	}
+
+	if intermediateCertPEM == "" {
+		if rootKey == nil {
+			return nil, errors.New("未提供 Intermediate 時必須提供 Root 私鑰")
+		}
+		return s.saveWithIntermediate(ctx, ca, root, rootKey)
+	}
+
+	inters, err := parseCertificateChain([]byte(intermediateCertPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Intermediate 憑證: %w", err)
+	}
+	inter := inters[0]
+	// CheckSignatureFrom 只檢查簽章，不檢查 Intermediate 本身是否為 CA，否則 Leaf 也能被當成簽發用的 Intermediate
+	if err := checkSigningCA(inter); err != nil {
+		return nil, fmt.Errorf("Intermediate 憑證: %w", err)
+	}
+	if err := inter.CheckSignatureFrom(root); err != nil {
+		return nil, fmt.Errorf("Intermediate 不是由此 Root 簽發: %w", err)
+	}
+	interKey, err := parsePrivateKeyPEM([]byte(intermediateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("Intermediate 私鑰: %w", err)
+	}
+	if err := checkKeyMatch(inter, interKey); err != nil {
+		return nil, fmt.Errorf("Intermediate 私鑰: %w", err)
+	}
+
+	ca.RootCertPEM = encodeCertPEM(root)
+	ca.RootNotAfter = root.NotAfter
+	ca.IntermediateCertPEM = encodeCertPEM(inter)
+	ca.IntermediateNotAfter = inter.NotAfter
+	if ca.IntermediateEncryptedKey, err = s.encryptKey(interKey); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Save(ctx, ca); err != nil {
+		return nil, err
+	}
+	logrus.Infof("🏛️ [InternalCA] 已匯入 CA: %s", name)
+	return ca, nil
+}
+
+// Get 取得目前的 CA (尚未建立回傳 nil)
+func (s *InternalCAService) Get(ctx context.Context) (*domain.InternalCA, error) {
+	ca, err := s.Repo.Get(ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return ca, err
+}
+
+// Chain 回傳 Intermediate + Root (PEM)，給用戶端匯入信任
+func (s *InternalCAService) Chain(ctx context.Context) (string, error) {
+	ca, err := s.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	if ca == nil {
+		return "", errors.New("尚未建立內建 CA")
+	}
+	return ca.IntermediateCertPEM + ca.RootCertPEM, nil
+}
+
+// Issue 為域名簽發內部憑證，存入憑證庫並更新域名紀錄
+func (s *InternalCAService) Issue(ctx context.Context, cert domain.SSLCertificate, validityDays int) (*domain.ManagedCertificate, error) {
+	if validityDays <= 0 {
+		validityDays = defaultInternalLeafDays
+	}
+	if validityDays > maxInternalLeafDays {
+		return nil, fmt.Errorf("效期不可超過 %d 天", maxInternalLeafDays)
+	}
+
+	ca, err := s.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ca == nil {
+		return nil, errors.New("尚未建立內建 CA")
+	}
+	inters, err := parseCertificateChain([]byte(ca.IntermediateCertPEM))
+	if err != nil {
+		return nil, err
+	}
+	inter := inters[0]
+	interKey, err := s.decryptKey(ca.IntermediateEncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	notAfter := time.Now().Add(time.Duration(validityDays) * 24 * time.Hour)
+	if notAfter.After(inter.NotAfter) {
+		return nil, fmt.Errorf("Intermediate 將於 %s 到期，請先更新 CA", inter.NotAfter.Format("2006-01-02"))
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cert.DomainName},
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(cert.DomainName); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{cert.DomainName}
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, tmpl, inter, leafKey.Public(), interKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(leafKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	certPEM = append(certPEM, ca.IntermediateCertPEM...)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	mc, err := s.Store.Save(ctx, cert, domain.CertSourceInternal, certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// 內部主機通常掃描不到，直接以簽發結果更新域名的憑證資訊
+	updated := cert
+	updated.Issuer = mc.Issuer
+	updated.NotBefore = mc.NotBefore
+	updated.NotAfter = mc.NotAfter
+	updated.DaysRemaining = int(time.Until(mc.NotAfter).Hours() / 24)
+	updated.SANs = mc.SANs
+	if err := s.DomainRepo.UpdateCertInfo(ctx, updated); err != nil {
+		logrus.Errorf("❌ [InternalCA] 更新 %s 的憑證資訊失敗: %v", cert.DomainName, err)
+	}
+
+	if s.Deployer != nil {
+		s.Deployer.DeployCertificate(ctx, mc)
+	}
+
+	logrus.Infof("🏛️ [InternalCA] 已簽發 %s (到期: %s)", cert.DomainName, mc.NotAfter.Format("2006-01-02"))
+	return mc, nil
+}
+
+// RenewalStatus 判斷域名目前的憑證是否由內建 CA 管理，以及是否該續簽
+// 內部憑證依 Leaf 本身的效期判斷 (剩餘 < 1/3 或 < renewBeforeDays)，不依賴掃描結果
+func (s *InternalCAService) RenewalStatus(ctx context.Context, cert domain.SSLCertificate, renewBeforeDays int) (managed, due bool) {
+	latest, err := s.Store.Repo.GetLatestByDomainID(ctx, cert.ID)
+	if err != nil || latest.Source != domain.CertSourceInternal {
+		return false, false
+	}
+
+	remaining := time.Until(latest.NotAfter)
+	lifetime := latest.NotAfter.Sub(latest.NotBefore)
+	return true, remaining < lifetime/3 || remaining < time.Duration(renewBeforeDays)*24*time.Hour
+}
+
+// Renew 依上一張內部憑證的效期重新簽發
+func (s *InternalCAService) Renew(ctx context.Context, cert domain.SSLCertificate) (*domain.ManagedCertificate, error) {
+	validityDays := defaultInternalLeafDays
+	if latest, err := s.Store.Repo.GetLatestByDomainID(ctx, cert.ID); err == nil {
+		if days := int(latest.NotAfter.Sub(latest.NotBefore).Hours()/24 + 0.5); days > 0 && days <= maxInternalLeafDays {
+			validityDays = days
+		}
+	}
+	return s.Issue(ctx, cert, validityDays)
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// saveWithIntermediate 由 Root 簽出新的 Intermediate 後保存
+func (s *InternalCAService) saveWithIntermediate(ctx context.Context, ca *domain.InternalCA, root *x509.Certificate, rootKey crypto.Signer) (*domain.InternalCA, error) {
+	interKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	validity := internalIntermediateValidity
+	if remaining := time.Until(root.NotAfter); remaining < validity {
+		validity = remaining
+	}
+	interTmpl, err := caTemplate(ca.Name+" Intermediate", validity, 0)
+	if err != nil {
+		return nil, err
+	}
+	interDER, err := x509.CreateCertificate(rand.Reader, interTmpl, root, interKey.Public(), rootKey)
+	if err != nil {
+		return nil, err
+	}
+	inter, err := x509.ParseCertificate(interDER)
+	if err != nil {
+		return nil, err
+	}
+
+	ca.RootCertPEM = encodeCertPEM(root)
+	ca.RootNotAfter = root.NotAfter
+	ca.IntermediateCertPEM = encodeCertPEM(inter)
+	ca.IntermediateNotAfter = inter.NotAfter
+	if ca.IntermediateEncryptedKey, err = s.encryptKey(interKey); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.Save(ctx, ca); err != nil {
+		return nil, err
+	}
+	logrus.Infof("🏛️ [InternalCA] CA 已建立: %s (Intermediate 到期: %s)", ca.Name, inter.NotAfter.Format("2006-01-02"))
+	return ca, nil
+}
+
+func (s *InternalCAService) encryptKey(key crypto.PrivateKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.Store.encrypt(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func (s *InternalCAService) decryptKey(encrypted string) (crypto.Signer, error) {
+	keyPEM, err := s.Store.decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("不支援的私鑰類型")
+	}
+	return signer, nil
+}
+
+// checkSigningCA 確認憑證可以簽發其他憑證：Basic Constraints 為 CA，且有 Key Usage 時必須包含 Cert Sign
+func checkSigningCA(c *x509.Certificate) error {
+	if !c.BasicConstraintsValid || !c.IsCA {
+		return errors.New("不是 CA 憑證 (Basic Constraints 未標示 CA)")
+	}
+	if c.KeyUsage != 0 && c.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return errors.New("Key Usage 不包含 Certificate Sign")
+	}
+	return nil
+}
+
+// caTemplate 產生 CA 憑證的樣板 (maxPathLen 0 代表只能簽發 Leaf)
+func caTemplate(commonName string, validity time.Duration, maxPathLen int) (*x509.Certificate, error) {
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-5 * time.Minute),
+		NotAfter:              time.Now().Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            maxPathLen,
+		MaxPathLenZero:        maxPathLen == 0,
+	}, nil
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodeCertPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInternalCAImportRequiresCAIntermediate(t *testing.T) {
+	rootKey, rootPEM, root := testCACert(t, "Test Root", nil, nil)
+
+	// 由 Root 直接簽發的 Leaf：簽章正確，但不能拿來簽發憑證
+	leafKey := testKey(t)
+	leafTmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "www.example.com"},
+		DNSNames:    []string{"www.example.com"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafPEM := testSign(t, leafTmpl, root, &leafKey.PublicKey, rootKey)
+
+	// Basic Constraints 是 CA 但 Key Usage 不含 Cert Sign
+	noSignKey := testKey(t)
+	noSignTmpl, err := caTemplate("No Sign", time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noSignTmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	noSignPEM := testSign(t, noSignTmpl, root, &noSignKey.PublicKey, rootKey)
+
+	interKey, interPEM, _ := testCACert(t, "Test Intermediate", root, rootKey)
+
+	tests := []struct {
+		name    string
+		certPEM string
+		keyPEM  string
+		wantErr string
+	}{
+		{"leaf as intermediate", leafPEM, testKeyPEM(t, leafKey), "不是 CA"},
+		{"intermediate without cert sign", noSignPEM, testKeyPEM(t, noSignKey), "Certificate Sign"},
+		{"valid intermediate", interPEM, testKeyPEM(t, interKey), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memInternalCARepo{}
+			svc := NewInternalCAService(repo, nil, NewCertStoreService(nil, nil, "test-key"), nil, nil)
+
+			_, err := svc.Import(context.Background(), "", rootPEM, "", tt.certPEM, tt.keyPEM)
+			if tt.wantErr == "" {
+				if err != nil || repo.saved == nil {
+					t.Fatalf("Import: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Import err = %v, want %q", err, tt.wantErr)
+			}
+			if repo.saved != nil {
+				t.Error("CA saved despite invalid intermediate")
+			}
+		})
+	}
+}
+
+func TestInternalCAImportRejectsLeafRoot(t *testing.T) {
+	key := testKey(t)
+	leafPEM := testSign(t, &x509.Certificate{Subject: pkix.Name{CommonName: "self-signed leaf"}}, nil, &key.PublicKey, key)
+
+	svc := NewInternalCAService(&memInternalCARepo{}, nil, NewCertStoreService(nil, nil, "test-key"), nil, nil)
+	if _, err := svc.Import(context.Background(), "", leafPEM, testKeyPEM(t, key), "", ""); err == nil || !strings.Contains(err.Error(), "不是 CA") {
+		t.Fatalf("Import err = %v, want non-CA root error", err)
+	}
+}
+
+// testCACert 產生 CA 憑證 (parent 為 nil 時自簽)
+func testCACert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, string, *x509.Certificate) {
+	t.Helper()
+
+	key := testKey(t)
+	tmpl, err := caTemplate(name, 24*time.Hour, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parentKey == nil {
+		parentKey = key
+	}
+	certPEM := testSign(t, tmpl, parent, &key.PublicKey, parentKey)
+	certs, err := parseCertificateChain([]byte(certPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, certPEM, certs[0]
+}
+
+func testSign(t *testing.T, tmpl, parent *x509.Certificate, pub *ecdsa.PublicKey, signer *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	if tmpl.SerialNumber == nil {
+		serial, err := randomSerial()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.SerialNumber = serial
+	}
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotBefore, tmpl.NotAfter = time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodeCertPEM(&x509.Certificate{Raw: der})
+}
+
+func testKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testKeyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+type memInternalCARepo struct {
+	saved *domain.InternalCA
+}
+
+func (m *memInternalCARepo) Get(context.Context) (*domain.InternalCA, error) { return m.saved, nil }
+
+func (m *memInternalCARepo) Save(_ context.Context, ca *domain.InternalCA) error {
+	m.saved = ca
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/keystore"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// parsedCert 從檔案中解析出的一張憑證
+type parsedCert struct {
+	Entry  string
+	Format string
+	Cert   *x509.Certificate
+}
+
+// parseCertFile 依內容 (與副檔名) 判斷格式並取出所有憑證
+// PKCS#12 需要密碼才能解開，會依序嘗試空密碼與設定中的密碼；JKS 的憑證本身未加密，不需要密碼
+func parseCertFile(name string, data []byte, passwords []string) ([]parsedCert, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch {
+	case keystore.IsJKS(data):
+		return parseJKS(data)
+	case bytes.Contains(data, []byte("-----BEGIN")):
+		// 只有私鑰的檔案 (e.g. certbot 的 privkey.pem) 不是錯誤，直接略過
+		if !bytes.Contains(data, []byte("-----BEGIN CERTIFICATE")) && bytes.Contains(data, []byte("PRIVATE KEY-----")) {
+			return nil, nil
+		}
+		certs, err := parseCertificateChain(data)
+		if err != nil {
+			return nil, err
+		}
+		return indexedCerts(certs, domain.FileFormatPEM), nil
+	case ext == ".p12" || ext == ".pfx":
+		return parsePKCS12(data, passwords)
+	}
+
+	// DER：單張或串接的憑證
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		return indexedCerts(certs, domain.FileFormatDER), nil
+	}
+	// 沒有副檔名提示的 PKCS#12
+	if certs, err := parsePKCS12(data, passwords); err == nil {
+		return certs, nil
+	}
+	return nil, errors.New("無法辨識的憑證格式")
+}
+
+func indexedCerts(certs []*x509.Certificate, format string) []parsedCert {
+	result := make([]parsedCert, 0, len(certs))
+	for i, c := range certs {
+		result = append(result, parsedCert{Entry: fmt.Sprintf("#%d", i+1), Format: format, Cert: c})
+	}
+	return result
+}
+
+// parsePKCS12 支援 PBES2 (AES，OpenSSL 3 與本系統匯出的預設) 與傳統 3DES / RC2 加密的檔案
+func parsePKCS12(data []byte, passwords []string) ([]parsedCert, error) {
+	var lastErr error
+	for _, password := range append([]string{""}, passwords...) {
+		_, entries, err := keystore.DecodePKCS12(data, password)
+		if errors.Is(err, keystore.ErrIncorrectPassword) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("PKCS#12 解析失敗: %w", err)
+		}
+
+		result := make([]parsedCert, 0, len(entries))
+		for i, e := range entries {
+			entry := e.Alias
+			if entry == "" {
+				entry = fmt.Sprintf("#%d", i+1)
+			}
+			result = append(result, parsedCert{Entry: entry, Format: domain.FileFormatPKCS12, Cert: e.Cert})
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("PKCS#12 密碼不符 (請在 file_watch.passwords 設定): %w", lastErr)
+}
+
+// parseJKS 解析 Java KeyStore (JKS / JCEKS)，只讀取明文的憑證，不需要密碼
+func parseJKS(data []byte) ([]parsedCert, error) {
+	_, entries, err := keystore.DecodeJKS(data, "")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]parsedCert, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, parsedCert{Entry: e.Alias, Format: domain.FileFormatJKS, Cert: e.Cert})
+	}
+	return result, nil
+}
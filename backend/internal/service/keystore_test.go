@@ -0,0 +1,75 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/keystore"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 檔案監控必須能讀取本系統匯出的 PKCS#12 (PBES2 / AES-256) 與 JKS
+func TestParseCertFileReadsExportedKeystores(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "files.example.com"},
+		DNSNames:     []string{"files.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p12, err := keystore.EncodePKCS12(key, []*x509.Certificate{cert}, "files", "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jks, err := keystore.EncodeJKS("files", key, []*x509.Certificate{cert}, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		passwords []string
+		format    string
+		entry     string
+	}{
+		{"bundle.p12", p12, []string{"other", "s3cret"}, domain.FileFormatPKCS12, "files"},
+		{"bundle.pfx.bin", p12, []string{"s3cret"}, domain.FileFormatPKCS12, "files"}, // 沒有副檔名提示
+		{"bundle.jks", jks, nil, domain.FileFormatJKS, "files"},
+	}
+	for _, tt := range tests {
+		certs, err := parseCertFile(tt.name, tt.data, tt.passwords)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(certs) != 1 || certs[0].Format != tt.format || certs[0].Entry != tt.entry {
+			t.Fatalf("%s: got %+v", tt.name, certs)
+		}
+		if certs[0].Cert.Subject.CommonName != "files.example.com" {
+			t.Errorf("%s: CN = %q", tt.name, certs[0].Cert.Subject.CommonName)
+		}
+	}
+
+	if _, err := parseCertFile("bundle.p12", p12, []string{"wrong"}); err == nil || !strings.Contains(err.Error(), "file_watch.passwords") {
+		t.Errorf("wrong password: err = %v", err)
+	}
+}
@@ -0,0 +1,699 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	kubeListPageSize  = 500
+	kubeServiceTokens = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+var errKubeNotFound = errors.New("resource not found")
+
+// KubernetesService 從 Kubernetes 的 Ingress / HTTPRoute 探索 TLS 主機名稱 (實作 DNSProviderSource)
+// 每個設定的叢集一個 Provider，資源消失時域名會隨同步流程刪除
+type KubernetesService struct {
+	Clusters []conf.KubernetesClusterConfig
+	Repo     repository.DomainRepository
+}
+
+func NewKubernetesService(cfg conf.KubernetesConfig, repo repository.DomainRepository) *KubernetesService {
+	return &KubernetesService{Clusters: cfg.Clusters, Repo: repo}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Providers 每個叢集一個 Provider；連線設定錯誤時仍回傳，讓同步結果記為失敗 (實作 DNSProviderSource)
+func (s *KubernetesService) Providers(ctx context.Context) ([]DNSProvider, error) {
+	var providers []DNSProvider
+	for _, cluster := range s.Clusters {
+		client, err := newKubeClient(cluster)
+		name := cluster.Name
+		if name == "" {
+			name = cluster.Context
+		}
+		if name == "" {
+			name = "default"
+		}
+		providers = append(providers, &kubernetesDNSProvider{svc: s, cluster: cluster, name: name, client: client, initErr: err})
+	}
+	return providers, nil
+}
+
+// RecordSyncResult 叢集設定寫在設定檔，只記錄日誌 (實作 DNSProviderSource)
+func (s *KubernetesService) RecordSyncResult(ctx context.Context, p DNSProvider, count int, err error) {
+	if err != nil {
+		logrus.Warnf("⚠️ [K8s] %s 同步失敗: %v", p.Label(), err)
+		return
+	}
+	logrus.Infof("✅ [K8s] %s 同步完成，共 %d 筆域名", p.Label(), count)
+}
+
+// =============================================================================
+// DNSProvider 實作
+// =============================================================================
+
+// kubernetesDNSProvider Kubernetes 沒有 Zone 的概念，以主機名稱的註冊域名 (eTLD+1) 分組
+// ListZones 一次抓取所有資源，ListRecords 只回傳快取中該 Zone 的主機
+type kubernetesDNSProvider struct {
+	svc     *KubernetesService
+	cluster conf.KubernetesClusterConfig
+	name    string
+	client  *kubeClient
+	initErr error
+
+	hosts map[string][]DNSRecord // Zone -> 主機
+}
+
+func (p *kubernetesDNSProvider) Name() string {
+	return domain.ProviderKubernetes
+}
+
+func (p *kubernetesDNSProvider) Label() string {
+	return domain.ProviderKubernetes + "/" + p.name
+}
+
+func (p *kubernetesDNSProvider) ListZones(ctx context.Context) ([]DNSZone, error) {
+	if p.initErr != nil {
+		return nil, fmt.Errorf("叢集連線設定錯誤: %w", p.initErr)
+	}
+
+	records, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已由其他來源 (DNS Provider、手動、其他叢集) 管理的主機不接手，避免每次同步來回切換
+	dbDomains, _, err := p.svc.Repo.List(ctx, 1, 100000, "", "", "", "", "all", "")
+	if err != nil {
+		return nil, fmt.Errorf("讀取現有域名失敗: %w", err)
+	}
+	managed := make(map[string]bool)
+	for _, d := range dbDomains {
+		if !p.Owns(d) {
+			managed[strings.ToLower(d.DomainName)] = true
+		}
+	}
+
+	p.hosts = make(map[string][]DNSRecord)
+	for _, record := range records {
+		if managed[strings.ToLower(record.Name)] {
+			logrus.Debugf("   ⏭ [K8s] %s 已由其他來源管理，略過", record.Name)
+			continue
+		}
+		zone := hostZone(record.Name)
+		p.hosts[zone] = append(p.hosts[zone], record)
+	}
+
+	zones := make([]DNSZone, 0, len(p.hosts))
+	for zone := range p.hosts {
+		zones = append(zones, DNSZone{ID: zone, Name: zone})
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	return zones, nil
+}
+
+func (p *kubernetesDNSProvider) ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error) {
+	if p.hosts == nil {
+		if _, err := p.ListZones(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return p.hosts[zone.Name], nil
+}
+
+func (p *kubernetesDNSProvider) GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	records, err := p.ListRecords(ctx, DNSZone{ID: zoneID, Name: zoneID})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	for _, record := range records {
+		if record.ID == recordID {
+			return record, nil
+		}
+	}
+	return DNSRecord{}, fmt.Errorf("%w: Kubernetes 主機 %s", ErrRecordNotFound, recordID)
+}
+
+// Owns RecordID 以叢集名稱開頭 ("cluster|host")，同一主機只會屬於一個叢集
+func (p *kubernetesDNSProvider) Owns(d domain.SSLCertificate) bool {
+	return d.Provider == domain.ProviderKubernetes && strings.HasPrefix(d.RecordID, p.name+"|")
+}
+
+func (p *kubernetesDNSProvider) Annotate(d *domain.SSLCertificate) {}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// kubeHost 彙整同一個主機名稱在各資源中的來源、TLS Secret 與對外位址
+type kubeHost struct {
+	kind    string
+	sources []string
+	secrets []string
+	targets []string
+}
+
+// discover 列出 Ingress 與 HTTPRoute 的主機名稱 (Gateway API 未安裝時略過 HTTPRoute)
+func (p *kubernetesDNSProvider) discover(ctx context.Context) ([]DNSRecord, error) {
+	hosts := make(map[string]*kubeHost)
+	add := func(host, kind, source, secret string, targets []string) {
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+		if host == "" || net.ParseIP(host) != nil {
+			return
+		}
+		h, ok := hosts[host]
+		if !ok {
+			h = &kubeHost{kind: kind}
+			hosts[host] = h
+		}
+		if kind == "INGRESS" {
+			h.kind = kind
+		}
+		h.sources = appendUnique(h.sources, source)
+		if secret != "" {
+			h.secrets = appendUnique(h.secrets, secret)
+		}
+		for _, t := range targets {
+			h.targets = appendUnique(h.targets, t)
+		}
+	}
+
+	// 1. Ingress (networking.k8s.io/v1)
+	ingresses, err := listKube[kubeIngress](ctx, p.client, "/apis/networking.k8s.io/v1", "ingresses", p.cluster.Namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("列出 Ingress 失敗: %w", err)
+	}
+	for _, ing := range ingresses {
+		source := "Ingress " + ing.Metadata.Namespace + "/" + ing.Metadata.Name
+		var targets []string
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			targets = append(targets, firstNonEmpty(lb.Hostname, lb.IP))
+		}
+		// TLS 區段的主機也是 TLS 端點 (即使沒有對應的 rule)
+		for _, t := range ing.Spec.TLS {
+			for _, host := range t.Hosts {
+				add(host, "INGRESS", source, ing.tlsSecretFor(host), targets)
+			}
+		}
+		for _, rule := range ing.Spec.Rules {
+			add(rule.Host, "INGRESS", source, ing.tlsSecretFor(rule.Host), targets)
+		}
+	}
+
+	// 2. HTTPRoute (gateway.networking.k8s.io/v1)，TLS Secret 來自上層 Gateway 的 Listener
+	routes, err := listKube[kubeHTTPRoute](ctx, p.client, "/apis/gateway.networking.k8s.io/v1", "httproutes", p.cluster.Namespaces)
+	if errors.Is(err, errKubeNotFound) {
+		logrus.Debugf("   [K8s] %s 未安裝 Gateway API，略過 HTTPRoute", p.Label())
+	} else if err != nil {
+		return nil, fmt.Errorf("列出 HTTPRoute 失敗: %w", err)
+	} else {
+		// Gateway 可能位於其他 Namespace 或沒有讀取權限，失敗時只是少了 TLS Secret 資訊
+		gateways, err := listKube[kubeGateway](ctx, p.client, "/apis/gateway.networking.k8s.io/v1", "gateways", p.cluster.Namespaces)
+		if err != nil && !errors.Is(err, errKubeNotFound) {
+			logrus.Warnf("⚠️ [K8s] %s 列出 Gateway 失敗: %v", p.Label(), err)
+		}
+		gatewayMap := make(map[string]kubeGateway, len(gateways))
+		for _, gw := range gateways {
+			gatewayMap[gw.Metadata.Namespace+"/"+gw.Metadata.Name] = gw
+		}
+
+		for _, route := range routes {
+			source := "HTTPRoute " + route.Metadata.Namespace + "/" + route.Metadata.Name
+			for _, ref := range route.Spec.ParentRefs {
+				if ref.Kind != "" && ref.Kind != "Gateway" {
+					continue
+				}
+				ns := firstNonEmpty(ref.Namespace, route.Metadata.Namespace)
+				gw := gatewayMap[ns+"/"+ref.Name]
+				var targets []string
+				for _, addr := range gw.Status.Addresses {
+					targets = append(targets, addr.Value)
+				}
+
+				for _, l := range gw.Spec.Listeners {
+					if ref.SectionName != "" && ref.SectionName != l.Name {
+						continue
+					}
+					// 沒有 hostnames 的 HTTPRoute 繼承 Listener 的主機名稱
+					names := route.Spec.Hostnames
+					if len(names) == 0 && l.Hostname != "" {
+						names = []string{l.Hostname}
+					}
+					for _, host := range names {
+						if !listenerMatches(l.Hostname, host) {
+							continue
+						}
+						add(host, "HTTPROUTE", source, l.tlsSecret(gw.Metadata.Namespace), targets)
+					}
+				}
+				// 找不到 Gateway (沒有權限或不在同一叢集) 時仍記錄主機
+				if len(gw.Spec.Listeners) == 0 {
+					for _, host := range route.Spec.Hostnames {
+						add(host, "HTTPROUTE", source, "", nil)
+					}
+				}
+			}
+		}
+	}
+
+	records := make([]DNSRecord, 0, len(hosts))
+	for host, h := range hosts {
+		comment := strings.Join(h.sources, "; ")
+		if len(h.secrets) > 0 {
+			comment += " | TLS: " + strings.Join(h.secrets, ", ")
+		}
+		sort.Strings(h.targets)
+		records = append(records, DNSRecord{
+			ID:      p.name + "|" + host,
+			Name:    host,
+			Type:    h.kind,
+			Content: strings.Join(h.targets, ","),
+			Comment: comment,
+		})
+	}
+	logrus.Infof("   ✅ [K8s] %s 探索完成 (Ingress %d / HTTPRoute %d / 主機 %d)", p.Label(), len(ingresses), len(routes), len(records))
+	return records, nil
+}
+
+// =============================================================================
+// Kubernetes API 資源格式 (只取需要的欄位)
+// =============================================================================
+
+type kubeMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type kubeIngress struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+		TLS []struct {
+			Hosts      []string `json:"hosts"`
+			SecretName string   `json:"secretName"`
+		} `json:"tls"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP       string `json:"ip"`
+				Hostname string `json:"hostname"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+func (ing kubeIngress) tlsSecretFor(host string) string {
+	for _, t := range ing.Spec.TLS {
+		for _, h := range t.Hosts {
+			if strings.EqualFold(h, host) && t.SecretName != "" {
+				return ing.Metadata.Namespace + "/" + t.SecretName
+			}
+		}
+	}
+	return ""
+}
+
+type kubeHTTPRoute struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		Hostnames  []string `json:"hostnames"`
+		ParentRefs []struct {
+			Kind        string `json:"kind"`
+			Name        string `json:"name"`
+			Namespace   string `json:"namespace"`
+			SectionName string `json:"sectionName"`
+		} `json:"parentRefs"`
+	} `json:"spec"`
+}
+
+type kubeGateway struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		Listeners []kubeListener `json:"listeners"`
+	} `json:"spec"`
+	Status struct {
+		Addresses []struct {
+			Value string `json:"value"`
+		} `json:"addresses"`
+	} `json:"status"`
+}
+
+type kubeListener struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	TLS      *struct {
+		CertificateRefs []struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"certificateRefs"`
+	} `json:"tls"`
+}
+
+func (l kubeListener) tlsSecret(gatewayNamespace string) string {
+	if l.TLS == nil || len(l.TLS.CertificateRefs) == 0 {
+		return ""
+	}
+	ref := l.TLS.CertificateRefs[0]
+	return firstNonEmpty(ref.Namespace, gatewayNamespace) + "/" + ref.Name
+}
+
+// =============================================================================
+// Kubernetes API Client (只需 GET + 分頁)
+// =============================================================================
+
+type kubeClient struct {
+	server    string
+	http      *http.Client
+	token     string
+	tokenFile string // In-Cluster 的 Token 會輪替，每次請求重新讀取
+	username  string
+	password  string
+}
+
+// listKube 列出資源 (namespaces 為空代表整個叢集)，自動處理 continue 分頁
+func listKube[T any](ctx context.Context, c *kubeClient, apiPath, resource string, namespaces []string) ([]T, error) {
+	paths := []string{apiPath + "/" + resource}
+	if len(namespaces) > 0 {
+		paths = paths[:0]
+		for _, ns := range namespaces {
+			paths = append(paths, apiPath+"/namespaces/"+url.PathEscape(ns)+"/"+resource)
+		}
+	}
+
+	var items []T
+	for _, path := range paths {
+		cont := ""
+		for {
+			query := url.Values{"limit": {fmt.Sprint(kubeListPageSize)}}
+			if cont != "" {
+				query.Set("continue", cont)
+			}
+			var page struct {
+				Metadata struct {
+					Continue string `json:"continue"`
+				} `json:"metadata"`
+				Items []T `json:"items"`
+			}
+			if err := c.get(ctx, path, query, &page); err != nil {
+				return nil, err
+			}
+			items = append(items, page.Items...)
+			if page.Metadata.Continue == "" {
+				break
+			}
+			cont = page.Metadata.Continue
+		}
+	}
+	return items, nil
+}
+
+func (c *kubeClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("讀取 ServiceAccount Token 失敗: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errKubeNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &status) == nil && status.Message != "" {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, status.Message)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// kubeconfig 只解析需要的欄位 (不支援 exec / auth-provider 外掛)
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Username              string      `yaml:"username"`
+			Password              string      `yaml:"password"`
+			Exec                  interface{} `yaml:"exec"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// newKubeClient 依設定建立 Client：in_cluster 使用 ServiceAccount，否則讀取 kubeconfig
+func newKubeClient(cfg conf.KubernetesClusterConfig) (*kubeClient, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	client := &kubeClient{}
+
+	if cfg.InCluster {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("不在 Kubernetes 叢集內 (缺少 KUBERNETES_SERVICE_HOST)")
+		}
+		ca, err := os.ReadFile(filepath.Join(kubeServiceTokens, "ca.crt"))
+		if err != nil {
+			return nil, fmt.Errorf("讀取叢集 CA 失敗: %w", err)
+		}
+		if tlsConfig.RootCAs, err = certPool(ca); err != nil {
+			return nil, err
+		}
+		client.server = "https://" + net.JoinHostPort(host, port)
+		client.tokenFile = filepath.Join(kubeServiceTokens, "token")
+	} else {
+		path := cfg.Kubeconfig
+		if path == "" {
+			path = os.Getenv("KUBECONFIG")
+		}
+		if path == "" {
+			return nil, fmt.Errorf("未設定 kubeconfig")
+		}
+		if err := client.loadKubeconfig(path, cfg.Context, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	client.server = strings.TrimRight(client.server, "/")
+	client.http = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	return client, nil
+}
+
+func (c *kubeClient) loadKubeconfig(path, contextName string, tlsConfig *tls.Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("讀取 kubeconfig 失敗: %w", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return fmt.Errorf("kubeconfig 格式錯誤: %w", err)
+	}
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+	var clusterName, userName string
+	for _, ctx := range kc.Contexts {
+		if ctx.Name == contextName {
+			clusterName, userName = ctx.Context.Cluster, ctx.Context.User
+		}
+	}
+	if clusterName == "" {
+		return fmt.Errorf("kubeconfig 找不到 Context: %s", contextName)
+	}
+
+	for _, cl := range kc.Clusters {
+		if cl.Name != clusterName {
+			continue
+		}
+		c.server = cl.Cluster.Server
+		tlsConfig.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+		ca, err := readInlineOrFile(cl.Cluster.CertificateAuthorityData, resolve(cl.Cluster.CertificateAuthority))
+		if err != nil {
+			return fmt.Errorf("讀取叢集 CA 失敗: %w", err)
+		}
+		if len(ca) > 0 {
+			if tlsConfig.RootCAs, err = certPool(ca); err != nil {
+				return err
+			}
+		}
+	}
+	if c.server == "" {
+		return fmt.Errorf("kubeconfig 找不到 Cluster: %s", clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil && u.User.Token == "" {
+			return fmt.Errorf("不支援 exec 驗證外掛，請改用 ServiceAccount Token")
+		}
+		c.token = u.User.Token
+		c.tokenFile = resolve(u.User.TokenFile)
+		c.username, c.password = u.User.Username, u.User.Password
+
+		certPEM, err := readInlineOrFile(u.User.ClientCertificateData, resolve(u.User.ClientCertificate))
+		if err != nil {
+			return fmt.Errorf("讀取 Client 憑證失敗: %w", err)
+		}
+		keyPEM, err := readInlineOrFile(u.User.ClientKeyData, resolve(u.User.ClientKey))
+		if err != nil {
+			return fmt.Errorf("讀取 Client 私鑰失敗: %w", err)
+		}
+		if len(certPEM) > 0 && len(keyPEM) > 0 {
+			pair, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return fmt.Errorf("Client 憑證格式錯誤: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// readInlineOrFile kubeconfig 的 *-data 欄位為 Base64，否則讀取檔案
+func readInlineOrFile(data, path string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func certPool(pemData []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("無效的 CA 憑證")
+	}
+	return pool, nil
+}
+
+// hostZone 以註冊域名 (eTLD+1) 作為 Zone，無法判斷時使用主機名稱本身
+func hostZone(host string) string {
+	zone, err := publicsuffix.EffectiveTLDPlusOne(strings.TrimPrefix(host, "*."))
+	if err != nil {
+		return strings.TrimPrefix(host, "*.")
+	}
+	return zone
+}
+
+// listenerMatches 判斷主機名稱是否符合 Gateway Listener 的 hostname (空值代表全部，支援 *. 萬用字元)
+func listenerMatches(listener, host string) bool {
+	if listener == "" {
+		return true
+	}
+	if strings.HasPrefix(listener, "*.") {
+		return strings.HasSuffix(strings.ToLower(host), strings.ToLower(listener[1:]))
+	}
+	return strings.EqualFold(listener, host)
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, item := range list {
+		if item == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeKubeAPI 模擬 API Server 的 list 端點，每頁只回傳一筆以測試 continue 分頁
+type fakeKubeAPI struct {
+	t *testing.T
+
+	mu         sync.Mutex
+	ingresses  []string
+	routes     []string
+	gateways   []string
+	gatewayAPI bool // false 時 Gateway API 的端點回 404 (未安裝 CRD)
+	failList   bool // true 時 Ingress 列表回 500
+}
+
+func (f *fakeKubeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"kind":"Status","message":"Unauthorized"}`)
+		return
+	}
+	if r.URL.Query().Get("limit") != strconv.Itoa(kubeListPageSize) {
+		f.t.Errorf("%s: missing limit parameter", r.URL)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []string
+	switch r.URL.Path {
+	case "/apis/networking.k8s.io/v1/ingresses":
+		if f.failList {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"kind":"Status","message":"etcdserver: request timed out"}`)
+			return
+		}
+		items = f.ingresses
+	case "/apis/gateway.networking.k8s.io/v1/httproutes", "/apis/gateway.networking.k8s.io/v1/gateways":
+		if !f.gatewayAPI {
+			http.NotFound(w, r)
+			return
+		}
+		items = f.routes
+		if r.URL.Path == "/apis/gateway.networking.k8s.io/v1/gateways" {
+			items = f.gateways
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	idx := 0
+	if cont := r.URL.Query().Get("continue"); cont != "" {
+		idx, _ = strconv.Atoi(cont)
+	}
+	page := struct {
+		Metadata struct {
+			Continue string `json:"continue,omitempty"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}{Items: []json.RawMessage{}}
+	if idx < len(items) {
+		page.Items = append(page.Items, json.RawMessage(items[idx]))
+	}
+	if idx+1 < len(items) {
+		page.Metadata.Continue = strconv.Itoa(idx + 1)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// newFakeKubeCluster 啟動 TLS 的 Fake API Server，並寫出指向它的 kubeconfig
+func newFakeKubeCluster(t *testing.T, repo repository.DomainRepository) (*KubernetesService, *fakeKubeAPI) {
+	api := &fakeKubeAPI{
+		t: t,
+		ingresses: []string{
+			`{"metadata":{"name":"shop","namespace":"default"},
+			  "spec":{"tls":[{"hosts":["shop.example.com"],"secretName":"shop-tls"}],
+			          "rules":[{"host":"shop.example.com"},{"host":"api.example.com"}]},
+			  "status":{"loadBalancer":{"ingress":[{"hostname":"lb.example.net"}]}}}`,
+			`{"metadata":{"name":"blog","namespace":"web"},
+			  "spec":{"rules":[{"host":"Blog.Example.org."},{"host":"10.0.0.1"},{"host":""}]},
+			  "status":{"loadBalancer":{"ingress":[{"ip":"198.51.100.7"}]}}}`,
+		},
+		routes: []string{
+			`{"metadata":{"name":"web","namespace":"apps"},
+			  "spec":{"hostnames":["www.example.com","other.test"],
+			          "parentRefs":[{"name":"gw","namespace":"infra","sectionName":"https"}]}}`,
+		},
+		gateways: []string{
+			`{"metadata":{"name":"gw","namespace":"infra"},
+			  "spec":{"listeners":[
+			    {"name":"http","hostname":"*.other.test"},
+			    {"name":"https","hostname":"*.example.com","tls":{"certificateRefs":[{"name":"wild-tls"}]}}]},
+			  "status":{"addresses":[{"value":"203.0.113.10"}]}}`,
+		},
+		gatewayAPI: true,
+	}
+	srv := httptest.NewTLSServer(api)
+	t.Cleanup(srv.Close)
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: fake
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: robot
+  user:
+    token: test-token
+contexts:
+- name: test
+  context:
+    cluster: fake
+    user: robot
+`, srv.URL, base64.StdEncoding.EncodeToString(caPEM))
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewKubernetesService(conf.KubernetesConfig{Clusters: []conf.KubernetesClusterConfig{{Name: "prod", Kubeconfig: path}}}, repo)
+	return svc, api
+}
+
+// syncKube 模擬一次同步：列出 Zone 與主機並轉成域名
+func syncKube(t *testing.T, svc *KubernetesService) (DNSProvider, []domain.SSLCertificate, *ZoneSyncReport, error) {
+	t.Helper()
+
+	providers, err := svc.Providers(context.Background())
+	if err != nil || len(providers) != 1 {
+		t.Fatalf("Providers = %v, %v", providers, err)
+	}
+	p := providers[0]
+
+	zones, err := p.ListZones(context.Background())
+	if err != nil {
+		return p, nil, nil, err
+	}
+	report := &ZoneSyncReport{}
+	var domains []domain.SSLCertificate
+	for _, zone := range zones {
+		records, err := p.ListRecords(context.Background(), zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range records {
+			domains = append(domains, mapRecordToDomain(p, zone, record, time.Time{}, 0))
+		}
+		report.Synced = append(report.Synced, zone.Name)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].DomainName < domains[j].DomainName })
+	return p, domains, report, nil
+}
+
+func TestKubernetesDiscoversIngressAndHTTPRouteHosts(t *testing.T) {
+	repo := &memDomainRepo{domains: []domain.SSLCertificate{
+		// 已由 Cloudflare 管理的主機不接手
+		{ID: primitive.NewObjectID(), DomainName: "api.example.com", Provider: domain.ProviderCloudflare, ZoneName: "example.com"},
+	}}
+	svc, _ := newFakeKubeCluster(t, repo)
+
+	p, domains, _, err := syncKube(t, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type host struct{ name, zone, recordID, kind, content, comment string }
+	want := []host{
+		{"blog.example.org", "example.org", "prod|blog.example.org", "INGRESS", "198.51.100.7", "Ingress web/blog"},
+		{"shop.example.com", "example.com", "prod|shop.example.com", "INGRESS", "lb.example.net", "Ingress default/shop | TLS: default/shop-tls"},
+		{"www.example.com", "example.com", "prod|www.example.com", "HTTPROUTE", "203.0.113.10", "HTTPRoute apps/web | TLS: infra/wild-tls"},
+	}
+	if len(domains) != len(want) {
+		t.Fatalf("discovered %d hosts, want %d: %+v", len(domains), len(want), domains)
+	}
+	for i, w := range want {
+		d := domains[i]
+		got := host{d.DomainName, d.ZoneName, d.RecordID, d.CFRecordType, d.CFOriginValue, d.CFComment}
+		if got != w {
+			t.Errorf("host %d = %+v, want %+v", i, got, w)
+		}
+		if d.Provider != domain.ProviderKubernetes || !p.Owns(d) {
+			t.Errorf("%s is not owned by the cluster provider", d.DomainName)
+		}
+	}
+
+	// GetRecord 依 RecordID 找回主機
+	record, err := p.GetRecord(context.Background(), "example.com", "prod|www.example.com")
+	if err != nil || record.Name != "www.example.com" {
+		t.Errorf("GetRecord = %+v, %v", record, err)
+	}
+}
+
+func TestKubernetesRemovesHostsWhoseResourcesDisappear(t *testing.T) {
+	repo := &memDomainRepo{}
+	svc, api := newFakeKubeCluster(t, repo)
+
+	// 第一次同步：全部主機寫入資料庫，另外放一筆手動域名與其他叢集的域名
+	_, first, _, err := syncKube(t, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range first {
+		d.ID = primitive.NewObjectID()
+		repo.domains = append(repo.domains, d)
+	}
+	repo.domains = append(repo.domains,
+		domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: "manual.example.com", ZoneName: "example.com"},
+		domain.SSLCertificate{ID: primitive.NewObjectID(), DomainName: "staging.example.com", Provider: domain.ProviderKubernetes, ZoneName: "example.com", RecordID: "staging|staging.example.com"},
+	)
+
+	// Ingress shop 被刪除、Gateway API 被移除
+	api.mu.Lock()
+	api.ingresses = api.ingresses[1:]
+	api.gatewayAPI = false
+	api.mu.Unlock()
+
+	deleted := runKubeDeletions(t, svc, repo)
+	sort.Strings(deleted)
+	if want := []string{"api.example.com", "shop.example.com", "www.example.com"}; fmt.Sprint(deleted) != fmt.Sprint(want) {
+		t.Errorf("deleted %v, want %v", deleted, want)
+	}
+	var remaining []string
+	for _, d := range repo.domains {
+		remaining = append(remaining, d.DomainName)
+	}
+	sort.Strings(remaining)
+	if want := []string{"blog.example.org", "manual.example.com", "staging.example.com"}; fmt.Sprint(remaining) != fmt.Sprint(want) {
+		t.Errorf("remaining %v, want %v", remaining, want)
+	}
+
+	// API Server 出錯時不可當成資源消失
+	api.mu.Lock()
+	api.failList = true
+	api.mu.Unlock()
+	if deleted := runKubeDeletions(t, svc, repo); len(deleted) != 0 {
+		t.Errorf("deleted %v while the API server was failing", deleted)
+	}
+	if len(repo.domains) != 3 {
+		t.Errorf("%d domains left after a failed sync, want 3", len(repo.domains))
+	}
+}
+
+// runKubeDeletions 重新同步叢集並執行 CronService 的刪除比對，回傳被刪除的域名
+func runKubeDeletions(t *testing.T, svc *KubernetesService, repo *memDomainRepo) []string {
+	t.Helper()
+
+	p, fetched, report, err := syncKube(t, svc)
+	cron := &CronService{Repo: repo, Notifier: &NotifierService{Repo: repo}}
+	results := []providerSyncResult{{Provider: p, Source: svc, Report: report, Err: err}}
+
+	db := append([]domain.SSLCertificate(nil), repo.domains...)
+	scope := cron.recordProviderResults(context.Background(), results, fetched, db)
+	var stats SyncStats
+	cron.processDeletions(context.Background(), fetched, db, scope, &stats)
+	return stats.DeletedNames
+}
+
+// =============================================================================
+// In-memory Repositories
+// =============================================================================
+
+// memDomainRepo 只實作同步、刪除與 CT 監控會用到的方法
+type memDomainRepo struct {
+	repository.DomainRepository
+
+	mu       sync.Mutex
+	domains  []domain.SSLCertificate
+	settings domain.NotificationSettings
+}
+
+func (m *memDomainRepo) List(_ context.Context, _, _ int64, _, _, _, _, _, zoneFilter string) ([]domain.SSLCertificate, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []domain.SSLCertificate
+	for _, d := range m.domains {
+		if zoneFilter == "" || d.ZoneName == zoneFilter {
+			list = append(list, d)
+		}
+	}
+	return list, int64(len(list)), nil
+}
+
+func (m *memDomainRepo) Delete(_ context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, d := range m.domains {
+		if d.ID == id {
+			m.domains = append(m.domains[:i], m.domains[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *memDomainRepo) GetUniqueZones(context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var zones []string
+	for _, d := range m.domains {
+		if d.ZoneName != "" {
+			zones = appendUnique(zones, d.ZoneName)
+		}
+	}
+	return zones, nil
+}
+
+// GetSettings 未設定時為零值 (通知全部關閉)
+func (m *memDomainRepo) GetSettings(context.Context) (*domain.NotificationSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := m.settings
+	return &settings, nil
+}
@@ -144,16 +144,16 @@ func (n *NotifierService) startWebhookWorker() {
 	}
 }
 
-// CheckAndNotify 檢查並發送告警 (核心邏輯)
-func (n *NotifierService) CheckAndNotify(ctx context.Context, cert domain.SSLCertificate) {
+// CheckAndNotify 檢查並發送告警 (核心邏輯)，有實際發送時回傳 true，由呼叫端更新各自的告警時間
+func (n *NotifierService) CheckAndNotify(ctx context.Context, cert domain.SSLCertificate) bool {
 	// 1. 判斷告警條件 (邏輯保持不變)
 	if cert.IsIgnored {
-		return
+		return false
 	}
 
 	// [修改 1] 放行條件：如果是 ConnectionError，即使日期是零值也要往下跑
 	if cert.NotAfter.IsZero() && cert.Status != domain.StatusConnectionError {
-		return
+		return false
 	}
 
 	// if cert.NotAfter.IsZero() {
@@ -183,6 +183,12 @@ func (n *NotifierService) CheckAndNotify(ctx context.Context, cert domain.SSLCer
 		}
 	}
 
+	// 多節點中有節點使用不同、過期或名稱不符的憑證
+	if cert.EndpointIssue != "" && cert.Status != domain.StatusConnectionError {
+		alertReasons = append(alertReasons, fmt.Sprintf("❌ 節點憑證異常 (%s)", cert.EndpointIssue))
+		shouldNotify = true
+	}
+
 	// B. 域名註冊過期檢查 (< 30 天)
 	// 注意：需確保 DomainDaysLeft 有效 (例如 > -10000，避免初始值 0 誤判)
 	// 這裡假設 DomainExpiryDate 不為零值才判斷
@@ -205,26 +211,70 @@ func (n *NotifierService) CheckAndNotify(ctx context.Context, cert domain.SSLCer
 
 	// 如果沒有任何告警原因，直接返回
 	if len(alertReasons) == 0 {
-		return
+		return false
 	}
 
 	if !shouldNotify {
-		return
+		return false
 	}
 
 	// 2. 防騷擾 (24hr)
 	if time.Since(cert.LastAlertTime) < 24*time.Hour {
-		return
+		return false
+	}
+
+	return n.sendExpiryAlert(ctx, cert, alertReasons)
+}
+
+// CheckAndNotifyOrigin 源站憑證 (Proxy 後方，cert.OriginCheck) 的到期與名稱不符告警
+// 防騷擾使用 OriginLastAlertTime，與邊緣憑證分開計算；有實際發送時回傳 true，由呼叫端更新時間
+func (n *NotifierService) CheckAndNotifyOrigin(ctx context.Context, cert domain.SSLCertificate) bool {
+	origin := cert.OriginCheck
+	if cert.IsIgnored || origin == nil || origin.Error != "" {
+		return false
 	}
 
+	var alertReasons []string
+	if origin.DaysRemaining < 0 {
+		alertReasons = append(alertReasons, "源站憑證已過期")
+	} else if origin.DaysRemaining < 30 {
+		alertReasons = append(alertReasons, fmt.Sprintf("源站憑證剩餘 %d 天", origin.DaysRemaining))
+	}
+	// Full (strict) 模式下 Cloudflare 會驗證源站憑證，名稱不符會回應 526
+	if !origin.IsMatch {
+		alertReasons = append(alertReasons, "❌ 源站憑證名稱不符 (Full strict 模式將回應 526)")
+	}
+	if len(alertReasons) == 0 || time.Since(cert.OriginLastAlertTime) < 24*time.Hour {
+		return false
+	}
+
+	status := domain.StatusActive
+	if origin.DaysRemaining < 0 {
+		status = domain.StatusExpired
+	}
+	return n.sendExpiryAlert(ctx, domain.SSLCertificate{
+		DomainName:     fmt.Sprintf("%s (源站 %s)", cert.DomainName, origin.IP),
+		Status:         status,
+		Issuer:         origin.Issuer,
+		NotAfter:       origin.NotAfter,
+		DaysRemaining:  origin.DaysRemaining,
+		DomainDaysLeft: cert.DomainDaysLeft,
+		TLSVersion:     origin.TLSVersion,
+		ResolvedRecord: cert.CFOriginValue,
+		IsMatch:        origin.IsMatch,
+	}, alertReasons)
+}
+
+// sendExpiryAlert 依設定的模板發送告警 (不含條件判斷與防騷擾)
+func (n *NotifierService) sendExpiryAlert(ctx context.Context, cert domain.SSLCertificate, alertReasons []string) bool {
 	// 3. 獲取設定
 	settings, err := n.Repo.GetSettings(ctx)
 	if err != nil {
-		return
+		return false
 	}
 
 	if !settings.NotifyOnExpiry {
-		return
+		return false
 	}
 
 	reasonStr := strings.Join(alertReasons, ", ")
@@ -268,9 +318,7 @@ func (n *NotifierService) CheckAndNotify(ctx context.Context, cert domain.SSLCer
 
 	// 7. 發送
 	n.sendToChannels(settings, msg)
-
-	// 更新最後告警時間
-	n.Repo.UpdateAlertTime(ctx, cert.ID)
+	return true
 }
 
 // [修改] 測試訊息：接收設定物件，而不是單一 URL
@@ -368,6 +416,10 @@ const (
 	EventScanFinish EventType = "SCAN_FINISH"
 	EventZoneAdd    EventType = "ZONE_ADD"
 	EventZoneDelete EventType = "ZONE_DELETE"
+	EventDeploy     EventType = "DEPLOY"
+
+	EventUnexpectedIssuance EventType = "UNEXPECTED_ISSUANCE" // CT Log 發現非允許清單 Issuer 簽發的憑證
+	EventZoneSecurity       EventType = "ZONE_SECURITY"       // Zone SSL/TLS 設定或 Edge 憑證包異常
 )
 
 // 定義給操作模板用的資料結構
@@ -387,10 +439,13 @@ const (
 	defaultRenewTpl  = "♻️ <b>[SSL 憑證續簽成功]</b>\n\n🌐 域名: <b>{{.Domain}}</b>\n{{.Details}}"
 	defaultUpdateTpl = "🛠 [DNS 變更]\n對象: {{.Domain}}\n內容: {{.Details}}"
 	// [新增] 匯總報告預設模板
-	defaultSyncFinishTpl = "☁️ [Cloudflare 同步完成]\n新增: {{.Added}}\n更新: {{.Updated}}\n刪除: {{.Deleted}}\n略過: {{.Skipped}}\n耗時: {{.Duration}}"
+	defaultSyncFinishTpl = "☁️ [Cloudflare 同步完成]\n新增: {{.Added}}\n更新: {{.Updated}}\n刪除: {{.Deleted}}\n略過: {{.Skipped}}\n未變更: {{.Unchanged}}\n耗時: {{.Duration}}"
 	defaultScanFinishTpl = "🔍 [SSL 掃描完成]\n總數: {{.Total}}\n正常: {{.Active}}\n過期: {{.Expired}}\n異常: {{.Warning}}\n耗時: {{.Duration}}"
 	defaultZoneAddTpl    = "🌍 <b>[新增主域名]</b>\nZone: {{.Domain}}\n詳情: {{.Details}}"
 	defaultZoneDeleteTpl = "💥 <b>[移除主域名]</b>\nZone: {{.Domain}}\n詳情: {{.Details}}"
+	defaultDeployTpl     = "🚚 <b>[憑證部署]</b>\n🌐 域名: <b>{{.Domain}}</b>\n{{.Details}}"
+	defaultIssuanceTpl   = "🚨 <b>[非預期的憑證簽發]</b>\n🌐 域名: <b>{{.Domain}}</b>\n{{.Details}}"
+	defaultZoneSecTpl    = "🔐 <b>[Zone SSL/TLS 異常]</b>\nZone: <b>{{.Domain}}</b>\n{{.Details}}"
 )
 
 type ExpiryTemplateData struct {
@@ -471,6 +526,27 @@ func (n *NotifierService) NotifyOperation(ctx context.Context, eventType EventTy
 			tmplStr = defaultZoneDeleteTpl
 		}
 		actionName = "移除 Zone"
+	case EventDeploy:
+		enabled = settings.NotifyOnDeploy
+		tmplStr = settings.NotifyOnDeployTemplate
+		if tmplStr == "" {
+			tmplStr = defaultDeployTpl
+		}
+		actionName = "憑證部署"
+	case EventUnexpectedIssuance:
+		enabled = settings.NotifyOnUnexpectedIssuance
+		tmplStr = settings.NotifyOnUnexpectedIssuanceTemplate
+		if tmplStr == "" {
+			tmplStr = defaultIssuanceTpl
+		}
+		actionName = "非預期簽發"
+	case EventZoneSecurity:
+		enabled = settings.NotifyOnZoneSecurity
+		tmplStr = settings.NotifyOnZoneSecurityTemplate
+		if tmplStr == "" {
+			tmplStr = defaultZoneSecTpl
+		}
+		actionName = "Zone SSL/TLS 異常"
 	default:
 		return // 未知事件不處理
 	}
@@ -516,17 +592,18 @@ func (n *NotifierService) SendTestMessage(ctx context.Context, settings domain.N
 
 // 我們需要一個新的結構來傳遞匯總數據
 type TaskSummaryData struct {
-	Added    int
-	Updated  int
-	Deleted  int
-	Skipped  int
-	Total    int
-	Active   int
-	Expired  int
-	Warning  int
-	Duration string
-	Time     string
-	Details  string // [新增] 用來放格式化後的詳細清單
+	Added     int
+	Updated   int
+	Deleted   int
+	Skipped   int
+	Unchanged int // 增量同步中未修改、未重新掃描的紀錄
+	Total     int
+	Active    int
+	Expired   int
+	Warning   int
+	Duration  string
+	Time      string
+	Details   string // [新增] 用來放格式化後的詳細清單
 }
 
 // 新增 NotifyTaskFinish 用於發送匯總
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckAndNotifyLeavesAlertTimeToCaller(t *testing.T) {
+	// memDomainRepo 沒有實作 UpdateAlertTime，Notifier 若自行寫入域名 collection 會直接 panic
+	repo := &memDomainRepo{settings: domain.NotificationSettings{
+		WebhookEnabled: true,
+		WebhookURL:     "http://hooks.invalid/expiry",
+		NotifyOnExpiry: true,
+	}}
+	notifier := &NotifierService{Repo: repo, tgQueue: make(chan telegramJob, 10), webhookQueue: make(chan webhookJob, 10)}
+
+	// 檔案憑證的 ID 不屬於 domains collection
+	fileCert := domain.SSLCertificate{
+		ID:            primitive.NewObjectID(),
+		DomainName:    "/etc/ssl/app.pem",
+		Status:        domain.StatusActive,
+		NotAfter:      time.Now().Add(5 * 24 * time.Hour),
+		DaysRemaining: 5,
+		IsMatch:       true,
+	}
+	if !notifier.CheckAndNotify(context.Background(), fileCert) {
+		t.Fatal("expiring certificate did not alert")
+	}
+	if msgs := drainWebhooks(notifier, 1); len(msgs) != 1 || !strings.Contains(msgs[0], "剩餘 5 天") {
+		t.Errorf("alerts = %q", msgs)
+	}
+
+	// 24 小時內已告警過的不重複發送
+	fileCert.LastAlertTime = time.Now().Add(-time.Hour)
+	if notifier.CheckAndNotify(context.Background(), fileCert) {
+		t.Error("alerted again within 24 hours")
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Cloudflare Origin CA 允許的效期 (天)，預設 15 年
+var originCAValidityDays = []int{7, 30, 90, 365, 730, 1095, 5475}
+
+const defaultOriginCAValidityDays = 5475
+
+// OriginCAService 透過 Cloudflare Origin CA 為 Proxy 後方的源站簽發憑證
+type OriginCAService struct {
+	CFService  *CloudflareService
+	DomainRepo repository.DomainRepository
+	Store      *CertStoreService
+	Notifier   *NotifierService
+}
+
+func NewOriginCAService(cf *CloudflareService, domainRepo repository.DomainRepository, store *CertStoreService, notifier *NotifierService) *OriginCAService {
+	return &OriginCAService{
+		CFService:  cf,
+		DomainRepo: domainRepo,
+		Store:      store,
+		Notifier:   notifier,
+	}
+}
+
+// Issue 為域名簽發 Origin CA 憑證並存入憑證庫
+// hostnames 為空時使用域名本身；validityDays 為 0 時使用 15 年
+func (s *OriginCAService) Issue(ctx context.Context, cert domain.SSLCertificate, hostnames []string, validityDays int) (*domain.ManagedCertificate, error) {
+	if cert.Provider != domain.ProviderCloudflare || cert.ZoneID == "" {
+		return nil, errors.New("只有 Cloudflare 管理的域名可以使用 Origin CA")
+	}
+	if len(hostnames) == 0 {
+		hostnames = []string{cert.DomainName}
+	}
+	if validityDays == 0 {
+		validityDays = defaultOriginCAValidityDays
+	}
+	if !slices.Contains(originCAValidityDays, validityDays) {
+		return nil, fmt.Errorf("效期只能是 %v 天其中之一", originCAValidityDays)
+	}
+
+	// 1. 本地產生私鑰與 CSR (私鑰不經過 Cloudflare)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: hostnames[0]},
+		DNSNames: hostnames,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 向 Cloudflare 申請 (Token 需具備 SSL and Certificates: Edit 權限)
+	api, err := s.CFService.clientFor(ctx, cert.CFAccountID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.CreateOriginCACertificate(ctx, cloudflare.CreateOriginCertificateParams{
+		Hostnames:       hostnames,
+		RequestType:     "origin-ecc",
+		RequestValidity: validityDays,
+		CSR:             string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Origin CA 簽發失敗: %w", err)
+	}
+
+	// 3. 保存並記錄在域名上 (與邊緣憑證分開)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	mc, err := s.Store.SaveCloudflareOrigin(ctx, cert, res.ID, []byte(res.Certificate), keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DomainRepo.UpdateOriginCert(ctx, cert.ID, mc.ID, mc.NotAfter); err != nil {
+		logrus.Errorf("❌ [OriginCA] 更新 %s 的 Origin 憑證資訊失敗: %v", cert.DomainName, err)
+	}
+
+	logrus.Infof("🟠 [OriginCA] 已簽發 %v (到期: %s)", hostnames, mc.NotAfter.Format("2006-01-02"))
+	s.Notifier.NotifyOperation(ctx, EventUpdate, cert.DomainName,
+		fmt.Sprintf("🟠 已簽發 Cloudflare Origin CA 憑證\n🌐 主機: %v\n📅 到期: %s", hostnames, mc.NotAfter.Format("2006-01-02")))
+	return mc, nil
+}
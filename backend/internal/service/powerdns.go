@@ -0,0 +1,204 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PowerDNSService 以 PowerDNS Authoritative HTTP API 作為同步來源 (實作 DNSProviderSource)
+type PowerDNSService struct {
+	Config conf.PowerDNSConfig
+	client *http.Client
+}
+
+func NewPowerDNSService(cfg conf.PowerDNSConfig) *PowerDNSService {
+	if cfg.ServerID == "" {
+		cfg.ServerID = "localhost"
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &PowerDNSService{Config: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Providers 有設定 API URL 才啟用 (實作 DNSProviderSource)
+func (s *PowerDNSService) Providers(ctx context.Context) ([]DNSProvider, error) {
+	if s.Config.APIURL == "" {
+		return nil, nil
+	}
+	return []DNSProvider{&powerDNSProvider{svc: s}}, nil
+}
+
+// RecordSyncResult PowerDNS 設定寫在設定檔，只記錄日誌 (實作 DNSProviderSource)
+func (s *PowerDNSService) RecordSyncResult(ctx context.Context, p DNSProvider, count int, err error) {
+	if err != nil {
+		logrus.Warnf("⚠️ [PowerDNS] 同步失敗: %v", err)
+		return
+	}
+	logrus.Infof("✅ [PowerDNS] 同步完成，共 %d 筆域名", count)
+}
+
+// =============================================================================
+// DNSProvider 實作
+// =============================================================================
+
+type powerDNSProvider struct {
+	svc *PowerDNSService
+}
+
+func (p *powerDNSProvider) Name() string {
+	return domain.ProviderPowerDNS
+}
+
+func (p *powerDNSProvider) Label() string {
+	return domain.ProviderPowerDNS
+}
+
+func (p *powerDNSProvider) ListZones(ctx context.Context) ([]DNSZone, error) {
+	var res []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := p.svc.get(ctx, "/zones", &res); err != nil {
+		return nil, err
+	}
+
+	zones := make([]DNSZone, 0, len(res))
+	for _, z := range res {
+		zones = append(zones, DNSZone{ID: z.ID, Name: strings.TrimSuffix(z.Name, ".")})
+	}
+	return zones, nil
+}
+
+func (p *powerDNSProvider) ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error) {
+	var res struct {
+		RRSets []powerDNSRRSet `json:"rrsets"`
+	}
+	if err := p.svc.get(ctx, "/zones/"+url.PathEscape(zone.ID), &res); err != nil {
+		return nil, err
+	}
+
+	var records []DNSRecord
+	for _, rrset := range res.RRSets {
+		if record, ok := rrset.toDNSRecord(); ok {
+			records = append(records, record)
+		}
+	}
+	logrus.Infof("   ✅ [PowerDNS] Zone %s 抓取完成，共 %d 筆紀錄", zone.Name, len(records))
+	return records, nil
+}
+
+// GetRecord recordID 格式為 "name|type" (PowerDNS 以 RRSet 為單位，沒有紀錄 ID)
+func (p *powerDNSProvider) GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	records, err := p.ListRecords(ctx, DNSZone{ID: zoneID, Name: zoneID})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	for _, record := range records {
+		if record.ID == recordID {
+			return record, nil
+		}
+	}
+	return DNSRecord{}, fmt.Errorf("%w: PowerDNS 紀錄 %s", ErrRecordNotFound, recordID)
+}
+
+func (p *powerDNSProvider) Owns(d domain.SSLCertificate) bool {
+	return d.Provider == domain.ProviderPowerDNS
+}
+
+func (p *powerDNSProvider) Annotate(d *domain.SSLCertificate) {}
+
+// =============================================================================
+// PowerDNS API 回應格式
+// =============================================================================
+
+type powerDNSRRSet struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Records []struct {
+		Content  string `json:"content"`
+		Disabled bool   `json:"disabled"`
+	} `json:"records"`
+	Comments []struct {
+		Content string `json:"content"`
+	} `json:"comments"`
+}
+
+// toDNSRecord 將一個 RRSet 合併成一筆紀錄，全部停用的 RRSet 視為不存在
+func (rrset powerDNSRRSet) toDNSRecord() (DNSRecord, bool) {
+	var values []string
+	for _, r := range rrset.Records {
+		if !r.Disabled {
+			values = append(values, strings.TrimSuffix(r.Content, "."))
+		}
+	}
+	if len(values) == 0 {
+		return DNSRecord{}, false
+	}
+	sort.Strings(values)
+
+	name := strings.TrimSuffix(rrset.Name, ".")
+	record := DNSRecord{
+		ID:      name + "|" + rrset.Type,
+		Name:    name,
+		Type:    rrset.Type,
+		Content: strings.Join(values, ","),
+	}
+	if len(rrset.Comments) > 0 {
+		record.Comment = rrset.Comments[0].Content
+	}
+	return record, true
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// get 呼叫 /api/v1/servers/{server_id}{path} 並解析 JSON
+func (s *PowerDNSService) get(ctx context.Context, path string, out interface{}) error {
+	endpoint := s.Config.APIURL + "/api/v1/servers/" + url.PathEscape(s.Config.ServerID) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", s.Config.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("PowerDNS 請求失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("PowerDNS 錯誤 (HTTP %d): %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("PowerDNS 錯誤: HTTP %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("PowerDNS 回應格式錯誤: %w", err)
+	}
+	return nil
+}
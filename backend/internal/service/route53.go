@@ -0,0 +1,380 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"cert-manager/internal/domain"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	route53DefaultEndpoint = "https://route53.amazonaws.com"
+	route53DefaultRegion   = "us-east-1" // Route 53 是全域服務，簽章固定使用 us-east-1
+	route53APIVersion      = "2013-04-01"
+	route53RecordPageSize  = 300
+	route53MaxRetries      = 3
+)
+
+// Route53Service 以 Route 53 REST API 作為同步來源 (實作 DNSProviderSource)
+// 只使用標準函式庫 (SigV4 簽章)，Endpoint 可指向本地 Mock (e.g. moto / LocalStack)
+type Route53Service struct {
+	Config conf.Route53Config
+	client *http.Client
+}
+
+func NewRoute53Service(cfg conf.Route53Config) *Route53Service {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = route53DefaultEndpoint
+	}
+	if cfg.Region == "" {
+		cfg.Region = route53DefaultRegion
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &Route53Service{Config: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Providers 有設定 Access Key 才啟用 (實作 DNSProviderSource)
+func (s *Route53Service) Providers(ctx context.Context) ([]DNSProvider, error) {
+	if s.Config.AccessKeyID == "" || s.Config.SecretAccessKey == "" {
+		return nil, nil
+	}
+	return []DNSProvider{&route53DNSProvider{svc: s}}, nil
+}
+
+// RecordSyncResult Route 53 帳號沒有存在資料庫，只記錄日誌 (實作 DNSProviderSource)
+func (s *Route53Service) RecordSyncResult(ctx context.Context, p DNSProvider, count int, err error) {
+	if err != nil {
+		logrus.Warnf("⚠️ [Route53] 同步失敗: %v", err)
+		return
+	}
+	logrus.Infof("✅ [Route53] 同步完成，共 %d 筆域名", count)
+}
+
+// =============================================================================
+// DNSProvider 實作
+// =============================================================================
+
+type route53DNSProvider struct {
+	svc *Route53Service
+}
+
+func (p *route53DNSProvider) Name() string {
+	return domain.ProviderRoute53
+}
+
+func (p *route53DNSProvider) Label() string {
+	return domain.ProviderRoute53
+}
+
+func (p *route53DNSProvider) ListZones(ctx context.Context) ([]DNSZone, error) {
+	var zones []DNSZone
+	marker := ""
+	for {
+		query := url.Values{"maxitems": {"100"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		var res route53ListHostedZonesResponse
+		if err := p.svc.get(ctx, "/hostedzone", query, &res); err != nil {
+			return nil, err
+		}
+		for _, z := range res.HostedZones {
+			zone := DNSZone{ID: strings.TrimPrefix(z.ID, "/hostedzone/"), Name: route53Name(z.Name)}
+			if z.Config.PrivateZone {
+				zone.Status = "private"
+			}
+			zones = append(zones, zone)
+		}
+
+		if !res.IsTruncated || res.NextMarker == "" {
+			break
+		}
+		marker = res.NextMarker
+	}
+	return zones, nil
+}
+
+func (p *route53DNSProvider) ListRecords(ctx context.Context, zone DNSZone) ([]DNSRecord, error) {
+	var records []DNSRecord
+	query := url.Values{"maxitems": {fmt.Sprint(route53RecordPageSize)}}
+	for {
+		var res route53ListRecordSetsResponse
+		if err := p.svc.get(ctx, "/hostedzone/"+zone.ID+"/rrset", query, &res); err != nil {
+			return nil, err
+		}
+		for _, rs := range res.RecordSets {
+			records = append(records, rs.toDNSRecord())
+		}
+
+		if !res.IsTruncated {
+			break
+		}
+		// 下一頁由 name / type / identifier 三者決定
+		query = url.Values{"maxitems": {fmt.Sprint(route53RecordPageSize)}, "name": {res.NextRecordName}}
+		if res.NextRecordType != "" {
+			query.Set("type", res.NextRecordType)
+		}
+		if res.NextRecordIdentifier != "" {
+			query.Set("identifier", res.NextRecordIdentifier)
+		}
+	}
+	logrus.Infof("   ✅ [Route53] Zone %s 抓取完成，共 %d 筆紀錄", zone.Name, len(records))
+	return records, nil
+}
+
+// GetRecord recordID 格式為 "name|type|setIdentifier" (Route 53 沒有紀錄 ID，type 為原始紀錄類型)
+func (p *route53DNSProvider) GetRecord(ctx context.Context, zoneID, recordID string) (DNSRecord, error) {
+	parts := strings.SplitN(recordID, "|", 3)
+	if len(parts) < 2 {
+		return DNSRecord{}, fmt.Errorf("無效的 Route 53 紀錄 ID: %s", recordID)
+	}
+	query := url.Values{"name": {parts[0]}, "type": {parts[1]}, "maxitems": {"100"}}
+	var res route53ListRecordSetsResponse
+	if err := p.svc.get(ctx, "/hostedzone/"+zoneID+"/rrset", query, &res); err != nil {
+		return DNSRecord{}, err
+	}
+	for _, rs := range res.RecordSets {
+		if record := rs.toDNSRecord(); record.ID == recordID {
+			return record, nil
+		}
+	}
+	return DNSRecord{}, fmt.Errorf("%w: Route 53 紀錄 %s", ErrRecordNotFound, recordID)
+}
+
+func (p *route53DNSProvider) Owns(d domain.SSLCertificate) bool {
+	return d.Provider == domain.ProviderRoute53
+}
+
+func (p *route53DNSProvider) Annotate(d *domain.SSLCertificate) {}
+
+// =============================================================================
+// Route 53 API 回應格式 (XML)
+// =============================================================================
+
+type route53ListHostedZonesResponse struct {
+	HostedZones []struct {
+		ID     string `xml:"Id"`
+		Name   string `xml:"Name"`
+		Config struct {
+			PrivateZone bool `xml:"PrivateZone"`
+		} `xml:"Config"`
+	} `xml:"HostedZones>HostedZone"`
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+}
+
+type route53ListRecordSetsResponse struct {
+	RecordSets           []route53RecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool               `xml:"IsTruncated"`
+	NextRecordName       string             `xml:"NextRecordName"`
+	NextRecordType       string             `xml:"NextRecordType"`
+	NextRecordIdentifier string             `xml:"NextRecordIdentifier"`
+}
+
+type route53RecordSet struct {
+	Name          string   `xml:"Name"`
+	Type          string   `xml:"Type"`
+	SetIdentifier string   `xml:"SetIdentifier"`
+	Values        []string `xml:"ResourceRecords>ResourceRecord>Value"`
+	AliasTarget   *struct {
+		HostedZoneID string `xml:"HostedZoneId"`
+		DNSName      string `xml:"DNSName"`
+	} `xml:"AliasTarget"`
+}
+
+type route53ErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// toDNSRecord 別名紀錄 (ELB / CloudFront / S3 ...) 的目標寫入 Content
+// A 別名記為 ALIAS；AAAA 別名保留 AAAA，讓同步流程在已有 A 別名時略過它
+func (rs route53RecordSet) toDNSRecord() DNSRecord {
+	name := route53Name(rs.Name)
+	record := DNSRecord{
+		ID:      name + "|" + rs.Type + "|" + rs.SetIdentifier,
+		Name:    name,
+		Type:    rs.Type,
+		Content: strings.Join(rs.Values, ","),
+	}
+	if rs.AliasTarget != nil {
+		record.Content = route53Name(rs.AliasTarget.DNSName)
+		record.Comment = "Alias (" + rs.Type + ")"
+		if rs.Type != "AAAA" {
+			record.Type = "ALIAS"
+		}
+	}
+	if rs.Type == "CNAME" {
+		record.Content = route53Name(record.Content)
+	}
+	return record
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// get 發送簽章後的 GET 請求並解析 XML，遇到限流時退避重試
+func (s *Route53Service) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := s.Config.Endpoint + "/" + route53APIVersion + path
+	if len(query) > 0 {
+		endpoint += "?" + canonicalQuery(query)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		s.sign(req, nil, time.Now().UTC())
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("Route 53 請求失敗: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			if err := xml.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("Route 53 回應格式錯誤: %w", err)
+			}
+			return nil
+		}
+
+		var apiErr route53ErrorResponse
+		_ = xml.Unmarshal(body, &apiErr)
+		throttled := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable ||
+			apiErr.Code == "Throttling" || apiErr.Code == "PriorRequestNotComplete"
+		if throttled && attempt < route53MaxRetries {
+			wait := time.Duration(1<<attempt) * time.Second
+			logrus.Warnf("⏳ [Route53] 觸發限流，%s 後重試", wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if apiErr.Code != "" {
+			return fmt.Errorf("Route 53 錯誤 (%d %s): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("Route 53 錯誤: HTTP %d", resp.StatusCode)
+	}
+}
+
+// sign 以 AWS Signature Version 4 簽署請求
+func (s *Route53Service) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	const service = "route53"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.Config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Config.SessionToken)
+	}
+
+	// 1. Canonical Request
+	headers := map[string]string{"host": req.URL.Host, "x-amz-date": amzDate}
+	if s.Config.SessionToken != "" {
+		headers["x-amz-security-token"] = s.Config.SessionToken
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	// 2. String to Sign
+	scope := date + "/" + s.Config.Region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	// 3. Signature
+	key := hmacSHA256([]byte("AWS4"+s.Config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Config.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.Config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 依 SigV4 規則排序並編碼 Query (空白為 %20)
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// route53Name 去掉結尾的點並還原跳脫字元 (Route 53 以 \052 表示 *)
+func route53Name(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	return strings.ReplaceAll(name, `\052`, "*")
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"cert-manager/internal/conf"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRoute53 模擬 Route 53 REST API，每個請求都以獨立實作的 SigV4 驗證
+type fakeRoute53 struct {
+	t   *testing.T
+	cfg conf.Route53Config
+
+	// 預期簽章不符時設為 true，不把驗證失敗記為測試錯誤
+	expectBadSignature bool
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakeRoute53) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.URL.Path+"?"+r.URL.RawQuery)
+	f.mu.Unlock()
+
+	if err := verifySigV4(r, f.cfg); err != nil {
+		if !f.expectBadSignature {
+			f.t.Errorf("%s %s: %v", r.Method, r.URL, err)
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<ErrorResponse><Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error></ErrorResponse>`)
+		return
+	}
+
+	q := r.URL.Query()
+	w.Header().Set("Content-Type", "text/xml")
+	switch r.URL.Path {
+	case "/2013-04-01/hostedzone":
+		if q.Get("marker") == "" {
+			fmt.Fprint(w, `<ListHostedZonesResponse><HostedZones>
+				<HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name><Config><PrivateZone>false</PrivateZone></Config></HostedZone>
+			</HostedZones><IsTruncated>true</IsTruncated><NextMarker>Z2</NextMarker><MaxItems>100</MaxItems></ListHostedZonesResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<ListHostedZonesResponse><HostedZones>
+			<HostedZone><Id>/hostedzone/Z2</Id><Name>internal.example.</Name><Config><PrivateZone>true</PrivateZone></Config></HostedZone>
+		</HostedZones><IsTruncated>false</IsTruncated><MaxItems>100</MaxItems></ListHostedZonesResponse>`)
+
+	case "/2013-04-01/hostedzone/Z1/rrset":
+		if q.Get("name") == "" {
+			fmt.Fprint(w, `<ListResourceRecordSetsResponse><ResourceRecordSets>
+				<ResourceRecordSet><Name>example.com.</Name><Type>A</Type><TTL>300</TTL>
+					<ResourceRecords><ResourceRecord><Value>192.0.2.1</Value></ResourceRecord><ResourceRecord><Value>192.0.2.2</Value></ResourceRecord></ResourceRecords>
+				</ResourceRecordSet>
+				<ResourceRecordSet><Name>\052.example.com.</Name><Type>CNAME</Type><TTL>300</TTL>
+					<ResourceRecords><ResourceRecord><Value>lb.example.net.</Value></ResourceRecord></ResourceRecords>
+				</ResourceRecordSet>
+			</ResourceRecordSets><IsTruncated>true</IsTruncated>
+			<NextRecordName>www.example.com.</NextRecordName><NextRecordType>A</NextRecordType><NextRecordIdentifier>eu west</NextRecordIdentifier>
+			<MaxItems>300</MaxItems></ListResourceRecordSetsResponse>`)
+			return
+		}
+		if strings.TrimSuffix(q.Get("name"), ".") != "www.example.com" || q.Get("type") != "A" {
+			f.t.Errorf("unexpected rrset query %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `<ListResourceRecordSetsResponse><ResourceRecordSets>
+			<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type><SetIdentifier>eu west</SetIdentifier>
+				<AliasTarget><HostedZoneId>Z2FDTNDATAQYW2</HostedZoneId><DNSName>d111111abcdef8.cloudfront.net.</DNSName><EvaluateTargetHealth>false</EvaluateTargetHealth></AliasTarget>
+			</ResourceRecordSet>
+			<ResourceRecordSet><Name>www.example.com.</Name><Type>AAAA</Type><SetIdentifier>eu west</SetIdentifier>
+				<AliasTarget><HostedZoneId>Z2FDTNDATAQYW2</HostedZoneId><DNSName>d111111abcdef8.cloudfront.net.</DNSName><EvaluateTargetHealth>false</EvaluateTargetHealth></AliasTarget>
+			</ResourceRecordSet>
+		</ResourceRecordSets><IsTruncated>false</IsTruncated><MaxItems>300</MaxItems></ListResourceRecordSetsResponse>`)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeRoute53(t *testing.T) (*Route53Service, *fakeRoute53) {
+	cfg := conf.Route53Config{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		SessionToken:    "session/token+example",
+	}
+	fake := &fakeRoute53{t: t}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg.Endpoint = srv.URL + "/"
+	svc := NewRoute53Service(cfg)
+	fake.cfg = svc.Config
+	return svc, fake
+}
+
+func TestRoute53ListZonesPaginates(t *testing.T) {
+	svc, fake := newFakeRoute53(t)
+	providers, err := svc.Providers(context.Background())
+	if err != nil || len(providers) != 1 {
+		t.Fatalf("Providers = %v, %v", providers, err)
+	}
+
+	zones, err := providers[0].ListZones(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DNSZone{{ID: "Z1", Name: "example.com"}, {ID: "Z2", Name: "internal.example", Status: "private"}}
+	if fmt.Sprint(zones) != fmt.Sprint(want) {
+		t.Errorf("zones = %+v, want %+v", zones, want)
+	}
+
+	wantRequests := []string{
+		"/2013-04-01/hostedzone?maxitems=100",
+		"/2013-04-01/hostedzone?marker=Z2&maxitems=100",
+	}
+	if fmt.Sprint(fake.requests) != fmt.Sprint(wantRequests) {
+		t.Errorf("requests = %q, want %q", fake.requests, wantRequests)
+	}
+}
+
+func TestRoute53ListRecordsPaginatesAndMapsAliases(t *testing.T) {
+	svc, fake := newFakeRoute53(t)
+	provider := &route53DNSProvider{svc: svc}
+
+	records, err := provider.ListRecords(context.Background(), DNSZone{ID: "Z1", Name: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DNSRecord{
+		{ID: "example.com|A|", Name: "example.com", Type: "A", Content: "192.0.2.1,192.0.2.2"},
+		{ID: "*.example.com|CNAME|", Name: "*.example.com", Type: "CNAME", Content: "lb.example.net"},
+		{ID: "www.example.com|A|eu west", Name: "www.example.com", Type: "ALIAS", Content: "d111111abcdef8.cloudfront.net", Comment: "Alias (A)"},
+		{ID: "www.example.com|AAAA|eu west", Name: "www.example.com", Type: "AAAA", Content: "d111111abcdef8.cloudfront.net", Comment: "Alias (AAAA)"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, records[i], want[i])
+		}
+	}
+
+	// 第二頁必須帶上 NextRecordName / Type / Identifier，且空白編碼為 %20
+	if len(fake.requests) != 2 || fake.requests[1] != "/2013-04-01/hostedzone/Z1/rrset?identifier=eu%20west&maxitems=300&name=www.example.com.&type=A" {
+		t.Errorf("requests = %q", fake.requests)
+	}
+
+	// GetRecord 以 name|type|identifier 找回別名紀錄
+	got, err := provider.GetRecord(context.Background(), "Z1", "www.example.com|A|eu west")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want[2] {
+		t.Errorf("GetRecord = %+v, want %+v", got, want[2])
+	}
+}
+
+func TestRoute53SignatureRejectedSurfacesError(t *testing.T) {
+	svc, fake := newFakeRoute53(t)
+	fake.cfg.SecretAccessKey = "another-secret"
+	fake.expectBadSignature = true
+
+	_, err := (&route53DNSProvider{svc: svc}).ListZones(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("err = %v, want SignatureDoesNotMatch", err)
+	}
+}
+
+// verifySigV4 依 AWS 文件從收到的請求重建 Canonical Request 並比對簽章
+func verifySigV4(r *http.Request, cfg conf.Route53Config) error {
+	auth := r.Header.Get("Authorization")
+	const algo = "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(auth, algo) {
+		return fmt.Errorf("authorization %q: wrong algorithm", auth)
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, algo), ", ") {
+		k, v, _ := strings.Cut(part, "=")
+		fields[k] = v
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		return fmt.Errorf("X-Amz-Date %q: %v", amzDate, err)
+	}
+	if d := time.Since(signedAt); d < -time.Minute || d > 5*time.Minute {
+		return fmt.Errorf("X-Amz-Date %s is not current", amzDate)
+	}
+	if r.Header.Get("X-Amz-Security-Token") != cfg.SessionToken {
+		return fmt.Errorf("missing session token")
+	}
+
+	scope := amzDate[:8] + "/us-east-1/route53/aws4_request"
+	if fields["Credential"] != cfg.AccessKeyID+"/"+scope {
+		return fmt.Errorf("credential %q, want scope %s", fields["Credential"], scope)
+	}
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	if fields["SignedHeaders"] != "host;x-amz-date;x-amz-security-token" {
+		return fmt.Errorf("signed headers %q", fields["SignedHeaders"])
+	}
+
+	// Query 以 RFC 3986 重新編碼並依 key、value 排序
+	values, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return err
+	}
+	var pairs []string
+	for k, vs := range values {
+		for _, v := range vs {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	query := strings.Join(pairs, "&")
+	if r.URL.RawQuery != query {
+		return fmt.Errorf("query on the wire %q is not canonical %q", r.URL.RawQuery, query)
+	}
+
+	var headers strings.Builder
+	for _, h := range signedHeaders {
+		v := r.Header.Get(h)
+		if h == "host" {
+			v = r.Host
+		}
+		headers.WriteString(h + ":" + v + "\n")
+	}
+	emptyHash := sha256.Sum256(nil)
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), query, headers.String(), fields["SignedHeaders"], hex.EncodeToString(emptyHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + cfg.SecretAccessKey)
+	for _, part := range []string{amzDate[:8], "us-east-1", "route53", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if want := hex.EncodeToString(key); fields["Signature"] != want {
+		return fmt.Errorf("signature %s, want %s", fields["Signature"], want)
+	}
+	return nil
+}
+
+func uriEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
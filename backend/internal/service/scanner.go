@@ -4,12 +4,15 @@ import (
 	"cert-manager/internal/domain"
 	"cert-manager/internal/repository"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,6 +24,9 @@ import (
 	"golang.org/x/net/publicsuffix"
 )
 
+// maxEndpointChecks 單一域名最多逐一檢查的 IP 數量
+const maxEndpointChecks = 16
+
 // ScannerService 負責域名的掃描、監控與通知
 type ScannerService struct {
 	Repo       repository.DomainRepository
@@ -150,6 +156,9 @@ func (s *ScannerService) ScanOne(ctx context.Context, oldCert domain.SSLCertific
 	// 2. 繼承舊資料 (Cloudflare 設定等不由此處更新)
 	s.inheritConfig(&newCert, oldCert)
 
+	// 2-1. Proxy 後方的源站憑證 (邊緣只看得到 Cloudflare 的憑證)
+	s.checkOrigin(ctx, &newCert)
+
 	// 3. WHOIS 查詢 (智慧緩存策略)
 	s.syncWhois(ctx, &newCert, oldCert)
 
@@ -171,8 +180,15 @@ func (s *ScannerService) ScanOne(ctx context.Context, oldCert domain.SSLCertific
 
 	isFreshError := newCert.Status == domain.StatusConnectionError && oldCert.Status != domain.StatusConnectionError
 
-	if checkExpiry || isFreshError {
-		s.Notifier.CheckAndNotify(ctx, newCert)
+	if (checkExpiry || isFreshError) && s.Notifier.CheckAndNotify(ctx, newCert) {
+		if err := s.Repo.UpdateAlertTime(ctx, newCert.ID); err != nil {
+			logrus.Errorf("❌ [Scan] 更新告警時間失敗 %s: %v", newCert.DomainName, err)
+		}
+	}
+	if checkExpiry && s.Notifier.CheckAndNotifyOrigin(ctx, newCert) {
+		if err := s.Repo.UpdateOriginAlertTime(ctx, newCert.ID); err != nil {
+			logrus.Errorf("❌ [Scan] 更新源站告警時間失敗 %s: %v", newCert.DomainName, err)
+		}
 	}
 
 	return newCert, changes, nil
@@ -210,15 +226,19 @@ func (s *ScannerService) processTask(ctx context.Context, cert domain.SSLCertifi
 // inheritConfig 繼承不需要重新掃描的配置
 func (s *ScannerService) inheritConfig(newCert *domain.SSLCertificate, oldCert domain.SSLCertificate) {
 	newCert.ID = oldCert.ID
-	newCert.CFZoneID = oldCert.CFZoneID
+	newCert.Provider = oldCert.Provider
+	newCert.ZoneID = oldCert.ZoneID
 	newCert.ZoneName = oldCert.ZoneName
-	newCert.CFRecordID = oldCert.CFRecordID
+	newCert.RecordID = oldCert.RecordID
+	newCert.RecordModifiedOn = oldCert.RecordModifiedOn
+	newCert.CFAccountID = oldCert.CFAccountID
 	newCert.IsIgnored = oldCert.IsIgnored
 	newCert.AutoRenew = oldCert.AutoRenew
 	newCert.IsProxied = oldCert.IsProxied
 	newCert.CFOriginValue = oldCert.CFOriginValue
 	newCert.CFRecordType = oldCert.CFRecordType
 	newCert.CFComment = oldCert.CFComment
+	newCert.OriginLastAlertTime = oldCert.OriginLastAlertTime
 	// 默認繼承 WHOIS，稍後由 syncWhois 決定是否覆蓋
 	newCert.DomainExpiryDate = oldCert.DomainExpiryDate
 	newCert.DomainDaysLeft = oldCert.DomainDaysLeft
@@ -320,6 +340,9 @@ func (s *ScannerService) PerformNetworkScan(parentCtx context.Context, domainNam
 		return s.checkSSLHandshake(ctx, &result)
 	})
 
+	// 2-1. 多筆 A / AAAA 時逐一檢查每個節點 (Load Balancer 後方可能有節點沒更新憑證)
+	s.checkEndpoints(ctx, &result)
+
 	if err != nil {
 		// [關鍵修正] 如果 DNS 解析成功了(上面沒 return)，但這裡連線失敗
 		// 不應該標記為 Unresolvable，而是 Connection Failed
@@ -412,6 +435,116 @@ func (s *ScannerService) checkSSLHandshake(ctx context.Context, result *domain.S
 	return nil
 }
 
+// checkOrigin 直接連線 CFOriginValue (CNAME 先解析，依序嘗試到握手成功為止)，SNI 使用 DomainName
+// 源站通常只允許 Cloudflare 連線，連不上只記錄錯誤，不視為異常
+func (s *ScannerService) checkOrigin(parentCtx context.Context, cert *domain.SSLCertificate) {
+	cert.OriginCheck = nil
+	target := strings.TrimSuffix(strings.TrimSpace(cert.CFOriginValue), ".")
+	if !cert.IsProxied || target == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, 30*time.Second)
+	defer cancel()
+
+	port := cert.Port
+	if port == 0 {
+		port = 443
+	}
+
+	ips := []string{target}
+	if net.ParseIP(target) == nil {
+		resolved, err := net.DefaultResolver.LookupHost(ctx, target)
+		if err != nil {
+			cert.OriginCheck = &domain.EndpointResult{Error: "源站解析失敗: " + err.Error()}
+			return
+		}
+		ips = resolved
+	}
+
+	var result domain.EndpointResult
+	for _, ip := range ips {
+		if result = s.handshakeEndpoint(ctx, ip, cert.DomainName, port); result.Error == "" {
+			break
+		}
+	}
+	cert.OriginCheck = &result
+	if result.Error == "" && (result.DaysRemaining < 0 || !result.IsMatch) {
+		logrus.Warnf("⚠️ [Scan] %s 源站憑證異常 (%s): 剩餘 %d 天, 名稱相符=%v", cert.DomainName, result.IP, result.DaysRemaining, result.IsMatch)
+	}
+}
+
+// checkEndpoints 以網域名稱作為 SNI 對每個解析到的 IP 握手 (併發)，並比對各節點的憑證
+// 單一 IP 時與主要握手結果相同，不重複檢查
+func (s *ScannerService) checkEndpoints(ctx context.Context, result *domain.SSLCertificate) {
+	ips := result.ResolvedIPs
+	if len(ips) < 2 || ctx.Err() != nil {
+		return
+	}
+	if len(ips) > maxEndpointChecks {
+		ips = ips[:maxEndpointChecks]
+	}
+
+	endpoints := make([]domain.EndpointResult, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Add(1)
+		go func(i int, ip string) {
+			defer wg.Done()
+			endpoints[i] = s.handshakeEndpoint(ctx, ip, result.DomainName, result.Port)
+		}(i, ip)
+	}
+	wg.Wait()
+
+	result.Endpoints = endpoints
+	result.EndpointIssue = endpointIssue(endpoints)
+	if result.EndpointIssue != "" {
+		logrus.Warnf("⚠️ [Scan] %s 節點憑證異常: %s", result.DomainName, result.EndpointIssue)
+	}
+}
+
+// handshakeEndpoint 對單一 IP 握手 (不重試，避免拖長整體掃描時間)
+func (s *ScannerService) handshakeEndpoint(ctx context.Context, ip, serverName string, port int) domain.EndpointResult {
+	ep := domain.EndpointResult{IP: ip}
+	start := time.Now()
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: -1}
+	rawConn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		ep.Error = s.parseDialError(err)
+		return ep
+	}
+	defer rawConn.Close()
+	_ = rawConn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	conn := tls.Client(rawConn, &tls.Config{InsecureSkipVerify: true, ServerName: serverName})
+	if err := conn.HandshakeContext(ctx); err != nil {
+		ep.Error = s.parseDialError(err)
+		return ep
+	}
+	ep.Latency = time.Since(start).Milliseconds()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		ep.Error = "伺服器未提供憑證"
+		return ep
+	}
+	cert := state.PeerCertificates[0]
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	ep.Fingerprint = hex.EncodeToString(fingerprint[:])
+	ep.Issuer = certIssuerName(cert)
+	ep.NotAfter = cert.NotAfter
+	ep.DaysRemaining = int(time.Until(cert.NotAfter).Hours() / 24)
+	ep.IsMatch = cert.VerifyHostname(serverName) == nil
+	if v, ok := tlsVersions[state.Version]; ok {
+		ep.TLSVersion = v
+	} else {
+		ep.TLSVersion = "Unknown"
+	}
+	return ep
+}
+
 // parseCertInfo 從連線中提取憑證資訊
 func (s *ScannerService) parseCertInfo(conn *tls.Conn, result *domain.SSLCertificate) {
 	state := conn.ConnectionState()
@@ -732,6 +865,22 @@ func (s *ScannerService) generateDiff(old, new domain.SSLCertificate) []string {
 		}
 	}
 
+	// 6. [源站憑證續簽檢測]
+	if old.OriginCheck != nil && new.OriginCheck != nil && !old.OriginCheck.NotAfter.IsZero() &&
+		new.OriginCheck.NotAfter.After(old.OriginCheck.NotAfter.Add(24*time.Hour)) {
+		changes = append(changes, fmt.Sprintf("♻️ <b>源站憑證已更新</b>: %s ➔ %s",
+			old.OriginCheck.NotAfter.Format("2006-01-02"), new.OriginCheck.NotAfter.Format("2006-01-02")))
+	}
+
+	// 7. [節點一致性檢測]
+	if old.EndpointIssue != new.EndpointIssue {
+		if new.EndpointIssue != "" {
+			changes = append(changes, fmt.Sprintf("⚠️ <b>節點憑證異常</b>: %s", new.EndpointIssue))
+		} else if new.Status != domain.StatusConnectionError {
+			changes = append(changes, "✅ <b>各節點憑證已一致</b>")
+		}
+	}
+
 	return changes
 }
 
@@ -792,6 +941,41 @@ func (s *ScannerService) parseDialError(err error) string {
 	return errMsg
 }
 
+// endpointIssue 比對握手成功的節點：憑證不同、過期或名稱不符時回傳說明
+// 連不上的節點 (e.g. 掃描主機沒有 IPv6) 只記錄在 EndpointResult.Error，不視為不一致
+func endpointIssue(endpoints []domain.EndpointResult) string {
+	var issues []string
+	var order []string
+	groups := make(map[string][]string)
+	notAfter := make(map[string]time.Time)
+
+	for _, ep := range endpoints {
+		if ep.Fingerprint == "" {
+			continue
+		}
+		if _, ok := groups[ep.Fingerprint]; !ok {
+			order = append(order, ep.Fingerprint)
+			notAfter[ep.Fingerprint] = ep.NotAfter
+		}
+		groups[ep.Fingerprint] = append(groups[ep.Fingerprint], ep.IP)
+
+		if ep.DaysRemaining < 0 {
+			issues = append(issues, fmt.Sprintf("%s 憑證已過期", ep.IP))
+		} else if !ep.IsMatch {
+			issues = append(issues, fmt.Sprintf("%s 憑證名稱不符", ep.IP))
+		}
+	}
+
+	if len(order) > 1 {
+		parts := make([]string, 0, len(order))
+		for _, fp := range order {
+			parts = append(parts, fmt.Sprintf("[%s] 到期 %s", strings.Join(groups[fp], ", "), notAfter[fp].Format("2006-01-02")))
+		}
+		issues = append([]string{"各節點憑證不一致: " + strings.Join(parts, " / ")}, issues...)
+	}
+	return strings.Join(issues, "; ")
+}
+
 func getRootDomain(domainName string) string {
 	root, err := publicsuffix.EffectiveTLDPlusOne(domainName)
 	if err != nil {
@@ -158,11 +158,25 @@ func (s *SchedulerService) PerformSync(ctx context.Context) (SyncStats, error) {
 
     // 3. 啟動 Cloudflare 抓取 (生產者)
     errChan := make(chan error, 1)
+    var zoneReport *ZoneSyncReport
     go func() {
         defer close(domainStream)
         // 注意：這裡呼叫的是修改後支援 Channel 的 CF.FetchDomains
-        if err := s.CF.FetchDomains(ctx, domainStream); err != nil {
+        providers, err := s.CF.Providers(ctx)
+        if err != nil {
             errChan <- err
+            return
+        }
+        zoneReport = &ZoneSyncReport{}
+        dnsSync := NewDNSSyncService(s.Repo, nil, nil, s.CF)
+        for _, p := range providers {
+            report, err := dnsSync.FetchDomains(ctx, p, domainStream)
+            if err != nil {
+                errChan <- err
+                return
+            }
+            zoneReport.Excluded = append(zoneReport.Excluded, report.Excluded...)
+            zoneReport.Failed = append(zoneReport.Failed, report.Failed...)
         }
     }()
 
@@ -178,7 +192,7 @@ func (s *SchedulerService) PerformSync(ctx context.Context) (SyncStats, error) {
 
     // 5. 處理刪除
     logrus.Info("🗑 [Sync] 開始檢查已刪除的域名...")
-    s.processDeletions(ctx, allCFDomains, dbDomains, &stats)
+    s.processDeletions(ctx, allCFDomains, dbDomains, zoneReport.Untouched(), &stats)
 
     stats.Duration = time.Since(start).String()
     
@@ -291,13 +305,14 @@ func (s *SchedulerService) PerformScan(ctx context.Context) {
 // }
 
 // processDeletions 刪除邏輯
-func (s *SchedulerService) processDeletions(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate, stats *SyncStats) {
+func (s *SchedulerService) processDeletions(ctx context.Context, cfDomains []domain.SSLCertificate, dbDomains []domain.SSLCertificate, untouchedZones map[string]bool, stats *SyncStats) {
     cfMap := make(map[string]bool)
     for _, d := range cfDomains {
         cfMap[d.DomainName] = true
     }
     for _, dbD := range dbDomains {
         if dbD.CFRecordType == "placeholder" { continue } // 簡單保護
+        if untouchedZones[dbD.ZoneName] { continue }      // 本次未同步的 Zone
         if !cfMap[dbD.DomainName] && !shouldSkipDomain(dbD.DomainName) {
             if err := s.Repo.Delete(ctx, dbD.ID); err == nil {
                 stats.Deleted++
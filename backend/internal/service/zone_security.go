@@ -0,0 +1,184 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 憑證包停留在 pending_validation 超過此時間視為卡住 (正常 DCV 通常數分鐘內完成)
+const certPackStuckAfter = 24 * time.Hour
+
+// ZoneSecurityService 同步時讀取 Zone 的 SSL/TLS 模式、最低 TLS 版本與 Edge 憑證包並保存，
+// 出現新的異常時發送 EventZoneSecurity
+type ZoneSecurityService struct {
+	Repo     repository.ZoneSecurityRepository
+	Domains  repository.DomainRepository
+	Notifier *NotifierService
+}
+
+func NewZoneSecurityService(repo repository.ZoneSecurityRepository, domains repository.DomainRepository, notifier *NotifierService) *ZoneSecurityService {
+	return &ZoneSecurityService{Repo: repo, Domains: domains, Notifier: notifier}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Refresh 更新單一 Zone 的設定 (Provider 不支援時略過)
+// 讀取失敗不影響 DNS 同步，只記錄在 LastError 並保留上次的資料
+func (s *ZoneSecurityService) Refresh(ctx context.Context, p DNSProvider, zone DNSZone) {
+	reader, ok := p.(ZoneSecurityReader)
+	if !ok {
+		return
+	}
+
+	prev, err := s.Repo.Get(ctx, zone.ID)
+	if err != nil {
+		logrus.Errorf("   ❌ [ZoneSecurity] 讀取 %s 既有資料失敗: %v", zone.Name, err)
+		return
+	}
+
+	current, err := reader.ZoneSecurity(ctx, zone)
+	if current == nil {
+		return
+	}
+	current.UpdatedAt = time.Now()
+	if err != nil {
+		logrus.Warnf("   ⚠️ [ZoneSecurity] %s 部分設定讀取失敗: %v", zone.Name, err)
+		current.LastError = err.Error()
+	}
+	if prev != nil {
+		if current.SSLMode == "" {
+			current.SSLMode = prev.SSLMode
+		}
+		if current.MinTLSVersion == "" {
+			current.MinTLSVersion = prev.MinTLSVersion
+		}
+		if current.CertificatePacks == nil {
+			current.CertificatePacks = prev.CertificatePacks
+		}
+	}
+	trackPendingSince(current, prev)
+
+	issues, details := s.evaluate(ctx, current)
+	current.Issues = issues
+
+	if err := s.Repo.Save(ctx, *current); err != nil {
+		logrus.Errorf("   ❌ [ZoneSecurity] 保存 %s 失敗: %v", zone.Name, err)
+		return
+	}
+	logrus.Infof("   🔐 [ZoneSecurity] %s: SSL=%s, 最低 TLS=%s, 憑證包 %d 個", zone.Name, current.SSLMode, current.MinTLSVersion, len(current.CertificatePacks))
+
+	// 只通知新出現的異常，持續存在的不重複發送
+	var known []string
+	if prev != nil {
+		known = prev.Issues
+	}
+	for _, issue := range issues {
+		if slices.Contains(known, issue) {
+			continue
+		}
+		logrus.Warnf("   🚨 [ZoneSecurity] %s: %s", zone.Name, issue)
+		s.Notifier.NotifyOperation(ctx, EventZoneSecurity, zone.Name, details[issue])
+	}
+}
+
+// List 所有已同步的 Zone 設定
+func (s *ZoneSecurityService) List(ctx context.Context) ([]domain.ZoneSecurity, error) {
+	return s.Repo.List(ctx)
+}
+
+// =============================================================================
+// Internal Logic (內部邏輯)
+// =============================================================================
+
+// evaluate 找出目前的異常，回傳穩定的異常描述 (用於比對是否為新異常) 與通知內容
+func (s *ZoneSecurityService) evaluate(ctx context.Context, zone *domain.ZoneSecurity) ([]string, map[string]string) {
+	var issues []string
+	details := make(map[string]string)
+
+	// 1. 憑證包卡在 pending_validation
+	for _, pack := range zone.CertificatePacks {
+		if pack.Status != domain.CertPackPendingValidation || time.Since(pack.PendingSince) < certPackStuckAfter {
+			continue
+		}
+		issue := fmt.Sprintf("憑證包 %s (%s) 卡在 pending_validation", pack.ID, pack.Type)
+		msg := fmt.Sprintf("%s 已 %d 小時\n涵蓋: %s\n驗證方式: %s",
+			issue, int(time.Since(pack.PendingSince).Hours()), strings.Join(pack.Hosts, ", "), pack.ValidationMethod)
+		if len(pack.ValidationErrors) > 0 {
+			msg += "\n錯誤: " + strings.Join(pack.ValidationErrors, "; ")
+		}
+		issues = append(issues, issue)
+		details[issue] = msg
+	}
+
+	// 2. Flexible 模式，且源站憑證無法通過 Full (strict) 驗證
+	if zone.SSLMode == domain.ZoneSSLFlexible {
+		hosts, err := s.strictInvalidOrigins(ctx, zone.ZoneName)
+		if err != nil {
+			logrus.Errorf("   ❌ [ZoneSecurity] 讀取 %s 的源站檢查結果失敗: %v", zone.ZoneName, err)
+		} else if len(hosts) > 0 {
+			issue := "Flexible 模式且源站憑證無法通過 Full (strict): " + strings.Join(hosts, ", ")
+			issues = append(issues, issue)
+			details[issue] = issue + "\nCloudflare 到源站之間為明文 HTTP，且源站憑證尚未就緒，無法直接切換到 Full (strict)"
+		}
+	}
+
+	return issues, details
+}
+
+// strictInvalidOrigins 列出 Zone 下源站檢查 (OriginCheck) 顯示憑證無效 (名稱不符或已過期) 的 Proxy 域名
+// 只看實際完成握手的結果：源站通常只允許 Cloudflare IP 連線，連不上或沒有 TLS 都沒有判斷依據，不列入
+func (s *ZoneSecurityService) strictInvalidOrigins(ctx context.Context, zoneName string) ([]string, error) {
+	domains, _, err := s.Domains.List(ctx, 1, 100000, "", "", "", "", "all", zoneName)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []string
+	for _, d := range domains {
+		origin := d.OriginCheck
+		if !d.IsProxied || d.IsIgnored || origin == nil || origin.Fingerprint == "" {
+			continue
+		}
+		if !origin.IsMatch || origin.DaysRemaining < 0 {
+			hosts = append(hosts, d.DomainName)
+		}
+	}
+	return hosts, nil
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// trackPendingSince 沿用上次記錄的 PendingSince，新進入 pending_validation 的憑證包從現在開始計算
+func trackPendingSince(current, prev *domain.ZoneSecurity) {
+	since := make(map[string]time.Time)
+	if prev != nil {
+		for _, pack := range prev.CertificatePacks {
+			if pack.Status == domain.CertPackPendingValidation && !pack.PendingSince.IsZero() {
+				since[pack.ID] = pack.PendingSince
+			}
+		}
+	}
+	for i := range current.CertificatePacks {
+		pack := &current.CertificatePacks[i]
+		if pack.Status != domain.CertPackPendingValidation {
+			pack.PendingSince = time.Time{}
+			continue
+		}
+		if t, ok := since[pack.ID]; ok {
+			pack.PendingSince = t
+		} else if pack.PendingSince.IsZero() {
+			pack.PendingSince = current.UpdatedAt
+		}
+	}
+}
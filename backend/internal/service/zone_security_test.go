@@ -0,0 +1,35 @@
+package service
+
+import (
+	"cert-manager/internal/domain"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestStrictInvalidOriginsIgnoresUnreachableOrigins(t *testing.T) {
+	proxied := func(name string, origin *domain.EndpointResult) domain.SSLCertificate {
+		return domain.SSLCertificate{DomainName: name, ZoneName: "example.com", IsProxied: true, OriginCheck: origin}
+	}
+	repo := &memDomainRepo{domains: []domain.SSLCertificate{
+		proxied("ok.example.com", &domain.EndpointResult{IP: "192.0.2.1", Fingerprint: "aa", IsMatch: true, DaysRemaining: 60}),
+		proxied("mismatch.example.com", &domain.EndpointResult{IP: "192.0.2.2", Fingerprint: "bb", IsMatch: false, DaysRemaining: 60}),
+		proxied("expired.example.com", &domain.EndpointResult{IP: "192.0.2.3", Fingerprint: "cc", IsMatch: true, DaysRemaining: -3}),
+		// 只允許 Cloudflare IP 連線的源站：直連逾時不代表憑證有問題
+		proxied("firewalled.example.com", &domain.EndpointResult{IP: "192.0.2.4", Error: "連線逾時"}),
+		proxied("plain-http.example.com", &domain.EndpointResult{IP: "192.0.2.5", Error: "連線被拒絕"}),
+		proxied("unresolved.example.com", &domain.EndpointResult{Error: "源站解析失敗: no such host"}),
+		proxied("unchecked.example.com", nil),
+		{DomainName: "dns-only.example.com", ZoneName: "example.com", OriginCheck: &domain.EndpointResult{IP: "192.0.2.6", Fingerprint: "dd"}},
+	}}
+	svc := NewZoneSecurityService(nil, repo, nil)
+
+	hosts, err := svc.strictInvalidOrigins(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(hosts)
+	if want := []string{"expired.example.com", "mismatch.example.com"}; !slices.Equal(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bufio"
+	"cert-manager/internal/domain"
+	"cert-manager/internal/repository"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const zoneFileExportTTL = 3600
+
+// ZoneImportResult Zone File 匯入結果
+type ZoneImportResult struct {
+	Zone     string                  `json:"zone"`
+	Created  []domain.SSLCertificate `json:"created"`
+	Existing []string                `json:"existing"` // 已在監控中，未重複建立
+	Skipped  []string                `json:"skipped"`  // _domainkey 等不需要監控的紀錄
+}
+
+// ZoneFileService 匯入 / 匯出 BIND 格式的 Zone File (供沒有 API 的註冊商一次性建檔)
+type ZoneFileService struct {
+	Repo repository.DomainRepository
+}
+
+func NewZoneFileService(repo repository.DomainRepository) *ZoneFileService {
+	return &ZoneFileService{Repo: repo}
+}
+
+// =============================================================================
+// Public Methods (業務入口)
+// =============================================================================
+
+// Import 解析 Zone File 的 A / AAAA / CNAME 紀錄並建立監控域名
+// zone 留空時使用檔案中的 $ORIGIN；已存在的域名不會重複建立
+func (s *ZoneFileService) Import(ctx context.Context, content, zone string) (*ZoneImportResult, error) {
+	zone = strings.TrimSuffix(strings.TrimSpace(zone), ".")
+	if zone == "" {
+		zone = detectOrigin(content)
+	}
+	if zone == "" {
+		return nil, fmt.Errorf("無法判斷 Zone 名稱，請指定 zone 參數或在檔案中加入 $ORIGIN")
+	}
+
+	rrs, err := parseZoneFile(content, zone)
+	if err != nil {
+		return nil, err
+	}
+	records := rrsToRecords(rrs)
+	hasPrimary := primaryHosts(records)
+
+	dbDomains, _, err := s.Repo.List(ctx, 1, 100000, "", "", "", "", "all", "")
+	if err != nil {
+		return nil, fmt.Errorf("讀取現有域名失敗: %w", err)
+	}
+	existing := make(map[string]bool, len(dbDomains))
+	for _, d := range dbDomains {
+		existing[strings.ToLower(d.DomainName)] = true
+	}
+
+	result := &ZoneImportResult{Zone: zone}
+	for _, record := range records {
+		if record.Type == "AAAA" && hasPrimary[strings.ToLower(record.Name)] {
+			continue
+		}
+		if shouldSkipDomain(record.Name) {
+			result.Skipped = append(result.Skipped, record.Name)
+			continue
+		}
+		if existing[strings.ToLower(record.Name)] {
+			result.Existing = append(result.Existing, record.Name)
+			continue
+		}
+
+		cert := domain.SSLCertificate{
+			ID:            primitive.NewObjectID(),
+			DomainName:    record.Name,
+			Provider:      domain.ProviderZoneFile,
+			ZoneID:        zone,
+			ZoneName:      zone,
+			RecordID:      record.ID,
+			CFRecordType:  record.Type,
+			CFOriginValue: record.Content,
+			Status:        domain.StatusPending,
+		}
+		if err := s.Repo.Create(ctx, cert); err != nil {
+			return result, fmt.Errorf("建立域名 %s 失敗: %w", record.Name, err)
+		}
+		existing[strings.ToLower(record.Name)] = true
+		result.Created = append(result.Created, cert)
+	}
+
+	logrus.Infof("📥 [ZoneFile] %s 匯入完成 (新增 %d / 已存在 %d / 略過 %d)", zone, len(result.Created), len(result.Existing), len(result.Skipped))
+	return result, nil
+}
+
+// Export 將目前監控中、屬於該 Zone 的域名輸出成 BIND 格式
+// 無法以標準紀錄表示的項目 (Route 53 ALIAS、手動新增等) 以註解列出
+func (s *ZoneFileService) Export(ctx context.Context, zone string) (string, int, error) {
+	zone = strings.TrimSuffix(strings.TrimSpace(zone), ".")
+	domains, _, err := s.Repo.List(ctx, 1, 100000, "", "", "", "", "false", zone)
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].DomainName < domains[j].DomainName })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "; Zone %s exported by CertManager at %s\n", zone, time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "$ORIGIN %s\n$TTL %d\n\n", dns.Fqdn(zone), zoneFileExportTTL)
+	for _, d := range domains {
+		rrs, ok := domainToRRs(d)
+		if !ok {
+			fmt.Fprintf(&b, "; %s\t%s\t%s\n", dns.Fqdn(d.DomainName), orDash(d.CFRecordType), orDash(d.CFOriginValue))
+			continue
+		}
+		for _, rr := range rrs {
+			b.WriteString(rr.String() + "\n")
+		}
+	}
+	return b.String(), len(domains), nil
+}
+
+// =============================================================================
+// Helper Functions (工具與底層邏輯)
+// =============================================================================
+
+// parseZoneFile 以 miekg/dns 解析 Zone File，只保留屬於該 Zone 的紀錄 ($INCLUDE 不允許)
+func parseZoneFile(content, zone string) ([]dns.RR, error) {
+	origin := dns.Fqdn(zone)
+	zp := dns.NewZoneParser(strings.NewReader(content), origin, "")
+	zp.SetDefaultTTL(zoneFileExportTTL)
+
+	var rrs []dns.RR
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if dns.IsSubDomain(origin, rr.Header().Name) {
+			rrs = append(rrs, rr)
+		}
+	}
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("Zone File 格式錯誤: %w", err)
+	}
+	return rrs, nil
+}
+
+// detectOrigin 從檔案中的第一個 $ORIGIN 取得 Zone 名稱
+func detectOrigin(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && strings.EqualFold(fields[0], "$ORIGIN") {
+			return strings.TrimSuffix(fields[1], ".")
+		}
+	}
+	return ""
+}
+
+// domainToRRs 依紀錄類型與 Origin 值還原 DNS 紀錄 (多個值以逗號分隔)
+func domainToRRs(d domain.SSLCertificate) ([]dns.RR, bool) {
+	if d.CFOriginValue == "" {
+		return nil, false
+	}
+	hdr := func(rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: dns.Fqdn(d.DomainName), Rrtype: rrtype, Class: dns.ClassINET, Ttl: zoneFileExportTTL}
+	}
+
+	var rrs []dns.RR
+	for _, value := range strings.Split(d.CFOriginValue, ",") {
+		value = strings.TrimSpace(value)
+		ip := net.ParseIP(value)
+		switch {
+		case d.CFRecordType == "A" && ip != nil && ip.To4() != nil:
+			rrs = append(rrs, &dns.A{Hdr: hdr(dns.TypeA), A: ip})
+		case d.CFRecordType == "AAAA" && ip != nil && ip.To4() == nil:
+			rrs = append(rrs, &dns.AAAA{Hdr: hdr(dns.TypeAAAA), AAAA: ip})
+		case d.CFRecordType == "CNAME" && value != "":
+			rrs = append(rrs, &dns.CNAME{Hdr: hdr(dns.TypeCNAME), Target: dns.Fqdn(value)})
+		default:
+			return nil, false
+		}
+	}
+	return rrs, true
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -17,7 +17,7 @@ export const DEFAULT_TEMPLATES = {
   zone_delete: `💥 <b>[移除主域名]</b>\nZone: {{.Domain}}\n詳情: {{.Details}}`,
 
   // [新增] 任務匯總類
-  sync_finish: `☁️ [Cloudflare 同步完成]\n新增: {{.Added}} | 更新: {{.Updated}}\n刪除: {{.Deleted}} | 略過: {{.Skipped}}\n耗時: {{.Duration}}{{.Details}}`,
+  sync_finish: `☁️ [Cloudflare 同步完成]\n新增: {{.Added}} | 更新: {{.Updated}}\n刪除: {{.Deleted}} | 略過: {{.Skipped}}\n未變更: {{.Unchanged}}\n耗時: {{.Duration}}{{.Details}}`,
   scan_finish: `🔍 [SSL 掃描完成]\n總數: {{.Total}}\n正常: {{.Active}}\n過期: {{.Expired}}\n異常: {{.Warning}}\n耗時: {{.Duration}}`,
 };
 
@@ -4,9 +4,10 @@ export interface SSLCertificate {
   id: string;
   domain_name: string;
 
-  // Cloudflare
-  cf_zone_id: string;
-  cf_record_id: string;
+  // DNS 來源 (provider 為空代表手動新增)
+  provider: string;
+  zone_id: string;
+  record_id: string;
   is_proxied: boolean; // 小橘雲
 
   // 設定